@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configEnv  = "PERSONSCTL_CONFIG"
+	profileEnv = "PERSONSCTL_PROFILE"
+
+	defaultProfile = "default"
+	defaultURL     = "http://localhost:8080"
+	defaultTimeout = 30 * time.Second
+)
+
+// Config is the config file, one profile per environment
+type Config struct {
+	CurrentProfile string             `yaml:"current_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+type Profile struct {
+	URL     string        `yaml:"url"`
+	Actor   string        `yaml:"actor,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// Output is the default output format of the profile
+	Output string `yaml:"output,omitempty"`
+}
+
+// configPath returns the path given by the flag, the environment or the
+// user config directory
+func configPath(flag string) (string, error) {
+	if flag != "" {
+		return flag, nil
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "personsctl", "config.yaml"), nil
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) save(path string) error {
+	var data bytes.Buffer
+	enc := yaml.NewEncoder(&data)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data.Bytes(), 0o600)
+}
+
+// profile returns the profile by name, the one from the environment or the
+// current one. Without profiles the local default is used.
+func (c *Config) profile(name string) (string, Profile, error) {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" {
+		name = c.CurrentProfile
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if name != defaultProfile {
+			return "", Profile{}, fmt.Errorf("profile %q is not configured", name)
+		}
+		profile = Profile{URL: defaultURL}
+	}
+
+	if profile.Timeout == 0 {
+		profile.Timeout = defaultTimeout
+	}
+	return name, profile, nil
+}
+
+func newConfigCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage profiles of the config file",
+		// profiles are managed without selecting one
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.loadConfig()
+		},
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List profiles, the current one is marked with *",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				current, _, err := a.config.profile(a.profileName)
+				if err != nil {
+					current = ""
+				}
+
+				names := make([]string, 0, len(a.config.Profiles))
+				for name := range a.config.Profiles {
+					names = append(names, name)
+				}
+				slices.Sort(names)
+
+				w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "CURRENT\tNAME\tURL\tACTOR")
+				for _, name := range names {
+					mark := ""
+					if name == current {
+						mark = "*"
+					}
+					profile := a.config.Profiles[name]
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, name, profile.URL, profile.Actor)
+				}
+				return w.Flush()
+			},
+		},
+		&cobra.Command{
+			Use:   "use NAME",
+			Short: "Make the profile current",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if _, ok := a.config.Profiles[args[0]]; !ok {
+					return fmt.Errorf("profile %q is not configured", args[0])
+				}
+
+				a.config.CurrentProfile = args[0]
+				return a.config.save(a.configPath)
+			},
+		},
+		newSetProfileCmd(a),
+	)
+	return cmd
+}
+
+func newSetProfileCmd(a *app) *cobra.Command {
+	var profile Profile
+
+	cmd := &cobra.Command{
+		Use:   "set-profile NAME",
+		Short: "Create or update a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			current := a.config.Profiles[args[0]]
+
+			flags := cmd.Flags()
+			if flags.Changed("url") {
+				current.URL = strings.TrimSuffix(profile.URL, "/")
+			}
+			if flags.Changed("actor") {
+				current.Actor = profile.Actor
+			}
+			if flags.Changed("timeout") {
+				current.Timeout = profile.Timeout
+			}
+			if flags.Changed("output") {
+				if err := checkFormat(profile.Output); err != nil {
+					return err
+				}
+				current.Output = profile.Output
+			}
+			if current.URL == "" {
+				return errors.New("--url is required for a new profile")
+			}
+
+			if a.config.Profiles == nil {
+				a.config.Profiles = make(map[string]Profile)
+			}
+			a.config.Profiles[args[0]] = current
+			return a.config.save(a.configPath)
+		},
+	}
+
+	// the flags shadow the global overrides, they are stored in the profile
+	flags := cmd.Flags()
+	flags.StringVar(&profile.URL, "url", "", "API address")
+	flags.StringVar(&profile.Actor, "actor", "", "actor recorded in the audit log")
+	flags.DurationVar(&profile.Timeout, "timeout", 0, "request timeout")
+	flags.StringVarP(&profile.Output, "output", "o", "", "default output format: table, json or yaml")
+	return cmd
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newEnrichCmd(a *app) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "enrich --dry-run NAME",
+		Short: "Show age, gender and nationalities the enrichment providers return for a name",
+		Long: "Show age, gender and nationalities the enrichment providers of the command service return for\n" +
+			"the normalized name. Only --dry-run is supported, nothing is stored.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			enrichment, err := client.PreviewEnrichment(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			return a.print(enrichment, table{
+				header: []string{"NAME", "AGE", "GENDER", "NATIONALITY"},
+				rows: [][]string{{
+					enrichment.Name, strconv.Itoa(enrichment.Age), enrichment.Gender, nationalities(enrichment.Nationalize),
+				}},
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only show the result")
+	_ = cmd.MarkFlagRequired("dry-run")
+	return cmd
+}
@@ -0,0 +1,19 @@
+module github.com/pintoter/persons/cmd/personsctl
+
+go 1.23.0
+
+require (
+	github.com/pintoter/persons/pkg/personsclient v0.0.0
+	github.com/spf13/cobra v1.8.0
+	github.com/spf13/pflag v1.0.5
+	github.com/stretchr/testify v1.8.4
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
+
+replace github.com/pintoter/persons/pkg/personsclient => ../../pkg/personsclient
@@ -0,0 +1,43 @@
+// Command personsctl manages persons through the persons API. Addresses and
+// actors of environments are kept as profiles in the config file.
+//
+//	personsctl --profile staging list --surname Ivanov -o yaml
+//	personsctl create --name Ivan --surname Ivanov
+//	personsctl export --nationality RU --file persons.csv
+//	personsctl enrich --dry-run Ivan
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pintoter/persons/pkg/personsclient"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := newRootCmd(os.Stdout, os.Stderr).ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// printError prints the error with the fields which failed validation
+func printError(w io.Writer, err error) {
+	fmt.Fprintln(w, "Error:", err)
+
+	var apiErr *personsclient.APIError
+	if errors.As(err, &apiErr) {
+		for _, v := range apiErr.Violations {
+			fmt.Fprintf(w, "  %s: %s\n", v.Field, v.Message)
+		}
+	}
+}
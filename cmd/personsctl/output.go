@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pintoter/persons/pkg/personsclient"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+	}
+}
+
+func (a *app) format() string {
+	if a.profile.Output == "" {
+		return formatTable
+	}
+	return a.profile.Output
+}
+
+// table is the table output of a value
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// print writes the value in the output format, tbl is its table output
+func (a *app) print(v any, tbl table) error {
+	switch a.format() {
+	case formatJSON:
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		return writeYAML(a.out, v)
+	default:
+		return tbl.write(a.out)
+	}
+}
+
+// writeYAML writes the value with the field names and order of its JSON
+// representation
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quotes JSON is parsed with
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func personsTable(persons ...personsclient.Person) table {
+	t := table{header: []string{"ID", "NAME", "SURNAME", "PATRONYMIC", "AGE", "GENDER", "NATIONALITY"}}
+	for _, p := range persons {
+		t.rows = append(t.rows, []string{
+			strconv.Itoa(p.ID), p.Name, p.Surname, p.Patronymic, strconv.Itoa(p.Age), p.Gender, nationalities(p.Nationalize),
+		})
+	}
+	return t
+}
+
+// nationalities formats nationalities as COUNTRY:PROBABILITY separated by
+// semicolons
+func nationalities(n []personsclient.Nationality) string {
+	values := make([]string, 0, len(n))
+	for _, nationality := range n {
+		values = append(values, nationality.Country+":"+strconv.FormatFloat(nationality.Probability, 'f', -1, 64))
+	}
+	return strings.Join(values, ";")
+}
+
+// parseNationalities parses the output of nationalities
+func parseNationalities(value string) ([]personsclient.Nationality, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var result []personsclient.Nationality
+	for _, item := range strings.Split(value, ";") {
+		country, probability, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("nationality %q must be COUNTRY:PROBABILITY", item)
+		}
+
+		p, err := strconv.ParseFloat(probability, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nationality %q must be COUNTRY:PROBABILITY", item)
+		}
+		result = append(result, personsclient.Nationality{Country: country, Probability: p})
+	}
+	return result, nil
+}
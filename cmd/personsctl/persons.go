@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxLimit is the largest page the API returns
+const maxLimit = 100
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("person ID must be a positive number, got %q", arg)
+	}
+	return id, nil
+}
+
+func newCreateCmd(a *app) *cobra.Command {
+	var (
+		name        personsclient.FullName
+		onDuplicate string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a person enriched with age, gender and nationalities",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			person, created, err := client.CreatePerson(cmd.Context(), name, personsclient.DuplicatePolicy(onDuplicate))
+			if err != nil {
+				return err
+			}
+			if !created {
+				fmt.Fprintf(a.errOut, "person %d already exists\n", person.ID)
+			}
+			return a.print(person, personsTable(person))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&name.Name, "name", "", "name")
+	flags.StringVar(&name.Surname, "surname", "", "surname")
+	flags.StringVar(&name.Patronymic, "patronymic", "", "patronymic")
+	flags.StringVar(&onDuplicate, "on-duplicate", "", "duplicate policy: reject, return_existing or create (default is the service policy)")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("surname")
+	return cmd
+}
+
+func newGetCmd(a *app) *cobra.Command {
+	var asOf string
+
+	cmd := &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a person",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			var person personsclient.Person
+			if asOf == "" {
+				person, err = client.GetPerson(cmd.Context(), id)
+			} else {
+				var at time.Time
+				if at, err = time.Parse(time.RFC3339, asOf); err != nil {
+					return fmt.Errorf("--as-of must be an RFC 3339 timestamp: %w", err)
+				}
+				person, err = client.GetPersonAsOf(cmd.Context(), id, at)
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(person, personsTable(person))
+		},
+	}
+
+	cmd.Flags().StringVar(&asOf, "as-of", "", "show the person as it was at the RFC 3339 timestamp")
+	return cmd
+}
+
+// filterFlags are the filters of persons queries
+type filterFlags struct {
+	query              string
+	match              string
+	name               string
+	surname            string
+	patronymic         string
+	age                int
+	ageMin             int
+	ageMax             int
+	gender             string
+	nationality        string
+	primaryNationality string
+	minProbability     float64
+	nationalities      string
+	exclude            []string
+	sort               string
+	asOf               string
+}
+
+func (f *filterFlags) register(flags *pflag.FlagSet) {
+	flags.StringVarP(&f.query, "query", "q", "", "search by name parts starting with it or similar to it, the most relevant first")
+	flags.StringVar(&f.match, "match", "", "how --query matches: fuzzy (default) or phonetic, by the sound of names and surnames")
+	flags.StringVar(&f.name, "name", "", "name")
+	flags.StringVar(&f.surname, "surname", "", "surname")
+	flags.StringVar(&f.patronymic, "patronymic", "", "patronymic, empty selects persons without patronymic")
+	flags.IntVar(&f.age, "age", 0, "age")
+	flags.IntVar(&f.ageMin, "age-min", 0, "minimal age, inclusive")
+	flags.IntVar(&f.ageMax, "age-max", 150, "maximal age, inclusive")
+	flags.StringVar(&f.gender, "gender", "", "comma-separated genders: male, female")
+	flags.StringVar(&f.nationality, "nationality", "", "comma-separated country IDs of nationalities, e.g. RU,KZ")
+	flags.StringVar(&f.primaryNationality, "primary-nationality", "", "comma-separated country IDs, the most probable nationality is any of them")
+	flags.Float64Var(&f.minProbability, "min-probability", 0, "least probability of the countries --nationality and --primary-nationality match")
+	flags.StringVar(&f.nationalities, "nationalities", "", "nationalities returned: top1, top3 or all (default)")
+	flags.StringArrayVar(&f.exclude, "exclude", nil, "skip persons with the values as FIELD=VALUE[,VALUE...], e.g. surname=Ivanov")
+	flags.StringVar(&f.sort, "sort", "", "comma-separated fields to sort by, descending ones prefixed with a minus, e.g. -age,surname")
+	flags.StringVar(&f.asOf, "as-of", "", "query persons as they were at the RFC 3339 timestamp")
+}
+
+// filter returns the filter of the flags set on the command
+func (f *filterFlags) filter(flags *pflag.FlagSet) (*personsclient.Filter, error) {
+	filter := personsclient.NewFilter()
+	if flags.Changed("query") {
+		filter.Search(f.query)
+	}
+	if flags.Changed("match") {
+		filter.Match(f.match)
+	}
+	if flags.Changed("name") {
+		filter.Name(f.name)
+	}
+	if flags.Changed("surname") {
+		filter.Surname(f.surname)
+	}
+	if flags.Changed("patronymic") {
+		filter.Patronymic(f.patronymic)
+	}
+	if flags.Changed("age") {
+		filter.Age(f.age)
+	}
+	if flags.Changed("age-min") || flags.Changed("age-max") {
+		filter.AgeBetween(f.ageMin, f.ageMax)
+	}
+	if flags.Changed("gender") {
+		filter.Gender(f.gender)
+	}
+	if flags.Changed("nationality") {
+		filter.Nationality(f.nationality)
+	}
+	if flags.Changed("primary-nationality") {
+		filter.PrimaryNationality(f.primaryNationality)
+	}
+	if flags.Changed("min-probability") {
+		filter.MinProbability(f.minProbability)
+	}
+	if flags.Changed("nationalities") {
+		filter.Nationalities(f.nationalities)
+	}
+	for _, exclude := range f.exclude {
+		field, values, ok := strings.Cut(exclude, "=")
+		if !ok {
+			return nil, fmt.Errorf("--exclude must be FIELD=VALUE[,VALUE...], got %q", exclude)
+		}
+		filter.Exclude(field, values)
+	}
+	if flags.Changed("sort") {
+		filter.Sort(f.sort)
+	}
+	if f.asOf != "" {
+		asOf, err := time.Parse(time.RFC3339, f.asOf)
+		if err != nil {
+			return nil, fmt.Errorf("--as-of must be an RFC 3339 timestamp: %w", err)
+		}
+		filter.AsOf(asOf)
+	}
+	return filter, nil
+}
+
+func newListCmd(a *app) *cobra.Command {
+	var (
+		filters filterFlags
+		limit   int
+		page    int
+		all     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List persons matching the filters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := filters.filter(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			if all && !cmd.Flags().Changed("limit") {
+				limit = maxLimit
+			}
+			filter.Limit(limit).Page(page)
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			var persons []personsclient.Person
+			if all {
+				for person, err := range client.Persons(cmd.Context(), filter) {
+					if err != nil {
+						return err
+					}
+					persons = append(persons, person)
+				}
+			} else if persons, err = client.GetPersons(cmd.Context(), filter); err != nil {
+				return err
+			}
+
+			if persons == nil {
+				persons = []personsclient.Person{}
+			}
+			return a.print(persons, personsTable(persons...))
+		},
+	}
+
+	flags := cmd.Flags()
+	filters.register(flags)
+	flags.IntVar(&limit, "limit", 10, "persons per page")
+	flags.IntVar(&page, "page", 1, "page number")
+	flags.BoolVar(&all, "all", false, "list all pages starting from --page, next pages are read by cursors")
+	return cmd
+}
+
+func newUpdateCmd(a *app) *cobra.Command {
+	var (
+		input       personsclient.UpdatePersonInput
+		name        string
+		surname     string
+		patronymic  string
+		age         int
+		gender      string
+		nationalize string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Change fields of a person, age, gender and nationalities are recomputed when the name changes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("name") {
+				input.Name = &name
+			}
+			if flags.Changed("surname") {
+				input.Surname = &surname
+			}
+			if flags.Changed("patronymic") {
+				input.Patronymic = &patronymic
+			}
+			if flags.Changed("age") {
+				input.Age = &age
+			}
+			if flags.Changed("gender") {
+				input.Gender = &gender
+			}
+			if flags.Changed("nationalize") {
+				if input.Nationalize, err = parseNationalities(nationalize); err != nil {
+					return err
+				}
+			}
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			result, err := client.UpdatePerson(cmd.Context(), id, input)
+			if err != nil {
+				return err
+			}
+			return a.print(result, personsTable(result.Person))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&name, "name", "", "name")
+	flags.StringVar(&surname, "surname", "", "surname")
+	flags.StringVar(&patronymic, "patronymic", "", "patronymic")
+	flags.IntVar(&age, "age", 0, "age")
+	flags.StringVar(&gender, "gender", "", "gender: male or female")
+	flags.StringVar(&nationalize, "nationalize", "", "nationalities as COUNTRY:PROBABILITY separated by semicolons, e.g. RU:0.7;KZ:0.1")
+	return cmd
+}
+
+func newDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID...",
+		Short: "Delete persons",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := make([]int, 0, len(args))
+			for _, arg := range args {
+				id, err := parseID(arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err = client.DeletePerson(cmd.Context(), id); err != nil {
+					return fmt.Errorf("delete person %d: %w", id, err)
+				}
+				fmt.Fprintf(a.out, "person %d deleted\n", id)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPersons = []string{
+	`{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[{"country_id":"RU","probability":0.7},{"country_id":"KZ","probability":0.1}]}`,
+	`{"id":2,"name":"Anna","surname":"Petrova","patronymic":"Sergeevna","age":25,"gender":"female","nationalize":[]}`,
+	`{"id":3,"name":"Petr","surname":"Ivanov","age":41,"gender":"male","nationalize":[]}`,
+}
+
+// newAPI returns a stub of the persons API, requests are recorded
+func newAPI(t *testing.T, requests *[]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/persons", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RawQuery)
+		query := r.URL.Query()
+		if !query.Has("page") && !query.Has("cursor") {
+			query.Set("page", "1")
+		}
+		switch {
+		case query.Get("page") == "1" && query.Get("limit") == "2":
+			_, _ = io.WriteString(w, `{"persons":[`+testPersons[0]+`,`+testPersons[1]+`],"next_cursor":"c2"}`)
+		case query.Get("page") == "1":
+			_, _ = io.WriteString(w, `{"persons":[`+testPersons[0]+`,`+testPersons[1]+`]}`)
+		case query.Get("cursor") == "c2":
+			_, _ = io.WriteString(w, `{"persons":[`+testPersons[2]+`],"prev_cursor":"c1"}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{"error":"person doesn't exist"}`)
+		}
+	})
+	mux.HandleFunc("POST /api/v1/persons", func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		*requests = append(*requests, r.Header.Get("X-Actor")+" "+r.URL.RawQuery+" "+input["name"])
+
+		switch input["name"] {
+		case "Ivan":
+			_, _ = io.WriteString(w, `{"person":`+testPersons[0]+`}`)
+		case "X":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":"invalid input parameters","violations":[{"field":"name","code":"too_short","message":"name must be at least 2 characters"}]}`)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"person":`+testPersons[1]+`}`)
+		}
+	})
+	mux.HandleFunc("GET /api/v1/enrichment", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"name":"`+r.URL.Query().Get("name")+`","age":42,"gender":"male","nationalize":[{"country_id":"RU","probability":0.4}]}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func run(t *testing.T, stdin string, args ...string) (string, string, error) {
+	var out, errOut bytes.Buffer
+	cmd := newRootCmd(&out, &errOut)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(append([]string{"--config", filepath.Join(t.TempDir(), "config.yaml")}, args...))
+
+	err := cmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func Test_Profiles(t *testing.T) {
+	t.Setenv(profileEnv, "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	exec := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := newRootCmd(&out, io.Discard)
+		cmd.SetArgs(append([]string{"--config", path}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	_, err := exec("config", "set-profile", "staging", "--actor", "ops")
+	assert.EqualError(t, err, "--url is required for a new profile")
+
+	_, err = exec("config", "set-profile", "staging", "--url", "http://staging:8080/", "--actor", "ops", "-o", "yaml")
+	assert.NoError(t, err)
+	_, err = exec("config", "set-profile", "prod", "--url", "http://prod:8080", "--timeout", "5s")
+	assert.NoError(t, err)
+	_, err = exec("config", "use", "prod")
+	assert.NoError(t, err)
+	_, err = exec("config", "use", "dev")
+	assert.EqualError(t, err, `profile "dev" is not configured`)
+
+	out, err := exec("config", "list")
+	assert.NoError(t, err)
+	assert.Equal(t, "CURRENT  NAME     URL                  ACTOR\n"+
+		"*        prod     http://prod:8080     \n"+
+		"         staging  http://staging:8080  ops\n", out)
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+
+	name, profile, err := cfg.profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", name)
+	assert.Equal(t, Profile{URL: "http://prod:8080", Timeout: 5e9}, profile)
+
+	t.Setenv(profileEnv, "staging")
+	name, profile, err = cfg.profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", name)
+	assert.Equal(t, Profile{URL: "http://staging:8080", Actor: "ops", Timeout: defaultTimeout, Output: formatYAML}, profile)
+
+	_, _, err = cfg.profile("dev")
+	assert.Error(t, err)
+
+	t.Setenv(profileEnv, "")
+	name, profile, err = (&Config{}).profile("")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultProfile, name)
+	assert.Equal(t, defaultURL, profile.URL)
+}
+
+func Test_List(t *testing.T) {
+	var requests []string
+	server := newAPI(t, &requests)
+
+	out, _, err := run(t, "", "--url", server.URL, "list", "--surname", "ivanov", "--age", "30", "--nationality", "RU")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age=30&limit=10&nationalize=RU&page=1&surname=ivanov"}, requests)
+	assert.Equal(t, "ID  NAME  SURNAME  PATRONYMIC  AGE  GENDER  NATIONALITY\n"+
+		"1   Ivan  Ivanov               30   male    RU:0.7;KZ:0.1\n"+
+		"2   Anna  Petrova  Sergeevna   25   female  \n", out)
+
+	requests = nil
+	_, _, err = run(t, "", "--url", server.URL, "list", "-q", "iva", "--age-min", "20", "--gender", "male,female", "--patronymic", "",
+		"--exclude", "surname=Ivanov,Petrov", "--exclude", "nationalize=UA")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age_max=150&age_min=20&gender=male%2Cfemale&limit=10&nationalize%21=UA&page=1&patronymic=&q=iva&surname%21=Ivanov%2CPetrov"}, requests)
+
+	_, _, err = run(t, "", "--url", server.URL, "list", "--exclude", "surname")
+	assert.EqualError(t, err, `--exclude must be FIELD=VALUE[,VALUE...], got "surname"`)
+
+	requests = nil
+	out, _, err = run(t, "", "--url", server.URL, "list", "--all", "--limit", "2", "-o", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"limit=2&page=1", "cursor=c2&limit=2"}, requests)
+	assert.JSONEq(t, "["+strings.Join(testPersons, ",")+"]", out)
+
+	out, _, err = run(t, "", "--url", server.URL, "list", "--page", "3", "-o", "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", out)
+
+	_, _, err = run(t, "", "--url", server.URL, "list", "-o", "xml")
+	assert.EqualError(t, err, `unknown output format "xml", use table, json or yaml`)
+}
+
+func Test_Create(t *testing.T) {
+	var requests []string
+	server := newAPI(t, &requests)
+
+	out, errOut, err := run(t, "", "--url", server.URL, "--actor", "alice", "create", "--name", "Ivan", "--surname", "Ivanov", "--on-duplicate", "return_existing", "-o", "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice on_duplicate=return_existing Ivan"}, requests)
+	assert.Equal(t, "person 1 already exists\n", errOut)
+	assert.Equal(t, `id: 1
+name: Ivan
+surname: Ivanov
+age: 30
+gender: male
+nationalize:
+  - country_id: RU
+    probability: 0.7
+  - country_id: KZ
+    probability: 0.1
+`, out)
+
+	_, _, err = run(t, "", "--url", server.URL, "create", "--name", "X", "--surname", "Ivanov")
+	var errOutput bytes.Buffer
+	printError(&errOutput, err)
+	assert.Equal(t, "Error: personsclient: 400 invalid input parameters: name must be at least 2 characters\n"+
+		"  name: name must be at least 2 characters\n", errOutput.String())
+}
+
+func Test_Import(t *testing.T) {
+	var requests []string
+	server := newAPI(t, &requests)
+
+	input := "id,name,surname,patronymic\n1,Ivan,Ivanov,\n,Anna,Petrova,Sergeevna\n,X,Ivanov,\n"
+	out, _, err := run(t, input, "--url", server.URL, "import", "--format", "csv", "-")
+	assert.EqualError(t, err, "1 of 3 records failed to import")
+	assert.Equal(t, []string{
+		" on_duplicate=return_existing Ivan",
+		" on_duplicate=return_existing Anna",
+		" on_duplicate=return_existing X",
+	}, requests)
+	assert.Equal(t, "LINE  ID  STATUS    ERROR\n"+
+		"2     1   existing  \n"+
+		"3     2   created   \n"+
+		"4         failed    personsclient: 400 invalid input parameters: name must be at least 2 characters\n", out)
+
+	requests = nil
+	path := filepath.Join(t.TempDir(), "persons.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"name":"Anna","surname":"Petrova"}`+"\n\n{\n"), 0o600))
+
+	out, _, err = run(t, "", "--url", server.URL, "import", "--on-duplicate", "create", "-o", "json", path)
+	assert.EqualError(t, err, "1 of 2 records failed to import")
+	assert.Equal(t, []string{" on_duplicate=create Anna"}, requests)
+	assert.JSONEq(t, `[
+		{"line": 1, "id": 2, "status": "created"},
+		{"line": 3, "status": "failed", "error": "invalid JSON: unexpected end of JSON input"}
+	]`, out)
+
+	_, _, err = run(t, "name;surname\n", "--url", server.URL, "import", "--format", "csv", "-")
+	assert.EqualError(t, err, "CSV header has no name column")
+}
+
+func Test_Export(t *testing.T) {
+	var requests []string
+	server := newAPI(t, &requests)
+
+	out, _, err := run(t, "", "--url", server.URL, "export", "--format", "csv", "--gender", "male")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gender=male&limit=100"}, requests)
+	assert.Equal(t, "id,name,surname,patronymic,age,gender,nationalize\n"+
+		"1,Ivan,Ivanov,,30,male,RU:0.7;KZ:0.1\n"+
+		"2,Anna,Petrova,Sergeevna,25,female,\n", out)
+
+	path := filepath.Join(t.TempDir(), "persons.jsonl")
+	_, errOut, err := run(t, "", "--url", server.URL, "export", "--file", path)
+	assert.NoError(t, err)
+	assert.Equal(t, "exported 2 persons to "+path+"\n", errOut)
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	assert.NoError(t, err)
+	assert.Equal(t, testPersons[0]+"\n"+testPersons[1]+"\n", string(data))
+
+	_, _, err = run(t, "", "--url", server.URL, "export", "--file", "persons.xml")
+	assert.EqualError(t, err, `unknown file format "xml", use csv or jsonl`)
+}
+
+func Test_EnrichDryRun(t *testing.T) {
+	var requests []string
+	server := newAPI(t, &requests)
+
+	out, _, err := run(t, "", "--url", server.URL, "enrich", "--dry-run", "Ivan")
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME  AGE  GENDER  NATIONALITY\n"+
+		"Ivan  42   male    RU:0.4\n", out)
+
+	_, _, err = run(t, "", "--url", server.URL, "enrich", "Ivan")
+	assert.EqualError(t, err, `required flag(s) "dry-run" not set`)
+}
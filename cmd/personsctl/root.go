@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/spf13/cobra"
+)
+
+// app is the state shared by the commands
+type app struct {
+	configPath  string
+	profileName string
+	url         string
+	actor       string
+	output      string
+
+	config  *Config
+	profile Profile
+
+	out    io.Writer
+	errOut io.Writer
+}
+
+func newRootCmd(out, errOut io.Writer) *cobra.Command {
+	a := &app{out: out, errOut: errOut}
+
+	root := &cobra.Command{
+		Use:           "personsctl",
+		Short:         "Manage persons through the persons API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.init()
+		},
+	}
+	root.SetOut(out)
+	root.SetErr(errOut)
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", "", "config file (default $"+configEnv+" or personsctl/config.yaml in the user config directory)")
+	flags.StringVarP(&a.profileName, "profile", "p", "", "profile of the config file (default $"+profileEnv+" or the current profile)")
+	flags.StringVar(&a.url, "url", "", "API address, overrides the profile")
+	flags.StringVar(&a.actor, "actor", "", "actor recorded in the audit log, overrides the profile")
+	flags.StringVarP(&a.output, "output", "o", "", "output format: table, json or yaml")
+
+	root.AddCommand(
+		newCreateCmd(a),
+		newGetCmd(a),
+		newListCmd(a),
+		newUpdateCmd(a),
+		newDeleteCmd(a),
+		newImportCmd(a),
+		newExportCmd(a),
+		newEnrichCmd(a),
+		newConfigCmd(a),
+	)
+	return root
+}
+
+// loadConfig reads the config file given by the flags
+func (a *app) loadConfig() error {
+	path, err := configPath(a.configPath)
+	if err != nil {
+		return err
+	}
+	a.configPath = path
+
+	a.config, err = loadConfig(path)
+	return err
+}
+
+// init loads the config and applies the flags to the selected profile
+func (a *app) init() error {
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+
+	var err error
+	_, a.profile, err = a.config.profile(a.profileName)
+	if err != nil {
+		return err
+	}
+
+	if a.url != "" {
+		a.profile.URL = a.url
+	}
+	if a.actor != "" {
+		a.profile.Actor = a.actor
+	}
+	if a.output != "" {
+		a.profile.Output = a.output
+	}
+	return checkFormat(a.format())
+}
+
+func (a *app) client() (*personsclient.Client, error) {
+	opts := []personsclient.Option{
+		personsclient.WithHTTPClient(&http.Client{Timeout: a.profile.Timeout}),
+	}
+	if a.profile.Actor != "" {
+		opts = append(opts, personsclient.WithActor(a.profile.Actor))
+	}
+
+	return personsclient.New(a.profile.URL, opts...)
+}
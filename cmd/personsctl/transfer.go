@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/spf13/cobra"
+)
+
+// File formats of import and export
+const (
+	fileCSV   = "csv"
+	fileJSONL = "jsonl"
+)
+
+var csvHeader = []string{"id", "name", "surname", "patronymic", "age", "gender", "nationalize"}
+
+// fileFormat returns the format given by the flag or the file extension,
+// JSON lines by default
+func fileFormat(flag, path string) (string, error) {
+	format := flag
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	switch format {
+	case fileCSV:
+		return fileCSV, nil
+	case fileJSONL, "json", "":
+		return fileJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown file format %q, use csv or jsonl", format)
+	}
+}
+
+// record is a person read by import, line is its position in the file
+type record struct {
+	line int
+	name personsclient.FullName
+	err  error
+}
+
+// readRecords reads full names from the file. CSV files need a header with
+// name and surname columns, other columns are ignored, so exported files
+// can be imported.
+func readRecords(r io.Reader, format string) ([]record, error) {
+	if format == fileJSONL {
+		var records []record
+		scanner := bufio.NewScanner(r)
+		for line := 1; scanner.Scan(); line++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+
+			rec := record{line: line}
+			if err := json.Unmarshal(scanner.Bytes(), &rec.name); err != nil {
+				rec.err = fmt.Errorf("invalid JSON: %w", err)
+			}
+			records = append(records, rec)
+		}
+		return records, scanner.Err()
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"name", "surname"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	value := func(row []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []record
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record{line: line, name: personsclient.FullName{
+			Name:       value(row, "name"),
+			Surname:    value(row, "surname"),
+			Patronymic: value(row, "patronymic"),
+		}})
+	}
+}
+
+// importResult is the outcome of importing one record
+type importResult struct {
+	Line   int    `json:"line"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Statuses of imported records
+const (
+	statusCreated  = "created"
+	statusExisting = "existing"
+	statusFailed   = "failed"
+)
+
+func newImportCmd(a *app) *cobra.Command {
+	var (
+		formatFlag  string
+		onDuplicate string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Create persons from a CSV or JSON lines file, - reads standard input",
+		Long: "Create persons from a CSV or JSON lines file, - reads standard input. CSV files need a header with\n" +
+			"name, surname and optionally patronymic columns, JSON lines hold objects with the same fields.\n" +
+			"Files written by export can be imported. Failed records don't stop the import.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := fileFormat(formatFlag, args[0])
+			if err != nil {
+				return err
+			}
+
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				var file *os.File
+				if file, err = os.Open(filepath.Clean(args[0])); err != nil {
+					return err
+				}
+				defer file.Close()
+				in = file
+			}
+
+			records, err := readRecords(in, format)
+			if err != nil {
+				return err
+			}
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			results := make([]importResult, 0, len(records))
+			failed := 0
+			for _, rec := range records {
+				result := importResult{Line: rec.line, Status: statusFailed}
+				if rec.err == nil {
+					var person personsclient.Person
+					var created bool
+					person, created, rec.err = client.CreatePerson(cmd.Context(), rec.name, personsclient.DuplicatePolicy(onDuplicate))
+					if rec.err == nil {
+						result.ID, result.Status = person.ID, statusExisting
+						if created {
+							result.Status = statusCreated
+						}
+					}
+				}
+				if rec.err != nil {
+					if cmd.Context().Err() != nil {
+						return cmd.Context().Err()
+					}
+					result.Error = rec.err.Error()
+					failed++
+				}
+				results = append(results, result)
+			}
+
+			tbl := table{header: []string{"LINE", "ID", "STATUS", "ERROR"}}
+			for _, result := range results {
+				id := ""
+				if result.ID != 0 {
+					id = strconv.Itoa(result.ID)
+				}
+				tbl.rows = append(tbl.rows, []string{strconv.Itoa(result.Line), id, result.Status, result.Error})
+			}
+			if err = a.print(results, tbl); err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d records failed to import", failed, len(records))
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&formatFlag, "format", "", "file format: csv or jsonl (default by the file extension, jsonl for -)")
+	flags.StringVar(&onDuplicate, "on-duplicate", string(personsclient.DuplicateReturnExisting), "duplicate policy: reject, return_existing or create")
+	return cmd
+}
+
+func newExportCmd(a *app) *cobra.Command {
+	var (
+		filters    filterFlags
+		formatFlag string
+		path       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write all persons matching the filters to a CSV or JSON lines file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := fileFormat(formatFlag, path)
+			if err != nil {
+				return err
+			}
+
+			filter, err := filters.filter(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			filter.Limit(maxLimit)
+
+			client, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			out := a.out
+			if path != "" && path != "-" {
+				var file *os.File
+				if file, err = os.Create(filepath.Clean(path)); err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+
+			w := newPersonWriter(out, format)
+			count := 0
+			for person, err := range client.Persons(cmd.Context(), filter) {
+				if err != nil {
+					return err
+				}
+				if err = w.write(person); err != nil {
+					return err
+				}
+				count++
+			}
+			if err = w.flush(); err != nil {
+				return err
+			}
+
+			if out != a.out {
+				fmt.Fprintf(a.errOut, "exported %d persons to %s\n", count, path)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	filters.register(flags)
+	flags.StringVar(&formatFlag, "format", "", "file format: csv or jsonl (default by the file extension, jsonl for standard output)")
+	flags.StringVarP(&path, "file", "f", "", "file to write, standard output by default")
+	return cmd
+}
+
+// personWriter writes persons in a file format
+type personWriter struct {
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+func newPersonWriter(w io.Writer, format string) *personWriter {
+	if format == fileCSV {
+		return &personWriter{csv: csv.NewWriter(w)}
+	}
+	return &personWriter{json: json.NewEncoder(w)}
+}
+
+func (w *personWriter) write(person personsclient.Person) error {
+	if w.json != nil {
+		return w.json.Encode(person)
+	}
+
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	return w.csv.Write([]string{
+		strconv.Itoa(person.ID),
+		person.Name,
+		person.Surname,
+		person.Patronymic,
+		strconv.Itoa(person.Age),
+		person.Gender,
+		nationalities(person.Nationalize),
+	})
+}
+
+func (w *personWriter) flush() error {
+	if w.json != nil {
+		return nil
+	}
+
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
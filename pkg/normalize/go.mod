@@ -0,0 +1,14 @@
+module github.com/pintoter/persons/pkg/normalize
+
+go 1.23.0
+
+require (
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/text v0.25.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,130 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+type Config interface {
+	IsEnabled() bool
+	ShouldCapitalize() bool
+	GetLanguage() string
+	GetParticles() []string
+}
+
+// Settings is the normalization section of service configs. Both services
+// read it, so names are normalized on write the same way as in filters.
+type Settings struct {
+	Enabled    bool
+	Capitalize bool
+	Language   string
+	Particles  []string
+}
+
+func (n *Settings) IsEnabled() bool {
+	return n.Enabled
+}
+
+func (n *Settings) ShouldCapitalize() bool {
+	return n.Capitalize
+}
+
+func (n *Settings) GetLanguage() string {
+	return n.Language
+}
+
+func (n *Settings) GetParticles() []string {
+	return n.Particles
+}
+
+// Normalizer brings name parts to the canonical form used for storage,
+// enrichment and search: NFC, single spaces and, optionally, capitalization
+type Normalizer struct {
+	enabled    bool
+	capitalize bool
+	lower      cases.Caser
+	upper      cases.Caser
+	particles  map[string]struct{}
+}
+
+func New(cfg Config) *Normalizer {
+	tag, err := language.Parse(cfg.GetLanguage())
+	if err != nil {
+		tag = language.Und
+	}
+
+	particles := make(map[string]struct{}, len(cfg.GetParticles()))
+	for _, particle := range cfg.GetParticles() {
+		particles[strings.ToLower(particle)] = struct{}{}
+	}
+
+	return &Normalizer{
+		enabled:    cfg.IsEnabled(),
+		capitalize: cfg.ShouldCapitalize(),
+		lower:      cases.Lower(tag),
+		upper:      cases.Upper(tag),
+		particles:  particles,
+	}
+}
+
+func (n *Normalizer) Normalize(value string) string {
+	if !n.enabled {
+		return value
+	}
+
+	words := strings.Fields(norm.NFC.String(value))
+	if n.capitalize {
+		for i, word := range words {
+			if _, ok := n.particles[n.lower.String(word)]; ok && i > 0 {
+				words[i] = n.lower.String(word)
+				continue
+			}
+			words[i] = n.capitalizeWord(word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// capitalizeWord keeps deliberate mixed case such as "DeVito" and
+// capitalizes every part of hyphenated words otherwise
+func (n *Normalizer) capitalizeWord(word string) string {
+	first, _ := utf8.DecodeRuneInString(word)
+	if unicode.IsUpper(first) && word != n.upper.String(word) {
+		return word
+	}
+
+	parts := strings.Split(n.lower.String(word), "-")
+	for i, part := range parts {
+		parts[i] = n.capitalizePart(part)
+	}
+	return strings.Join(parts, "-")
+}
+
+func (n *Normalizer) capitalizePart(part string) string {
+	// O'Neil, D'Artagnan
+	if idx := strings.IndexAny(part, "'’"); idx > 0 && utf8.RuneCountInString(part[:idx]) == 1 {
+		_, size := utf8.DecodeRuneInString(part[idx:])
+		return n.title(part[:idx]) + part[idx:idx+size] + n.title(part[idx+size:])
+	}
+
+	// McDonald
+	if strings.HasPrefix(part, "mc") && len(part) > len("mc") {
+		return "Mc" + n.title(part[len("mc"):])
+	}
+
+	return n.title(part)
+}
+
+func (n *Normalizer) title(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	if first == utf8.RuneError {
+		return s
+	}
+	return n.upper.String(string(first)) + s[size:]
+}
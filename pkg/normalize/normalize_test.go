@@ -0,0 +1,66 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	enabled    bool
+	capitalize bool
+	language   string
+	particles  []string
+}
+
+func (c testConfig) IsEnabled() bool        { return c.enabled }
+func (c testConfig) ShouldCapitalize() bool { return c.capitalize }
+func (c testConfig) GetLanguage() string    { return c.language }
+func (c testConfig) GetParticles() []string { return c.particles }
+
+func Test_Normalize(t *testing.T) {
+	n := New(testConfig{
+		enabled:    true,
+		capitalize: true,
+		language:   "en",
+		particles:  []string{"van", "de"},
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Lower", input: "ivan", want: "Ivan"},
+		{name: "UpperWithSpaces", input: "  IVAN ", want: "Ivan"},
+		{name: "AlreadyNormalized", input: "Ivan", want: "Ivan"},
+		{name: "CollapseSpaces", input: "anna   maria", want: "Anna Maria"},
+		{name: "Hyphenated", input: "rimsky-korsakov", want: "Rimsky-Korsakov"},
+		{name: "Apostrophe", input: "o'neil", want: "O'Neil"},
+		{name: "TypographicApostrophe", input: "D’ARTAGNAN", want: "D’Artagnan"},
+		{name: "Mc", input: "mcdonald", want: "McDonald"},
+		{name: "MixedCaseKept", input: "DeVito", want: "DeVito"},
+		{name: "Particle", input: "ludwig VAN beethoven", want: "Ludwig van Beethoven"},
+		{name: "LeadingParticle", input: "van dyke", want: "Van Dyke"},
+		{name: "Cyrillic", input: "дмитрий", want: "Дмитрий"},
+		{name: "NFC", input: "jose\u0301", want: "Jos\u00e9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, n.Normalize(tt.input))
+		})
+	}
+}
+
+func Test_NormalizeWithoutCapitalize(t *testing.T) {
+	n := New(testConfig{enabled: true})
+
+	assert.Equal(t, "ivan ivanov", n.Normalize(" ivan\t ivanov "))
+}
+
+func Test_NormalizeDisabled(t *testing.T) {
+	n := New(testConfig{})
+
+	assert.Equal(t, " IVAN ", n.Normalize(" IVAN "))
+}
@@ -0,0 +1,253 @@
+// Package personsclient is a client of the persons API. Commands are served by
+// the command service and queries by the query service, behind nginx both are
+// available on the same address.
+//
+//	client, err := personsclient.New("http://localhost:8080", personsclient.WithActor("billing"))
+//	...
+//	for person, err := range client.Persons(ctx, personsclient.NewFilter().Surname("Ivanov")) {
+//		...
+//	}
+package personsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = 100 * time.Millisecond
+	maxBackoff         = 5 * time.Second
+
+	actorHeader     = "X-Actor"
+	requestIDHeader = "X-Request-ID"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	actor      string
+
+	maxAttempts int
+	backoff     time.Duration
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sets the HTTP client used for requests. Redirects of merged
+// persons are never followed, they are returned as MergedError.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithActor sets the X-Actor header, it's recorded in the history of persons
+// changed by the client
+func WithActor(actor string) Option {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+// WithRetries sets how many times idempotent requests are attempted when the
+// server is unavailable, and the delay before the first retry which doubles
+// with every attempt
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.backoff = backoff
+	}
+}
+
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("personsclient: base URL must be an absolute http or https URL: %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:     u,
+		httpClient:  http.DefaultClient,
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	c.httpClient = &httpClient
+
+	return c, nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose requests carry the X-Request-ID
+// header, to correlate them with the history of persons
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// call describes one API request
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	accept string
+}
+
+func (c call) idempotent() bool {
+	return c.method == http.MethodGet || c.method == http.MethodDelete
+}
+
+// do sends the request and decodes the response into out. Idempotent
+// requests are retried on network errors and on 429, 502, 503 and 504.
+func (c *Client) do(ctx context.Context, req call, out any) (int, error) {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, decodeError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("personsclient: decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// send returns the first response that isn't retried, its body has to be
+// closed by the caller
+func (c *Client) send(ctx context.Context, req call) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, fmt.Errorf("personsclient: encode request: %w", err)
+		}
+	}
+
+	attempts := 1
+	if req.idempotent() {
+		attempts = c.maxAttempts
+	}
+
+	backoff := c.backoff
+	for attempt := 1; ; attempt++ {
+		httpReq, err := c.newRequest(ctx, req, body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if attempt == attempts || (err == nil && !retryable(resp.StatusCode)) {
+			return resp, err
+		}
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+func (c *Client) newRequest(ctx context.Context, req call, body []byte) (*http.Request, error) {
+	u := c.baseURL.JoinPath(req.path)
+	u.RawQuery = req.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if req.accept != "" {
+		httpReq.Header.Set("Accept", req.accept)
+	}
+	if c.actor != "" {
+		httpReq.Header.Set(actorHeader, c.actor)
+	}
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		httpReq.Header.Set(requestIDHeader, requestID)
+	}
+	return httpReq, nil
+}
+
+func retryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	var body struct {
+		Err        string      `json:"error"`
+		Violations []Violation `json:"violations"`
+		Duplicates []int       `json:"duplicates"`
+		MergedInto *int        `json:"merged_into"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Err == "" {
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	apiErr.Message = body.Err
+	apiErr.Violations = body.Violations
+	apiErr.Duplicates = body.Duplicates
+	if body.MergedInto != nil {
+		return &MergedError{APIError: apiErr, MergedInto: *body.MergedInto}
+	}
+	return apiErr
+}
+
+// isEmpty reports whether the error means there are no persons matching
+// the request
+func isEmpty(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Message == messageNotFound
+}
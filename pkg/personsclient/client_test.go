@@ -0,0 +1,292 @@
+package personsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New(server.URL, WithRetries(3, time.Millisecond), WithActor("tests"))
+	assert.NoError(t, err)
+	return client
+}
+
+func Test_New(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+
+	_, err = New("https://persons.example.com/")
+	assert.NoError(t, err)
+}
+
+func Test_RetryIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "tests", r.Header.Get("X-Actor"))
+		assert.Equal(t, "req-1", r.Header.Get("X-Request-ID"))
+		_, _ = io.WriteString(w, `{"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[]}}`)
+	})
+
+	person, err := client.GetPerson(WithRequestID(context.Background(), "req-1"), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ivan", person.Name)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func Test_RetryGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := client.GetWebhooks(context.Background())
+	assert.ErrorIs(t, err, ErrServer)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func Test_NoRetryNotIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, _, err := client.CreatePerson(context.Background(), FullName{Name: "Ivan", Surname: "Ivanov"}, DuplicateDefault)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func Test_RetryCancelled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.backoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetPersons(ctx, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   int
+		body   string
+		is     []error
+		isNot  []error
+		verify func(t *testing.T, err error)
+	}{
+		{
+			name:  "Validation",
+			code:  http.StatusBadRequest,
+			body:  `{"error":"invalid input parameters","violations":[{"field":"name","code":"too_short","message":"name must be at least 2 characters"}]}`,
+			is:    []error{ErrInvalidInput},
+			isNot: []error{ErrNotFound, ErrServer},
+			verify: func(t *testing.T, err error) {
+				var apiErr *APIError
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, []Violation{{Field: "name", Code: "too_short", Message: "name must be at least 2 characters"}}, apiErr.Violations)
+				assert.EqualError(t, err, "personsclient: 400 invalid input parameters: name must be at least 2 characters")
+			},
+		},
+		{
+			name:  "NotFoundAsBadRequest",
+			code:  http.StatusBadRequest,
+			body:  `{"error":"person doesn't exist"}`,
+			is:    []error{ErrNotFound},
+			isNot: []error{ErrInvalidInput},
+		},
+		{
+			name: "NotFound",
+			code: http.StatusNotFound,
+			body: `{"error":"webhook doesn't exist"}`,
+			is:   []error{ErrNotFound},
+		},
+		{
+			name:  "Duplicate",
+			code:  http.StatusConflict,
+			body:  `{"error":"person already exists","duplicates":[3,7]}`,
+			is:    []error{ErrDuplicate},
+			isNot: []error{ErrInvalidInput},
+			verify: func(t *testing.T, err error) {
+				var apiErr *APIError
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, []int{3, 7}, apiErr.Duplicates)
+			},
+		},
+		{
+			name:  "Merged",
+			code:  http.StatusMovedPermanently,
+			body:  `{"error":"person was merged into another person","merged_into":3}`,
+			is:    []error{ErrMerged},
+			isNot: []error{ErrNotFound},
+			verify: func(t *testing.T, err error) {
+				var mergedErr *MergedError
+				assert.True(t, errors.As(err, &mergedErr))
+				assert.Equal(t, 3, mergedErr.MergedInto)
+			},
+		},
+		{
+			name: "NotJSON",
+			code: http.StatusInternalServerError,
+			body: "oops",
+			is:   []error{ErrServer},
+			verify: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "personsclient: 500 oops")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if test.code == http.StatusMovedPermanently {
+					w.Header().Set("Location", "/api/v1/persons/3")
+				}
+				w.WriteHeader(test.code)
+				_, _ = io.WriteString(w, test.body)
+			})
+
+			_, err := client.GetPerson(context.Background(), 1)
+			for _, target := range test.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range test.isNot {
+				assert.NotErrorIs(t, err, target)
+			}
+			if test.verify != nil {
+				test.verify(t, err)
+			}
+		})
+	}
+}
+
+func Test_FilterQuery(t *testing.T) {
+	filter := NewFilter().
+		Name("Ivan").
+		Surname("Ivanov").
+		Age(30).
+		Gender(Male).
+		Nationality("RU").
+		AsOf(time.Date(2026, 7, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))).
+		Limit(10).
+		Page(2)
+
+	assert.Equal(t, "age=30&as_of=2026-07-01T00%3A00%3A00Z&gender=male&limit=10&name=Ivan&nationalize=RU&page=2&surname=Ivanov", filter.query().Encode())
+	assert.Equal(t, "age=30&gender=male&name=Ivan&nationalize=RU&surname=Ivanov", filter.personQuery().Encode())
+
+	filter = NewFilter().
+		Search("iva").
+		Match(MatchPhonetic).
+		Patronymic("").
+		AgeBetween(20, 30).
+		Gender(Male, Female).
+		Nationality("RU", "KZ").
+		PrimaryNationality("RU").
+		MinProbability(0.5).
+		Nationalities(NationalitiesTop1).
+		Exclude("surname", "Ivanov", "Petrov").
+		Sort("-age", "surname").
+		Limit(10)
+	assert.Equal(t, "age_max=30&age_min=20&gender=male%2Cfemale&nationalize=RU%2CKZ&nationalize_min_probability=0.5&patronymic="+
+		"&primary_nationality=RU&surname%21=Ivanov%2CPetrov", filter.personQuery().Encode())
+	assert.Equal(t, "iva", filter.query().Get("q"))
+	assert.Equal(t, "phonetic", filter.query().Get("match"))
+	assert.Equal(t, "-age,surname", filter.query().Get("sort"))
+	assert.Equal(t, "top1", filter.query().Get("nationalities"))
+
+	var empty *Filter
+	assert.Empty(t, empty.query())
+	assert.Equal(t, "name=Anna", (&Filter{}).Name("Anna").query().Encode())
+}
+
+func Test_PersonsIterator(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Ivanov", r.URL.Query().Get("surname"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+
+		assert.False(t, r.URL.Query().Has("page"))
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = io.WriteString(w, `{"persons":[{"id":1},{"id":2}],"next_cursor":"c2"}`)
+		case "c2":
+			_, _ = io.WriteString(w, `{"persons":[{"id":3},{"id":4}],"total":5,"limit":2,"has_more":true,"next_cursor":"c3","prev_cursor":"c1"}`)
+		default:
+			_, _ = io.WriteString(w, `{"persons":[],"prev_cursor":"c2"}`)
+		}
+	})
+
+	var ids []int
+	for person, err := range client.Persons(context.Background(), NewFilter().Surname("Ivanov").Limit(2)) {
+		assert.NoError(t, err)
+		ids = append(ids, person.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+
+	ids = nil
+	for person := range client.Persons(context.Background(), NewFilter().Surname("Ivanov").Limit(2)) {
+		ids = append(ids, person.ID)
+		if len(ids) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	page, err := client.GetPersonsPage(context.Background(), NewFilter().Surname("Ivanov").Limit(2).Cursor("c2"))
+	assert.NoError(t, err)
+	assert.Equal(t, PersonsPage{
+		Persons:    []Person{{ID: 3}, {ID: 4}},
+		Total:      5,
+		Limit:      2,
+		HasMore:    true,
+		NextCursor: "c3",
+		PrevCursor: "c1",
+	}, page)
+}
+
+func Test_Events(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		assert.Equal(t, "3", r.URL.Query().Get("last_event_id"))
+		assert.False(t, r.URL.Query().Has("limit"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, id := range []int{4, 6} {
+			_, _ = fmt.Fprintf(w, "id: %d\nevent: PersonCreated\ndata: {\"id\":%d,\"type\":\"PersonCreated\",\"person_id\":1}\n\n: ping\n\n", id, id)
+		}
+	})
+
+	lastEventID := int64(3)
+	var ids []int64
+	var streamErr error
+	for event, err := range client.Events(context.Background(), NewFilter().Name("Ivan").Limit(5), &lastEventID) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		ids = append(ids, event.ID)
+	}
+
+	assert.Equal(t, []int64{4, 6}, ids)
+	assert.ErrorIs(t, streamErr, io.ErrUnexpectedEOF)
+}
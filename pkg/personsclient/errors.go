@@ -0,0 +1,73 @@
+package personsclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors to check API errors against with errors.Is
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input parameters")
+	ErrDuplicate    = errors.New("person already exists")
+	ErrMerged       = errors.New("person was merged into another person")
+	ErrServer       = errors.New("unexpected server error")
+)
+
+// messageNotFound is the error message of missing persons, the command
+// service returns it with 400 Bad Request
+const messageNotFound = "person doesn't exist"
+
+// Violation is a field of the request that failed validation
+type Violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int
+	Message    string
+	Violations []Violation
+	// Duplicates are IDs of persons with the same full name, set when
+	// creation is rejected
+	Duplicates []int
+}
+
+func (e *APIError) Error() string {
+	if len(e.Violations) > 0 {
+		return fmt.Sprintf("personsclient: %d %s: %s", e.StatusCode, e.Message, e.Violations[0].Message)
+	}
+	return fmt.Sprintf("personsclient: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Message == messageNotFound || e.StatusCode == http.StatusNotFound
+	case ErrInvalidInput:
+		return e.StatusCode == http.StatusBadRequest && e.Message != messageNotFound
+	case ErrDuplicate:
+		return e.StatusCode == http.StatusConflict
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError && e.Message != messageNotFound
+	default:
+		return false
+	}
+}
+
+// MergedError is returned for persons merged into the survivor MergedInto
+type MergedError struct {
+	*APIError
+	MergedInto int
+}
+
+func (e *MergedError) Is(target error) bool {
+	return target == ErrMerged
+}
+
+func (e *MergedError) Unwrap() error {
+	return e.APIError
+}
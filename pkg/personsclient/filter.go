@@ -0,0 +1,187 @@
+package personsclient
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// personParams are the filters accepted by the change feed too
+var personParams = []string{
+	"name", "surname", "patronymic", "age", "age_min", "age_max", "gender", "nationalize",
+	"primary_nationality", "nationalize_min_probability",
+	"name!", "surname!", "patronymic!", "gender!", "nationalize!",
+}
+
+// Filter selects persons, it mirrors the query parameters of
+// GET /api/v1/persons. Names are matched after the same normalization as on
+// creation. The zero value and nil select all persons.
+type Filter struct {
+	values url.Values
+}
+
+func NewFilter() *Filter {
+	return &Filter{values: url.Values{}}
+}
+
+func (f *Filter) set(key, value string) *Filter {
+	if f.values == nil {
+		f.values = url.Values{}
+	}
+	f.values.Set(key, value)
+	return f
+}
+
+// Search selects persons with a name part starting with the query or
+// similar to it, the most relevant first
+func (f *Filter) Search(query string) *Filter {
+	return f.set("q", query)
+}
+
+// How Filter.Search matches names
+const (
+	// MatchFuzzy matches name parts starting with the query or similar to it
+	// in any spelling, it's the default
+	MatchFuzzy = "fuzzy"
+	// MatchPhonetic matches names and surnames sounding like the query words
+	MatchPhonetic = "phonetic"
+)
+
+// Match sets how the query of Search matches names, MatchFuzzy or
+// MatchPhonetic
+func (f *Filter) Match(match string) *Filter {
+	return f.set("match", match)
+}
+
+func (f *Filter) Name(name string) *Filter {
+	return f.set("name", name)
+}
+
+func (f *Filter) Surname(surname string) *Filter {
+	return f.set("surname", surname)
+}
+
+// Patronymic selects persons with the patronymic, an empty one selects
+// persons without patronymic
+func (f *Filter) Patronymic(patronymic string) *Filter {
+	return f.set("patronymic", patronymic)
+}
+
+func (f *Filter) Age(age int) *Filter {
+	return f.set("age", strconv.Itoa(age))
+}
+
+// AgeBetween selects persons of age from min to max inclusive
+func (f *Filter) AgeBetween(min, max int) *Filter {
+	return f.set("age_min", strconv.Itoa(min)).set("age_max", strconv.Itoa(max))
+}
+
+// Gender selects persons of any of the genders, Male or Female
+func (f *Filter) Gender(genders ...string) *Filter {
+	return f.set("gender", strings.Join(genders, ","))
+}
+
+// Nationality selects persons with any of the countries among their
+// nationalities
+func (f *Filter) Nationality(countryIDs ...string) *Filter {
+	return f.set("nationalize", strings.Join(countryIDs, ","))
+}
+
+// PrimaryNationality selects persons whose most probable nationality is any
+// of the countries
+func (f *Filter) PrimaryNationality(countryIDs ...string) *Filter {
+	return f.set("primary_nationality", strings.Join(countryIDs, ","))
+}
+
+// MinProbability is the least probability of the countries Nationality and
+// PrimaryNationality match, from 0 to 1
+func (f *Filter) MinProbability(probability float64) *Filter {
+	return f.set("nationalize_min_probability", strconv.FormatFloat(probability, 'f', -1, 64))
+}
+
+// Nationalities returned with persons
+const (
+	// NationalitiesTop1 returns the most probable nationality only
+	NationalitiesTop1 = "top1"
+	// NationalitiesTop3 returns three most probable nationalities
+	NationalitiesTop3 = "top3"
+	// NationalitiesAll returns all nationalities, it's the default
+	NationalitiesAll = "all"
+)
+
+// Nationalities sets which nationalities are returned with persons,
+// NationalitiesTop1, NationalitiesTop3 or NationalitiesAll
+func (f *Filter) Nationalities(nationalities string) *Filter {
+	return f.set("nationalities", nationalities)
+}
+
+// Exclude skips persons with any of the values of the field: name, surname,
+// patronymic, gender or nationalize. An empty patronymic skips persons
+// without patronymic.
+func (f *Filter) Exclude(field string, values ...string) *Filter {
+	return f.set(field+"!", strings.Join(values, ","))
+}
+
+// AsOf selects persons as they were at the moment
+func (f *Filter) AsOf(asOf time.Time) *Filter {
+	return f.set("as_of", asOf.UTC().Format(time.RFC3339Nano))
+}
+
+// Sort orders persons by the fields in turn, descending ones prefixed with a
+// minus, e.g. Sort("-age", "surname"). Ties are broken by ID.
+func (f *Filter) Sort(fields ...string) *Filter {
+	return f.set("sort", strings.Join(fields, ","))
+}
+
+// Limit is the page size, from 1 to 100
+func (f *Filter) Limit(limit int) *Filter {
+	return f.set("limit", strconv.Itoa(limit))
+}
+
+// Page is the number of the page, starting from 1
+func (f *Filter) Page(page int) *Filter {
+	return f.set("page", strconv.Itoa(page))
+}
+
+// Cursor selects the page by a cursor of a previous page, it can't be
+// combined with Page
+func (f *Filter) Cursor(cursor string) *Filter {
+	return f.set("cursor", cursor)
+}
+
+func (f *Filter) query() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	for key, values := range f.values {
+		query[key] = append([]string(nil), values...)
+	}
+	return query
+}
+
+// personQuery returns person filters only, without paging and as_of
+func (f *Filter) personQuery() url.Values {
+	query := url.Values{}
+	if f == nil {
+		return query
+	}
+	for _, key := range personParams {
+		if f.values.Has(key) {
+			query.Set(key, f.values.Get(key))
+		}
+	}
+	return query
+}
+
+func (f *Filter) intValue(key string, def int) int {
+	if f == nil || !f.values.Has(key) {
+		return def
+	}
+	n, err := strconv.Atoi(f.values.Get(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,30 @@
+package personsclient
+
+import "iter"
+
+// maxPageSize is the largest page the API returns
+const maxPageSize = 100
+
+// pages yields items of consecutive pages starting from page, until a page
+// is shorter than limit or fails
+func pages[T any](limit, page int, fetch func(limit, page int) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for ; ; page++ {
+			items, err := fetch(limit, page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < limit {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package personsclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DuplicatePolicy decides what happens when a person with the same full name
+// exists
+type DuplicatePolicy string
+
+const (
+	// DuplicateDefault applies the policy configured in the command service
+	DuplicateDefault        DuplicatePolicy = ""
+	DuplicateReject         DuplicatePolicy = "reject"
+	DuplicateReturnExisting DuplicatePolicy = "return_existing"
+	DuplicateCreate         DuplicatePolicy = "create"
+)
+
+// Merge rules choose which person a field is taken from
+const (
+	KeepTarget = "keep_target"
+	KeepSource = "keep_source"
+	Newest     = "newest"
+)
+
+// UpdatePersonInput holds the fields to change, nil fields are kept. Derived
+// fields which aren't set are recomputed when the name changes.
+type UpdatePersonInput struct {
+	Name        *string       `json:"name,omitempty"`
+	Surname     *string       `json:"surname,omitempty"`
+	Patronymic  *string       `json:"patronymic,omitempty"`
+	Age         *int          `json:"age,omitempty"`
+	Gender      *string       `json:"gender,omitempty"`
+	Nationalize []Nationality `json:"nationalize,omitempty"`
+}
+
+type UpdatePersonResult struct {
+	Person Person `json:"person"`
+	// Recomputed are derived fields recomputed with the update
+	Recomputed []string `json:"recomputed,omitempty"`
+	// Scheduled are derived fields which are recomputed in background
+	Scheduled []string `json:"scheduled,omitempty"`
+}
+
+// MergeInput selects the person merged into the target, Rules map fields to
+// KeepTarget, KeepSource or Newest
+type MergeInput struct {
+	SourceID int               `json:"source_id"`
+	Rules    map[string]string `json:"rules,omitempty"`
+}
+
+// String returns a pointer to the value, for optional input fields
+func String(value string) *string {
+	return &value
+}
+
+// Int returns a pointer to the value, for optional input fields
+func Int(value int) *int {
+	return &value
+}
+
+type personResponse struct {
+	Person Person `json:"person"`
+}
+
+func personPath(id int) string {
+	return "/api/v1/persons/" + strconv.Itoa(id)
+}
+
+// CreatePerson creates a person enriched with age, gender and nationalities.
+// Created is false when DuplicateReturnExisting returned an existing person.
+// Rejected duplicates are returned as ErrDuplicate with their IDs in
+// APIError.Duplicates.
+func (c *Client) CreatePerson(ctx context.Context, name FullName, onDuplicate DuplicatePolicy) (person Person, created bool, err error) {
+	query := url.Values{}
+	if onDuplicate != DuplicateDefault {
+		query.Set("on_duplicate", string(onDuplicate))
+	}
+
+	var resp personResponse
+	code, err := c.do(ctx, call{method: http.MethodPost, path: "/api/v1/persons", query: query, body: name}, &resp)
+	if err != nil {
+		return Person{}, false, err
+	}
+	return resp.Person, code == http.StatusCreated, nil
+}
+
+func (c *Client) UpdatePerson(ctx context.Context, id int, input UpdatePersonInput) (UpdatePersonResult, error) {
+	var resp UpdatePersonResult
+	_, err := c.do(ctx, call{method: http.MethodPatch, path: personPath(id), body: input}, &resp)
+	return resp, err
+}
+
+func (c *Client) DeletePerson(ctx context.Context, id int) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: personPath(id)}, nil)
+	return err
+}
+
+// MergePersons merges the source person into the target and returns the
+// target. The source is deleted and redirects to the target.
+func (c *Client) MergePersons(ctx context.Context, targetID int, input MergeInput) (Person, error) {
+	var resp personResponse
+	_, err := c.do(ctx, call{method: http.MethodPost, path: personPath(targetID) + "/merge", body: input}, &resp)
+	return resp.Person, err
+}
+
+// PreviewEnrichment returns what the enrichment providers of the command
+// service give for the name, without creating a person
+func (c *Client) PreviewEnrichment(ctx context.Context, name string) (Enrichment, error) {
+	var resp Enrichment
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/api/v1/enrichment", query: url.Values{"name": {name}}}, &resp)
+	return resp, err
+}
+
+// GetPerson returns the person, merged persons are returned as MergedError
+func (c *Client) GetPerson(ctx context.Context, id int) (Person, error) {
+	return c.getPerson(ctx, id, url.Values{})
+}
+
+// GetPersonAsOf returns the person as it was at the moment
+func (c *Client) GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (Person, error) {
+	return c.getPerson(ctx, id, url.Values{"as_of": {asOf.UTC().Format(time.RFC3339Nano)}})
+}
+
+func (c *Client) getPerson(ctx context.Context, id int, query url.Values) (Person, error) {
+	var resp personResponse
+	_, err := c.do(ctx, call{method: http.MethodGet, path: personPath(id), query: query}, &resp)
+	return resp.Person, err
+}
+
+// GetPersons returns one page of persons matching the filter
+func (c *Client) GetPersons(ctx context.Context, filter *Filter) ([]Person, error) {
+	page, err := c.GetPersonsPage(ctx, filter)
+	return page.Persons, err
+}
+
+// GetPersonsPage returns one page of persons matching the filter with the
+// cursors of the neighbouring pages
+func (c *Client) GetPersonsPage(ctx context.Context, filter *Filter) (PersonsPage, error) {
+	var page PersonsPage
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/api/v1/persons", query: filter.query()}, &page)
+	if isEmpty(err) {
+		return PersonsPage{Persons: []Person{}}, nil
+	}
+	return page, err
+}
+
+// Persons iterates over all persons matching the filter starting from the
+// filter page, next pages are read by cursors, so persons created or deleted
+// meanwhile don't shift them. Iteration stops at the first error.
+func (c *Client) Persons(ctx context.Context, filter *Filter) iter.Seq2[Person, error] {
+	return func(yield func(Person, error) bool) {
+		query := filter.query()
+		if !query.Has("limit") {
+			query.Set("limit", strconv.Itoa(maxPageSize))
+		}
+
+		for {
+			page, err := c.GetPersonsPage(ctx, &Filter{values: query})
+			if err != nil {
+				yield(Person{}, err)
+				return
+			}
+
+			for _, person := range page.Persons {
+				if !yield(person, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			query.Del("page")
+			query.Set("cursor", page.NextCursor)
+		}
+	}
+}
+
+// GetHistory returns one page of recorded changes of the person, the newest
+// first
+func (c *Client) GetHistory(ctx context.Context, id, limit, page int) ([]HistoryEntry, error) {
+	var resp struct {
+		History []HistoryEntry `json:"history"`
+	}
+	query := url.Values{"limit": {strconv.Itoa(limit)}, "page": {strconv.Itoa(page)}}
+	_, err := c.do(ctx, call{method: http.MethodGet, path: personPath(id) + "/history", query: query}, &resp)
+	return resp.History, err
+}
+
+// History iterates over all recorded changes of the person, the newest first
+func (c *Client) History(ctx context.Context, id int) iter.Seq2[HistoryEntry, error] {
+	return pages(maxPageSize, 1, func(limit, page int) ([]HistoryEntry, error) {
+		return c.GetHistory(ctx, id, limit, page)
+	})
+}
@@ -0,0 +1,102 @@
+package personsclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChangesOptions tune GetChanges. Without Limit the server default is used.
+// Wait holds a request without new changes until changes arrive or the
+// time is up, up to a minute.
+type ChangesOptions struct {
+	Limit int
+	Wait  time.Duration
+}
+
+// GetChanges returns persons changed since the token, a full sync without
+// it. Pass NextToken as since to continue while HasMore is true.
+func (c *Client) GetChanges(ctx context.Context, since string, opts ChangesOptions) (ChangeSet, error) {
+	query := url.Values{}
+	if since != "" {
+		query.Set("since", since)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Wait > 0 {
+		query.Set("wait", strconv.Itoa(int(opts.Wait.Round(time.Second)/time.Second)))
+	}
+
+	var resp ChangeSet
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/api/v1/persons/changes", query: query}, &resp)
+	return resp, err
+}
+
+// Events streams changes of persons matching the filter as they happen. With
+// lastEventID the stream starts after that event, otherwise from now on. The
+// stream ends when ctx is cancelled; if the server closes it, io.ErrUnexpectedEOF
+// is yielded and the caller may reconnect with the ID of the last event.
+func (c *Client) Events(ctx context.Context, filter *Filter, lastEventID *int64) iter.Seq2[PersonEvent, error] {
+	return func(yield func(PersonEvent, error) bool) {
+		query := filter.personQuery()
+		if lastEventID != nil {
+			query.Set("last_event_id", strconv.FormatInt(*lastEventID, 10))
+		}
+
+		resp, err := c.send(ctx, call{method: http.MethodGet, path: "/api/v1/persons/events", query: query, accept: "text/event-stream"})
+		if err != nil {
+			yield(PersonEvent{}, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			yield(PersonEvent{}, decodeError(resp))
+			return
+		}
+
+		var data strings.Builder
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line != "" {
+				// id and event fields repeat the event itself, comments are heartbeats
+				if value, ok := strings.CutPrefix(line, "data:"); ok {
+					data.WriteString(strings.TrimPrefix(value, " "))
+				}
+				continue
+			}
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event PersonEvent
+			if err = json.Unmarshal([]byte(data.String()), &event); err != nil {
+				yield(PersonEvent{}, fmt.Errorf("personsclient: decode event: %w", err))
+				return
+			}
+			data.Reset()
+			if !yield(event, nil) {
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		err = scanner.Err()
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		yield(PersonEvent{}, err)
+	}
+}
@@ -0,0 +1,162 @@
+package personsclient
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	Male   = "male"
+	Female = "female"
+)
+
+type Nationality struct {
+	Country     string  `json:"country_id"`
+	Probability float64 `json:"probability"`
+}
+
+type FullName struct {
+	Name       string `json:"name"`
+	Surname    string `json:"surname"`
+	Patronymic string `json:"patronymic,omitempty"`
+}
+
+type Person struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Surname     string        `json:"surname"`
+	Patronymic  string        `json:"patronymic,omitempty"`
+	Age         int           `json:"age"`
+	Gender      string        `json:"gender"`
+	Nationalize []Nationality `json:"nationalize"`
+	// Original is the full name as it was sent, before normalization. It's
+	// returned by the command service only.
+	Original *FullName `json:"original,omitempty"`
+	// PossibleDuplicateOf is set when the person was created although a person
+	// with the same full name exists
+	PossibleDuplicateOf *int `json:"possible_duplicate_of,omitempty"`
+	// Score is the relevance to the search query of Filter.Search
+	Score float64 `json:"score,omitempty"`
+	// Match is the spelling of the full name Filter.Search matched
+	Match *Match `json:"match,omitempty"`
+}
+
+// Match is a spelling of a full name: "original" or a transliteration by the
+// "icao", "gost", "bgn" or "informal" scheme
+type Match struct {
+	Scheme string `json:"scheme"`
+	Text   string `json:"text"`
+}
+
+// PersonsPage is a page of persons, the cursors are empty when there is no
+// page before or after it
+type PersonsPage struct {
+	Persons []Person `json:"persons"`
+	// Total is the number of persons matching the filter on all pages
+	Total int `json:"total"`
+	// Page is zero for pages selected by a cursor
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Enrichment is what the enrichment providers return for a normalized name
+type Enrichment struct {
+	Name        string        `json:"name"`
+	Age         int           `json:"age"`
+	Gender      string        `json:"gender"`
+	Nationalize []Nationality `json:"nationalize"`
+}
+
+// HistoryEntry is a recorded change of a person
+type HistoryEntry struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+const (
+	ChangeUpsert    = "upsert"
+	ChangeTombstone = "tombstone"
+)
+
+// Change is the latest state of a person changed since a sync token. Deleted
+// persons are tombstones without person data.
+type Change struct {
+	Type       string    `json:"type"`
+	PersonID   int       `json:"person_id"`
+	Person     *Person   `json:"person,omitempty"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+	EventID    int64     `json:"event_id"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// ChangeSet is a page of changes. NextToken is passed as since to continue.
+type ChangeSet struct {
+	Changes   []Change `json:"changes"`
+	NextToken string   `json:"next_token"`
+	HasMore   bool     `json:"has_more"`
+}
+
+// Event types of the change feed
+const (
+	PersonCreated  = "PersonCreated"
+	PersonUpdated  = "PersonUpdated"
+	PersonEnriched = "PersonEnriched"
+	PersonDeleted  = "PersonDeleted"
+)
+
+// PersonEvent is a change of person delivered by the change feed
+type PersonEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	PersonID   int       `json:"person_id"`
+	Person     *Person   `json:"person,omitempty"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Webhook is a subscription to person events. Secret is returned only when
+// the webhook is created.
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Statuses of webhook deliveries
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type Delivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	AttemptLog []DeliveryAttempt `json:"attempt_log,omitempty"`
+}
+
+type DeliveryAttempt struct {
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
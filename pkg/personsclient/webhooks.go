@@ -0,0 +1,93 @@
+package personsclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// WebhookInput subscribes URL to person events. All event types are sent
+// when EventTypes is empty. Deliveries are signed with Secret, a random one
+// is generated if it's empty.
+type WebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+func webhookPath(id int) string {
+	return "/api/v1/webhooks/" + strconv.Itoa(id)
+}
+
+func deliveryPath(webhookID int, id int64) string {
+	return webhookPath(webhookID) + "/deliveries/" + strconv.FormatInt(id, 10)
+}
+
+type webhookResponse struct {
+	Webhook Webhook `json:"webhook"`
+}
+
+type deliveryResponse struct {
+	Delivery Delivery `json:"delivery"`
+}
+
+// CreateWebhook creates the subscription, the returned webhook holds the
+// secret, which isn't returned anymore
+func (c *Client) CreateWebhook(ctx context.Context, input WebhookInput) (Webhook, error) {
+	var resp webhookResponse
+	_, err := c.do(ctx, call{method: http.MethodPost, path: "/api/v1/webhooks", body: input}, &resp)
+	return resp.Webhook, err
+}
+
+func (c *Client) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	var resp struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+	_, err := c.do(ctx, call{method: http.MethodGet, path: "/api/v1/webhooks"}, &resp)
+	return resp.Webhooks, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := c.do(ctx, call{method: http.MethodDelete, path: webhookPath(id)}, nil)
+	return err
+}
+
+// GetDeliveries returns one page of the delivery log of the webhook, the
+// newest first. An empty status returns deliveries in any status,
+// DeliveryDead returns the dead-letter list.
+func (c *Client) GetDeliveries(ctx context.Context, webhookID int, status string, limit, page int) ([]Delivery, error) {
+	query := url.Values{"limit": {strconv.Itoa(limit)}, "page": {strconv.Itoa(page)}}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var resp struct {
+		Deliveries []Delivery `json:"deliveries"`
+	}
+	_, err := c.do(ctx, call{method: http.MethodGet, path: webhookPath(webhookID) + "/deliveries", query: query}, &resp)
+	return resp.Deliveries, err
+}
+
+// Deliveries iterates over the whole delivery log of the webhook
+func (c *Client) Deliveries(ctx context.Context, webhookID int, status string) iter.Seq2[Delivery, error] {
+	return pages(maxPageSize, 1, func(limit, page int) ([]Delivery, error) {
+		return c.GetDeliveries(ctx, webhookID, status, limit, page)
+	})
+}
+
+// GetDelivery returns the delivery with its attempt log
+func (c *Client) GetDelivery(ctx context.Context, webhookID int, id int64) (Delivery, error) {
+	var resp deliveryResponse
+	_, err := c.do(ctx, call{method: http.MethodGet, path: deliveryPath(webhookID, id)}, &resp)
+	return resp.Delivery, err
+}
+
+// Redeliver schedules the delivery to be sent again, dead deliveries get a
+// new set of attempts
+func (c *Client) Redeliver(ctx context.Context, webhookID int, id int64) (Delivery, error) {
+	var resp deliveryResponse
+	_, err := c.do(ctx, call{method: http.MethodPost, path: deliveryPath(webhookID, id) + "/redeliver"}, &resp)
+	return resp.Delivery, err
+}
@@ -0,0 +1,582 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxKeyLength is the length Double Metaphone keys are cut to
+const maxKeyLength = 4
+
+// metaphone holds the state of a Double Metaphone encoding, a port of the
+// original algorithm by Lawrence Philips
+type metaphone struct {
+	word               []rune
+	length, last       int
+	primary, alternate strings.Builder
+	slavoGermanic      bool
+}
+
+// doubleMetaphone returns the primary and the alternate Double Metaphone keys
+// of a Latin word
+func doubleMetaphone(word string) (string, string) {
+	var letters []rune
+	for _, r := range strings.ToUpper(word) {
+		if unicode.IsLetter(r) || r == ' ' {
+			letters = append(letters, r)
+		}
+	}
+	if len(letters) == 0 {
+		return "", ""
+	}
+
+	m := &metaphone{
+		// padded so lookups past the end see spaces
+		word:   append(letters, []rune("     ")...),
+		length: len(letters),
+		last:   len(letters) - 1,
+	}
+	upper := string(letters)
+	m.slavoGermanic = strings.Contains(upper, "W") || strings.Contains(upper, "K") ||
+		strings.Contains(upper, "CZ") || strings.Contains(upper, "WITZ")
+	m.encode()
+
+	return truncate(m.primary.String()), truncate(m.alternate.String())
+}
+
+func truncate(key string) string {
+	if len(key) > maxKeyLength {
+		return key[:maxKeyLength]
+	}
+	return key
+}
+
+func (m *metaphone) at(pos int) rune {
+	if pos < 0 || pos >= len(m.word) {
+		return 0
+	}
+	return m.word[pos]
+}
+
+// is reports whether the letters at pos are one of the options, all options
+// have the same length
+func (m *metaphone) is(pos int, options ...string) bool {
+	if pos < 0 {
+		return false
+	}
+	for _, option := range options {
+		length := len([]rune(option))
+		if pos+length <= len(m.word) && string(m.word[pos:pos+length]) == option {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) vowel(pos int) bool {
+	return strings.ContainsRune("AEIOUY", m.at(pos)) && m.at(pos) != 0
+}
+
+// add appends main to both keys, or main to the primary and alternate to the
+// alternate one
+func (m *metaphone) add(main string, alternate ...string) {
+	m.primary.WriteString(main)
+	if len(alternate) > 0 {
+		m.alternate.WriteString(alternate[0])
+	} else {
+		m.alternate.WriteString(main)
+	}
+}
+
+// skip returns the step over the current letter and its double
+func (m *metaphone) skip(current int, double rune) int {
+	if m.at(current+1) == double {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) germanic() bool {
+	return m.is(0, "VAN ", "VON ") || m.is(0, "SCH")
+}
+
+func (m *metaphone) encode() {
+	current := 0
+	// skip silent letters at the start
+	if m.is(0, "GN", "KN", "PN", "WR", "PS") {
+		current++
+	}
+	// initial X is pronounced Z, e.g. Xavier
+	if m.at(0) == 'X' {
+		m.add("S")
+		current++
+	}
+
+	for (m.primary.Len() < maxKeyLength || m.alternate.Len() < maxKeyLength) && current < m.length {
+		switch m.at(current) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if current == 0 {
+				m.add("A")
+			}
+			current++
+		case 'B':
+			m.add("P")
+			current += m.skip(current, 'B')
+		case 'Ç':
+			m.add("S")
+			current++
+		case 'C':
+			current += m.encodeC(current)
+		case 'D':
+			switch {
+			case m.is(current, "DG") && m.is(current+2, "I", "E", "Y"):
+				// edge
+				m.add("J")
+				current += 3
+			case m.is(current, "DG"):
+				// edgar
+				m.add("TK")
+				current += 2
+			case m.is(current, "DT", "DD"):
+				m.add("T")
+				current += 2
+			default:
+				m.add("T")
+				current++
+			}
+		case 'F':
+			m.add("F")
+			current += m.skip(current, 'F')
+		case 'G':
+			current += m.encodeG(current)
+		case 'H':
+			// kept first or between vowels only
+			if (current == 0 || m.vowel(current-1)) && m.vowel(current+1) {
+				m.add("H")
+				current += 2
+			} else {
+				current++
+			}
+		case 'J':
+			current += m.encodeJ(current)
+		case 'K':
+			m.add("K")
+			current += m.skip(current, 'K')
+		case 'L':
+			if m.at(current+1) == 'L' {
+				// Spanish, e.g. cabrillo, gallegos
+				if (current == m.length-3 && m.is(current-1, "ILLO", "ILLA", "ALLE")) ||
+					((m.is(m.last-1, "AS", "OS") || m.is(m.last, "A", "O")) && m.is(current-1, "ALLE")) {
+					m.add("L", "")
+					current += 2
+					continue
+				}
+				current += 2
+			} else {
+				current++
+			}
+			m.add("L")
+		case 'M':
+			if (m.is(current-1, "UMB") && (current+1 == m.last || m.is(current+2, "ER"))) || m.at(current+1) == 'M' {
+				current += 2
+			} else {
+				current++
+			}
+			m.add("M")
+		case 'N':
+			m.add("N")
+			current += m.skip(current, 'N')
+		case 'Ñ':
+			m.add("N")
+			current++
+		case 'P':
+			if m.at(current+1) == 'H' {
+				m.add("F")
+				current += 2
+				continue
+			}
+			// campbell, raspberry
+			if m.is(current+1, "P", "B") {
+				current += 2
+			} else {
+				current++
+			}
+			m.add("P")
+		case 'Q':
+			m.add("K")
+			current += m.skip(current, 'Q')
+		case 'R':
+			// French, e.g. rogier, but not hochmeier
+			if current == m.last && !m.slavoGermanic && m.is(current-2, "IE") && !m.is(current-4, "ME", "MA") {
+				m.add("", "R")
+			} else {
+				m.add("R")
+			}
+			current += m.skip(current, 'R')
+		case 'S':
+			current += m.encodeS(current)
+		case 'T':
+			switch {
+			case m.is(current, "TION"):
+				m.add("X")
+				current += 3
+			case m.is(current, "TIA", "TCH"):
+				m.add("X")
+				current += 3
+			case m.is(current, "TH") || m.is(current, "TTH"):
+				// thomas, thames or Germanic
+				if m.is(current+2, "OM", "AM") || m.germanic() {
+					m.add("T")
+				} else {
+					m.add("0", "T")
+				}
+				current += 2
+			default:
+				if m.is(current+1, "T", "D") {
+					current += 2
+				} else {
+					current++
+				}
+				m.add("T")
+			}
+		case 'V':
+			m.add("F")
+			current += m.skip(current, 'V')
+		case 'W':
+			current += m.encodeW(current)
+		case 'X':
+			// French, e.g. breaux
+			if !(current == m.last && (m.is(current-3, "IAU", "EAU") || m.is(current-2, "AU", "OU"))) {
+				m.add("KS")
+			}
+			if m.is(current+1, "C", "X") {
+				current += 2
+			} else {
+				current++
+			}
+		case 'Z':
+			// Chinese pinyin, e.g. zhao
+			if m.at(current+1) == 'H' {
+				m.add("J")
+				current += 2
+				continue
+			}
+			if m.is(current+1, "ZO", "ZI", "ZA") || (m.slavoGermanic && current > 0 && m.at(current-1) != 'T') {
+				m.add("S", "TS")
+			} else {
+				m.add("S")
+			}
+			current += m.skip(current, 'Z')
+		default:
+			current++
+		}
+	}
+}
+
+func (m *metaphone) encodeC(current int) int {
+	// various Germanic
+	if current > 1 && !m.vowel(current-2) && m.is(current-1, "ACH") &&
+		m.at(current+2) != 'I' && (m.at(current+2) != 'E' || m.is(current-2, "BACHER", "MACHER")) {
+		m.add("K")
+		return 2
+	}
+	// caesar
+	if current == 0 && m.is(current, "CAESAR") {
+		m.add("S")
+		return 2
+	}
+	// Italian chianti
+	if m.is(current, "CHIA") {
+		m.add("K")
+		return 2
+	}
+
+	if m.is(current, "CH") {
+		// michael
+		if current > 0 && m.is(current, "CHAE") {
+			m.add("K", "X")
+			return 2
+		}
+		// Greek roots, e.g. chemistry, chorus
+		if current == 0 && (m.is(current+1, "HARAC", "HARIS") || m.is(current+1, "HOR", "HYM", "HIA", "HEM")) && !m.is(0, "CHORE") {
+			m.add("K")
+			return 2
+		}
+		// Germanic, Greek or otherwise ch for kh sound
+		if m.germanic() || m.is(current-2, "ORCHES", "ARCHIT", "ORCHID") || m.is(current+2, "T", "S") ||
+			((m.is(current-1, "A", "O", "U", "E") || current == 0) &&
+				m.is(current+2, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ")) {
+			m.add("K")
+		} else if current > 0 {
+			if m.is(0, "MC") {
+				// mchugh
+				m.add("K")
+			} else {
+				m.add("X", "K")
+			}
+		} else {
+			m.add("X")
+		}
+		return 2
+	}
+
+	// czerny
+	if m.is(current, "CZ") && !m.is(current-2, "WICZ") {
+		m.add("S", "X")
+		return 2
+	}
+	// focaccia
+	if m.is(current+1, "CIA") {
+		m.add("X")
+		return 3
+	}
+	// double C, but not mcclellan
+	if m.is(current, "CC") && !(current == 1 && m.at(0) == 'M') {
+		// bellocchio, but not bacchus
+		if m.is(current+2, "I", "E", "H") && !m.is(current+2, "HU") {
+			// accident, accede, succeed
+			if (current == 1 && m.at(current-1) == 'A') || m.is(current-1, "UCCEE", "UCCES") {
+				m.add("KS")
+			} else {
+				// bacci, bertucci
+				m.add("X")
+			}
+			return 3
+		}
+		// Pierce's rule
+		m.add("K")
+		return 2
+	}
+	if m.is(current, "CK", "CG", "CQ") {
+		m.add("K")
+		return 2
+	}
+	if m.is(current, "CI", "CE", "CY") {
+		// Italian vs. English
+		if m.is(current, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return 2
+	}
+
+	m.add("K")
+	// mac caffrey, mac gregor
+	switch {
+	case m.is(current+1, " C", " Q", " G"):
+		return 3
+	case m.is(current+1, "C", "K", "Q") && !m.is(current+1, "CE", "CI"):
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeG(current int) int {
+	if m.at(current+1) == 'H' {
+		if current > 0 && !m.vowel(current-1) {
+			m.add("K")
+			return 2
+		}
+		// ghislane, ghiradelli
+		if current == 0 {
+			if m.at(current+2) == 'I' {
+				m.add("J")
+			} else {
+				m.add("K")
+			}
+			return 2
+		}
+		// Parker's rule: hugh, bough, broughton
+		if (current > 1 && m.is(current-2, "B", "H", "D")) ||
+			(current > 2 && m.is(current-3, "B", "H", "D")) ||
+			(current > 3 && m.is(current-4, "B", "H")) {
+			return 2
+		}
+		// laugh, mclaughlin, cough, gough, rough, tough
+		if current > 2 && m.at(current-1) == 'U' && m.is(current-3, "C", "G", "L", "R", "T") {
+			m.add("F")
+		} else if current > 0 && m.at(current-1) != 'I' {
+			m.add("K")
+		}
+		return 2
+	}
+
+	if m.at(current+1) == 'N' {
+		switch {
+		case current == 1 && m.vowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		// not cagney
+		case !m.is(current+2, "EY") && m.at(current+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.add("KN")
+		}
+		return 2
+	}
+	// tagliaro
+	if m.is(current+1, "LI") && !m.slavoGermanic {
+		m.add("KL", "L")
+		return 2
+	}
+	// -ges-, -gep-, -gel-, -gie- at the start
+	if current == 0 && (m.at(current+1) == 'Y' ||
+		m.is(current+1, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")) {
+		m.add("K", "J")
+		return 2
+	}
+	// -ger-, -gy-
+	if (m.is(current+1, "ER") || m.at(current+1) == 'Y') && !m.is(0, "DANGER", "RANGER", "MANGER") &&
+		!m.is(current-1, "E", "I") && !m.is(current-1, "RGY", "OGY") {
+		m.add("K", "J")
+		return 2
+	}
+	// Italian, e.g. biaggi
+	if m.is(current+1, "E", "I", "Y") || m.is(current-1, "AGGI", "OGGI") {
+		switch {
+		// obvious Germanic
+		case m.germanic() || m.is(current+1, "ET"):
+			m.add("K")
+		// always soft if French ending
+		case m.is(current+1, "IER "):
+			m.add("J")
+		default:
+			m.add("J", "K")
+		}
+		return 2
+	}
+
+	m.add("K")
+	return m.skip(current, 'G')
+}
+
+func (m *metaphone) encodeJ(current int) int {
+	// obvious Spanish, jose, san jacinto
+	if m.is(current, "JOSE") || m.is(0, "SAN ") {
+		if (current == 0 && m.at(current+4) == ' ') || m.is(0, "SAN ") {
+			m.add("H")
+		} else {
+			m.add("J", "H")
+		}
+		return 1
+	}
+
+	switch {
+	// yankelovich, jankelowicz
+	case current == 0:
+		m.add("J", "A")
+	// Spanish, e.g. bajador
+	case m.vowel(current-1) && !m.slavoGermanic && (m.at(current+1) == 'A' || m.at(current+1) == 'O'):
+		m.add("J", "H")
+	case current == m.last:
+		m.add("J", "")
+	case !m.is(current+1, "L", "T", "K", "S", "N", "M", "B", "Z") && !m.is(current-1, "S", "K", "L"):
+		m.add("J")
+	}
+	return m.skip(current, 'J')
+}
+
+func (m *metaphone) encodeS(current int) int {
+	// island, isle, carlisle, carlysle
+	if m.is(current-1, "ISL", "YSL") {
+		return 1
+	}
+	// sugar
+	if current == 0 && m.is(current, "SUGAR") {
+		m.add("X", "S")
+		return 1
+	}
+	if m.is(current, "SH") {
+		// Germanic
+		if m.is(current+1, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.add("S")
+		} else {
+			m.add("X")
+		}
+		return 2
+	}
+	// Italian and Armenian
+	if m.is(current, "SIO", "SIA") || m.is(current, "SIAN") {
+		if !m.slavoGermanic {
+			m.add("S", "X")
+		} else {
+			m.add("S")
+		}
+		return 3
+	}
+	// German and anglicisations, smith matches schmidt, snider matches
+	// schneider, also Slavic -sz-
+	if (current == 0 && m.is(current+1, "M", "N", "L", "W")) || m.is(current+1, "Z") {
+		m.add("S", "X")
+		if m.is(current+1, "Z") {
+			return 2
+		}
+		return 1
+	}
+	if m.is(current, "SC") {
+		// Schlesinger's rule
+		if m.at(current+2) == 'H' {
+			// Dutch, e.g. school, schooner
+			if m.is(current+3, "OO", "ER", "EN", "UY", "ED", "EM") {
+				// schermerhorn, schenker
+				if m.is(current+3, "ER", "EN") {
+					m.add("X", "SK")
+				} else {
+					m.add("SK")
+				}
+				return 3
+			}
+			if current == 0 && !m.vowel(3) && m.at(3) != 'W' {
+				m.add("X", "S")
+			} else {
+				m.add("X")
+			}
+			return 3
+		}
+		if m.is(current+2, "I", "E", "Y") {
+			m.add("S")
+			return 3
+		}
+		m.add("SK")
+		return 3
+	}
+
+	// French, e.g. resnais, artois
+	if current == m.last && m.is(current-2, "AI", "OI") {
+		m.add("", "S")
+	} else {
+		m.add("S")
+	}
+	if m.is(current+1, "S", "Z") {
+		return 2
+	}
+	return 1
+}
+
+func (m *metaphone) encodeW(current int) int {
+	// can also be in the middle of a word
+	if m.is(current, "WR") {
+		m.add("R")
+		return 2
+	}
+	if current == 0 && (m.vowel(current+1) || m.is(current, "WH")) {
+		// wasserman matches vasserman
+		if m.vowel(current + 1) {
+			m.add("A", "F")
+		} else {
+			// uomo matches womo
+			m.add("A")
+		}
+	}
+	// arnow matches arnoff
+	if (current == m.last && m.vowel(current-1)) || m.is(current-1, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.is(0, "SCH") {
+		m.add("", "F")
+		return 1
+	}
+	// Polish, e.g. filipowicz
+	if m.is(current, "WICZ", "WITZ") {
+		m.add("TS", "FX")
+		return 4
+	}
+	return 1
+}
@@ -0,0 +1,60 @@
+// Package phonetic computes keys of words that sound alike: Double Metaphone
+// for Latin words and Russian Metaphone for Cyrillic ones. Words sharing a
+// key are phonetic matches, e.g. Jon and John or Shmidt and Schmidt.
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Encode returns phonetic keys of a word, none when it has no letters. Latin
+// words have the primary Double Metaphone key and the alternate one if it
+// differs, Cyrillic words have a single Russian Metaphone key.
+func Encode(word string) []string {
+	if hasCyrillic(word) {
+		if key := russianMetaphone(word); key != "" {
+			return []string{key}
+		}
+		return nil
+	}
+
+	primary, alternate := doubleMetaphone(word)
+	switch {
+	case primary == "":
+		return nil
+	case alternate == "" || alternate == primary:
+		return []string{primary}
+	default:
+		return []string{primary, alternate}
+	}
+}
+
+// Keys returns phonetic keys of all words of the text, without repeats.
+// Words are split by spaces and hyphens.
+func Keys(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-'
+	})
+
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, word := range words {
+		for _, key := range Encode(word) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+func hasCyrillic(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package phonetic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Encode(t *testing.T) {
+	tests := []struct {
+		word string
+		keys []string
+	}{
+		{word: "John", keys: []string{"JN", "AN"}},
+		{word: "Jon", keys: []string{"JN", "AN"}},
+		{word: "Schmidt", keys: []string{"XMT", "SMT"}},
+		{word: "Shmidt", keys: []string{"XMT"}},
+		{word: "Smith", keys: []string{"SM0", "XMT"}},
+		{word: "Thompson", keys: []string{"TMPS"}},
+		{word: "Catherine", keys: []string{"K0RN", "KTRN"}},
+		{word: "Kathryn", keys: []string{"K0RN", "KTRN"}},
+		{word: "Alexander", keys: []string{"ALKS"}},
+		{word: "Aleksandr", keys: []string{"ALKS"}},
+		{word: "Knight", keys: []string{"NT"}},
+		{word: "Filipowicz", keys: []string{"FLPT", "FLPF"}},
+		{word: "Шмидт", keys: []string{"шмит"}},
+		{word: "Шмит", keys: []string{"шмит"}},
+		{word: "Пётр", keys: []string{"питр"}},
+		{word: "Петров", keys: []string{"питр4"}},
+		{word: "Ковалевская", keys: []string{"кавал%"}},
+		{word: "Федько", keys: []string{"фитка"}},
+		{word: "Федка", keys: []string{"фитка"}},
+		{word: "'", keys: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.word, func(t *testing.T) {
+			assert.Equal(t, tc.keys, Encode(tc.word))
+		})
+	}
+}
+
+func Test_Keys(t *testing.T) {
+	assert.Equal(t, []string{"JN", "AN", "SM0", "XMT"}, Keys("John Smith-Jon"))
+	assert.Equal(t, []string{"иван", "питр4"}, Keys("Иван  Петров"))
+	assert.Equal(t, []string{}, Keys(""))
+}
@@ -0,0 +1,79 @@
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// russianEndings compress common endings of surnames, longest first
+var russianEndings = []struct {
+	ending, key string
+}{
+	{"овский", "@"}, {"евский", "#"}, {"овская", "$"}, {"евская", "%"},
+	{"иева", "9"}, {"еева", "9"}, {"ова", "9"}, {"ева", "9"},
+	{"иев", "4"}, {"еев", "4"}, {"ов", "4"}, {"ев", "4"},
+	{"ина", "1"}, {"ин", "8"}, {"нко", "3"},
+	{"ых", "5"}, {"их", "5"}, {"ая", "6"}, {"ый", "7"}, {"ий", "7"},
+}
+
+var (
+	russianVowels = map[rune]rune{'о': 'а', 'ы': 'а', 'я': 'а', 'е': 'и', 'ё': 'и', 'э': 'и', 'ю': 'у'}
+	// voiced consonants are devoiced at the end of a word and before
+	// voiceless ones
+	russianDevoiced  = map[rune]rune{'б': 'п', 'з': 'с', 'д': 'т', 'в': 'ф', 'г': 'к', 'ж': 'ш'}
+	russianVoiceless = "пкстфхцчшщ"
+)
+
+// russianMetaphone returns the Russian Metaphone key of a Cyrillic word:
+// endings compressed, unstressed vowels merged, consonants devoiced and
+// repeated letters collapsed
+func russianMetaphone(word string) string {
+	var letters []rune
+	for _, r := range strings.ToLower(word) {
+		if unicode.Is(unicode.Cyrillic, r) {
+			letters = append(letters, r)
+		}
+	}
+
+	var ending string
+	for _, e := range russianEndings {
+		suffix := []rune(e.ending)
+		if len(letters) > len(suffix) && string(letters[len(letters)-len(suffix):]) == e.ending {
+			letters, ending = letters[:len(letters)-len(suffix)], e.key
+			break
+		}
+	}
+
+	var merged []rune
+	for i := 0; i < len(letters); i++ {
+		r := letters[i]
+		switch {
+		// йо, ио, йе, ие sound as и
+		case (r == 'й' || r == 'и') && i+1 < len(letters) && (letters[i+1] == 'о' || letters[i+1] == 'е'):
+			merged = append(merged, 'и')
+			i++
+		case r == 'ь' || r == 'ъ':
+		case russianVowels[r] != 0:
+			merged = append(merged, russianVowels[r])
+		default:
+			merged = append(merged, r)
+		}
+	}
+
+	var key []rune
+	for i, r := range merged {
+		if devoiced, ok := russianDevoiced[r]; ok && (i == len(merged)-1 || strings.ContainsRune(russianVoiceless, merged[i+1])) {
+			r = devoiced
+		}
+		// тс and дс sound as ц
+		if r == 'с' && len(key) > 0 && key[len(key)-1] == 'т' {
+			key[len(key)-1] = 'ц'
+			continue
+		}
+		if len(key) > 0 && key[len(key)-1] == r {
+			continue
+		}
+		key = append(key, r)
+	}
+	return string(key) + ending
+}
@@ -0,0 +1,850 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: api/persons/command/v1/command.proto
+
+package commandv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DuplicatePolicy int32
+
+const (
+	// the policy configured in the service
+	DuplicatePolicy_DUPLICATE_POLICY_UNSPECIFIED DuplicatePolicy = 0
+	// fail with ALREADY_EXISTS listing matching persons
+	DuplicatePolicy_DUPLICATE_POLICY_REJECT DuplicatePolicy = 1
+	// return the best matching person instead of creating one
+	DuplicatePolicy_DUPLICATE_POLICY_RETURN_EXISTING DuplicatePolicy = 2
+	// create the person with a link to the best match
+	DuplicatePolicy_DUPLICATE_POLICY_CREATE DuplicatePolicy = 3
+)
+
+// Enum value maps for DuplicatePolicy.
+var (
+	DuplicatePolicy_name = map[int32]string{
+		0: "DUPLICATE_POLICY_UNSPECIFIED",
+		1: "DUPLICATE_POLICY_REJECT",
+		2: "DUPLICATE_POLICY_RETURN_EXISTING",
+		3: "DUPLICATE_POLICY_CREATE",
+	}
+	DuplicatePolicy_value = map[string]int32{
+		"DUPLICATE_POLICY_UNSPECIFIED":     0,
+		"DUPLICATE_POLICY_REJECT":          1,
+		"DUPLICATE_POLICY_RETURN_EXISTING": 2,
+		"DUPLICATE_POLICY_CREATE":          3,
+	}
+)
+
+func (x DuplicatePolicy) Enum() *DuplicatePolicy {
+	p := new(DuplicatePolicy)
+	*p = x
+	return p
+}
+
+func (x DuplicatePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DuplicatePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_persons_command_v1_command_proto_enumTypes[0].Descriptor()
+}
+
+func (DuplicatePolicy) Type() protoreflect.EnumType {
+	return &file_api_persons_command_v1_command_proto_enumTypes[0]
+}
+
+func (x DuplicatePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DuplicatePolicy.Descriptor instead.
+func (DuplicatePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{0}
+}
+
+type Nationality struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 3166-1 alpha-2 country code
+	CountryId     string  `protobuf:"bytes,1,opt,name=country_id,json=countryId,proto3" json:"country_id,omitempty"`
+	Probability   float64 `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Nationality) Reset() {
+	*x = Nationality{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Nationality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nationality) ProtoMessage() {}
+
+func (x *Nationality) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nationality.ProtoReflect.Descriptor instead.
+func (*Nationality) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Nationality) GetCountryId() string {
+	if x != nil {
+		return x.CountryId
+	}
+	return ""
+}
+
+func (x *Nationality) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+type FullName struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Surname       string                 `protobuf:"bytes,2,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic    string                 `protobuf:"bytes,3,opt,name=patronymic,proto3" json:"patronymic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FullName) Reset() {
+	*x = FullName{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FullName) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FullName) ProtoMessage() {}
+
+func (x *FullName) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FullName.ProtoReflect.Descriptor instead.
+func (*FullName) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FullName) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FullName) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *FullName) GetPatronymic() string {
+	if x != nil {
+		return x.Patronymic
+	}
+	return ""
+}
+
+type Person struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Surname     string                 `protobuf:"bytes,3,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic  string                 `protobuf:"bytes,4,opt,name=patronymic,proto3" json:"patronymic,omitempty"`
+	Age         int32                  `protobuf:"varint,5,opt,name=age,proto3" json:"age,omitempty"`
+	Gender      string                 `protobuf:"bytes,6,opt,name=gender,proto3" json:"gender,omitempty"`
+	Nationalize []*Nationality         `protobuf:"bytes,7,rep,name=nationalize,proto3" json:"nationalize,omitempty"`
+	// full name as it was entered, before normalization
+	Original            *FullName `protobuf:"bytes,8,opt,name=original,proto3" json:"original,omitempty"`
+	PossibleDuplicateOf *int64    `protobuf:"varint,9,opt,name=possible_duplicate_of,json=possibleDuplicateOf,proto3,oneof" json:"possible_duplicate_of,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Person) Reset() {
+	*x = Person{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Person) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Person) ProtoMessage() {}
+
+func (x *Person) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Person.ProtoReflect.Descriptor instead.
+func (*Person) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Person) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Person) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Person) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *Person) GetPatronymic() string {
+	if x != nil {
+		return x.Patronymic
+	}
+	return ""
+}
+
+func (x *Person) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Person) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Person) GetNationalize() []*Nationality {
+	if x != nil {
+		return x.Nationalize
+	}
+	return nil
+}
+
+func (x *Person) GetOriginal() *FullName {
+	if x != nil {
+		return x.Original
+	}
+	return nil
+}
+
+func (x *Person) GetPossibleDuplicateOf() int64 {
+	if x != nil && x.PossibleDuplicateOf != nil {
+		return *x.PossibleDuplicateOf
+	}
+	return 0
+}
+
+type CreatePersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Surname       string                 `protobuf:"bytes,2,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic    string                 `protobuf:"bytes,3,opt,name=patronymic,proto3" json:"patronymic,omitempty"`
+	OnDuplicate   DuplicatePolicy        `protobuf:"varint,4,opt,name=on_duplicate,json=onDuplicate,proto3,enum=persons.command.v1.DuplicatePolicy" json:"on_duplicate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePersonRequest) Reset() {
+	*x = CreatePersonRequest{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonRequest) ProtoMessage() {}
+
+func (x *CreatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonRequest.ProtoReflect.Descriptor instead.
+func (*CreatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreatePersonRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetPatronymic() string {
+	if x != nil {
+		return x.Patronymic
+	}
+	return ""
+}
+
+func (x *CreatePersonRequest) GetOnDuplicate() DuplicatePolicy {
+	if x != nil {
+		return x.OnDuplicate
+	}
+	return DuplicatePolicy_DUPLICATE_POLICY_UNSPECIFIED
+}
+
+type CreatePersonResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Person *Person                `protobuf:"bytes,1,opt,name=person,proto3" json:"person,omitempty"`
+	// set when an existing person was returned instead of creating one
+	Existed       bool `protobuf:"varint,2,opt,name=existed,proto3" json:"existed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePersonResponse) Reset() {
+	*x = CreatePersonResponse{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePersonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePersonResponse) ProtoMessage() {}
+
+func (x *CreatePersonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePersonResponse.ProtoReflect.Descriptor instead.
+func (*CreatePersonResponse) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreatePersonResponse) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+func (x *CreatePersonResponse) GetExisted() bool {
+	if x != nil {
+		return x.Existed
+	}
+	return false
+}
+
+type NationalityList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Nationality         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NationalityList) Reset() {
+	*x = NationalityList{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NationalityList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NationalityList) ProtoMessage() {}
+
+func (x *NationalityList) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NationalityList.ProtoReflect.Descriptor instead.
+func (*NationalityList) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NationalityList) GetItems() []*Nationality {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type UpdatePersonRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name       *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Surname    *string                `protobuf:"bytes,3,opt,name=surname,proto3,oneof" json:"surname,omitempty"`
+	Patronymic *string                `protobuf:"bytes,4,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	Age        *int32                 `protobuf:"varint,5,opt,name=age,proto3,oneof" json:"age,omitempty"`
+	Gender     *string                `protobuf:"bytes,6,opt,name=gender,proto3,oneof" json:"gender,omitempty"`
+	// replaces nationalities when set, an empty list clears them
+	Nationalize   *NationalityList `protobuf:"bytes,7,opt,name=nationalize,proto3" json:"nationalize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePersonRequest) Reset() {
+	*x = UpdatePersonRequest{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePersonRequest) ProtoMessage() {}
+
+func (x *UpdatePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePersonRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePersonRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdatePersonRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetSurname() string {
+	if x != nil && x.Surname != nil {
+		return *x.Surname
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetAge() int32 {
+	if x != nil && x.Age != nil {
+		return *x.Age
+	}
+	return 0
+}
+
+func (x *UpdatePersonRequest) GetGender() string {
+	if x != nil && x.Gender != nil {
+		return *x.Gender
+	}
+	return ""
+}
+
+func (x *UpdatePersonRequest) GetNationalize() *NationalityList {
+	if x != nil {
+		return x.Nationalize
+	}
+	return nil
+}
+
+type UpdatePersonResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Person *Person                `protobuf:"bytes,1,opt,name=person,proto3" json:"person,omitempty"`
+	// derived fields re-enriched and stored with the update
+	Recomputed []string `protobuf:"bytes,2,rep,name=recomputed,proto3" json:"recomputed,omitempty"`
+	// derived fields being re-enriched in background
+	Scheduled     []string `protobuf:"bytes,3,rep,name=scheduled,proto3" json:"scheduled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePersonResponse) Reset() {
+	*x = UpdatePersonResponse{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePersonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePersonResponse) ProtoMessage() {}
+
+func (x *UpdatePersonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePersonResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePersonResponse) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdatePersonResponse) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+func (x *UpdatePersonResponse) GetRecomputed() []string {
+	if x != nil {
+		return x.Recomputed
+	}
+	return nil
+}
+
+func (x *UpdatePersonResponse) GetScheduled() []string {
+	if x != nil {
+		return x.Scheduled
+	}
+	return nil
+}
+
+type DeletePersonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePersonRequest) Reset() {
+	*x = DeletePersonRequest{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePersonRequest) ProtoMessage() {}
+
+func (x *DeletePersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePersonRequest.ProtoReflect.Descriptor instead.
+func (*DeletePersonRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeletePersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeletePersonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePersonResponse) Reset() {
+	*x = DeletePersonResponse{}
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePersonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePersonResponse) ProtoMessage() {}
+
+func (x *DeletePersonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_command_v1_command_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePersonResponse.ProtoReflect.Descriptor instead.
+func (*DeletePersonResponse) Descriptor() ([]byte, []int) {
+	return file_api_persons_command_v1_command_proto_rawDescGZIP(), []int{9}
+}
+
+var File_api_persons_command_v1_command_proto protoreflect.FileDescriptor
+
+const file_api_persons_command_v1_command_proto_rawDesc = "" +
+	"\n" +
+	"$api/persons/command/v1/command.proto\x12\x12persons.command.v1\"N\n" +
+	"\vNationality\x12\x1d\n" +
+	"\n" +
+	"country_id\x18\x01 \x01(\tR\tcountryId\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"X\n" +
+	"\bFullName\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x02 \x01(\tR\asurname\x12\x1e\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tR\n" +
+	"patronymic\"\xe0\x02\n" +
+	"\x06Person\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x03 \x01(\tR\asurname\x12\x1e\n" +
+	"\n" +
+	"patronymic\x18\x04 \x01(\tR\n" +
+	"patronymic\x12\x10\n" +
+	"\x03age\x18\x05 \x01(\x05R\x03age\x12\x16\n" +
+	"\x06gender\x18\x06 \x01(\tR\x06gender\x12A\n" +
+	"\vnationalize\x18\a \x03(\v2\x1f.persons.command.v1.NationalityR\vnationalize\x128\n" +
+	"\boriginal\x18\b \x01(\v2\x1c.persons.command.v1.FullNameR\boriginal\x127\n" +
+	"\x15possible_duplicate_of\x18\t \x01(\x03H\x00R\x13possibleDuplicateOf\x88\x01\x01B\x18\n" +
+	"\x16_possible_duplicate_of\"\xab\x01\n" +
+	"\x13CreatePersonRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x02 \x01(\tR\asurname\x12\x1e\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tR\n" +
+	"patronymic\x12F\n" +
+	"\fon_duplicate\x18\x04 \x01(\x0e2#.persons.command.v1.DuplicatePolicyR\vonDuplicate\"d\n" +
+	"\x14CreatePersonResponse\x122\n" +
+	"\x06person\x18\x01 \x01(\v2\x1a.persons.command.v1.PersonR\x06person\x12\x18\n" +
+	"\aexisted\x18\x02 \x01(\bR\aexisted\"H\n" +
+	"\x0fNationalityList\x125\n" +
+	"\x05items\x18\x01 \x03(\v2\x1f.persons.command.v1.NationalityR\x05items\"\xb4\x02\n" +
+	"\x13UpdatePersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x1d\n" +
+	"\asurname\x18\x03 \x01(\tH\x01R\asurname\x88\x01\x01\x12#\n" +
+	"\n" +
+	"patronymic\x18\x04 \x01(\tH\x02R\n" +
+	"patronymic\x88\x01\x01\x12\x15\n" +
+	"\x03age\x18\x05 \x01(\x05H\x03R\x03age\x88\x01\x01\x12\x1b\n" +
+	"\x06gender\x18\x06 \x01(\tH\x04R\x06gender\x88\x01\x01\x12E\n" +
+	"\vnationalize\x18\a \x01(\v2#.persons.command.v1.NationalityListR\vnationalizeB\a\n" +
+	"\x05_nameB\n" +
+	"\n" +
+	"\b_surnameB\r\n" +
+	"\v_patronymicB\x06\n" +
+	"\x04_ageB\t\n" +
+	"\a_gender\"\x88\x01\n" +
+	"\x14UpdatePersonResponse\x122\n" +
+	"\x06person\x18\x01 \x01(\v2\x1a.persons.command.v1.PersonR\x06person\x12\x1e\n" +
+	"\n" +
+	"recomputed\x18\x02 \x03(\tR\n" +
+	"recomputed\x12\x1c\n" +
+	"\tscheduled\x18\x03 \x03(\tR\tscheduled\"%\n" +
+	"\x13DeletePersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x16\n" +
+	"\x14DeletePersonResponse*\x93\x01\n" +
+	"\x0fDuplicatePolicy\x12 \n" +
+	"\x1cDUPLICATE_POLICY_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17DUPLICATE_POLICY_REJECT\x10\x01\x12$\n" +
+	" DUPLICATE_POLICY_RETURN_EXISTING\x10\x02\x12\x1b\n" +
+	"\x17DUPLICATE_POLICY_CREATE\x10\x032\xbf\x02\n" +
+	"\x14PersonCommandService\x12a\n" +
+	"\fCreatePerson\x12'.persons.command.v1.CreatePersonRequest\x1a(.persons.command.v1.CreatePersonResponse\x12a\n" +
+	"\fUpdatePerson\x12'.persons.command.v1.UpdatePersonRequest\x1a(.persons.command.v1.UpdatePersonResponse\x12a\n" +
+	"\fDeletePerson\x12'.persons.command.v1.DeletePersonRequest\x1a(.persons.command.v1.DeletePersonResponseBOZMgithub.com/pintoter/persons/services/command/api/persons/command/v1;commandv1b\x06proto3"
+
+var (
+	file_api_persons_command_v1_command_proto_rawDescOnce sync.Once
+	file_api_persons_command_v1_command_proto_rawDescData []byte
+)
+
+func file_api_persons_command_v1_command_proto_rawDescGZIP() []byte {
+	file_api_persons_command_v1_command_proto_rawDescOnce.Do(func() {
+		file_api_persons_command_v1_command_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_persons_command_v1_command_proto_rawDesc), len(file_api_persons_command_v1_command_proto_rawDesc)))
+	})
+	return file_api_persons_command_v1_command_proto_rawDescData
+}
+
+var file_api_persons_command_v1_command_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_persons_command_v1_command_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_api_persons_command_v1_command_proto_goTypes = []any{
+	(DuplicatePolicy)(0),         // 0: persons.command.v1.DuplicatePolicy
+	(*Nationality)(nil),          // 1: persons.command.v1.Nationality
+	(*FullName)(nil),             // 2: persons.command.v1.FullName
+	(*Person)(nil),               // 3: persons.command.v1.Person
+	(*CreatePersonRequest)(nil),  // 4: persons.command.v1.CreatePersonRequest
+	(*CreatePersonResponse)(nil), // 5: persons.command.v1.CreatePersonResponse
+	(*NationalityList)(nil),      // 6: persons.command.v1.NationalityList
+	(*UpdatePersonRequest)(nil),  // 7: persons.command.v1.UpdatePersonRequest
+	(*UpdatePersonResponse)(nil), // 8: persons.command.v1.UpdatePersonResponse
+	(*DeletePersonRequest)(nil),  // 9: persons.command.v1.DeletePersonRequest
+	(*DeletePersonResponse)(nil), // 10: persons.command.v1.DeletePersonResponse
+}
+var file_api_persons_command_v1_command_proto_depIdxs = []int32{
+	1,  // 0: persons.command.v1.Person.nationalize:type_name -> persons.command.v1.Nationality
+	2,  // 1: persons.command.v1.Person.original:type_name -> persons.command.v1.FullName
+	0,  // 2: persons.command.v1.CreatePersonRequest.on_duplicate:type_name -> persons.command.v1.DuplicatePolicy
+	3,  // 3: persons.command.v1.CreatePersonResponse.person:type_name -> persons.command.v1.Person
+	1,  // 4: persons.command.v1.NationalityList.items:type_name -> persons.command.v1.Nationality
+	6,  // 5: persons.command.v1.UpdatePersonRequest.nationalize:type_name -> persons.command.v1.NationalityList
+	3,  // 6: persons.command.v1.UpdatePersonResponse.person:type_name -> persons.command.v1.Person
+	4,  // 7: persons.command.v1.PersonCommandService.CreatePerson:input_type -> persons.command.v1.CreatePersonRequest
+	7,  // 8: persons.command.v1.PersonCommandService.UpdatePerson:input_type -> persons.command.v1.UpdatePersonRequest
+	9,  // 9: persons.command.v1.PersonCommandService.DeletePerson:input_type -> persons.command.v1.DeletePersonRequest
+	5,  // 10: persons.command.v1.PersonCommandService.CreatePerson:output_type -> persons.command.v1.CreatePersonResponse
+	8,  // 11: persons.command.v1.PersonCommandService.UpdatePerson:output_type -> persons.command.v1.UpdatePersonResponse
+	10, // 12: persons.command.v1.PersonCommandService.DeletePerson:output_type -> persons.command.v1.DeletePersonResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_api_persons_command_v1_command_proto_init() }
+func file_api_persons_command_v1_command_proto_init() {
+	if File_api_persons_command_v1_command_proto != nil {
+		return
+	}
+	file_api_persons_command_v1_command_proto_msgTypes[2].OneofWrappers = []any{}
+	file_api_persons_command_v1_command_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_persons_command_v1_command_proto_rawDesc), len(file_api_persons_command_v1_command_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_persons_command_v1_command_proto_goTypes,
+		DependencyIndexes: file_api_persons_command_v1_command_proto_depIdxs,
+		EnumInfos:         file_api_persons_command_v1_command_proto_enumTypes,
+		MessageInfos:      file_api_persons_command_v1_command_proto_msgTypes,
+	}.Build()
+	File_api_persons_command_v1_command_proto = out.File
+	file_api_persons_command_v1_command_proto_goTypes = nil
+	file_api_persons_command_v1_command_proto_depIdxs = nil
+}
@@ -0,0 +1,57 @@
+// Command webhook-receiver is a local endpoint for trying out webhooks. It
+// prints received deliveries, checks their signatures and can be told to
+// fail to exercise retries.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pintoter/persons/services/command/internal/webhooks"
+)
+
+const signatureInvalid = "invalid"
+
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	secret := flag.String("secret", "", "subscription secret to verify signatures with")
+	status := flag.Int("status", http.StatusNoContent, "response status code")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		verified := "unchecked"
+		if *secret != "" {
+			verified = signatureInvalid
+			if webhooks.Verify(*secret, r.Header.Get(webhooks.HeaderTimestamp), body, r.Header.Get(webhooks.HeaderSignature)) {
+				verified = "valid"
+			}
+		}
+
+		log.Printf("delivery=%s event=%s signature=%s body=%s",
+			r.Header.Get(webhooks.HeaderDelivery), r.Header.Get(webhooks.HeaderEvent), verified, body)
+
+		if verified == signatureInvalid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(*status)
+	})
+
+	server := &http.Server{
+		Addr:         *addr,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
@@ -8,17 +8,21 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 
 	_ "github.com/pintoter/persons/docs"
 	"github.com/pintoter/persons/pkg/database/postgres"
 	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/pkg/normalize"
 	"github.com/pintoter/persons/services/command/internal/client"
 	"github.com/pintoter/persons/services/command/internal/config"
 	migrations "github.com/pintoter/persons/services/command/internal/database"
+	"github.com/pintoter/persons/services/command/internal/events"
 	dbrepo "github.com/pintoter/persons/services/command/internal/repository/db"
 	"github.com/pintoter/persons/services/command/internal/server"
 	"github.com/pintoter/persons/services/command/internal/service"
 	"github.com/pintoter/persons/services/command/internal/transport"
+	"github.com/pintoter/persons/services/command/internal/webhooks"
 )
 
 // @title           			Persons
@@ -51,13 +55,40 @@ func Run() {
 
 	repo := dbrepo.New(db)
 
+	publisher, closePublisher, err := events.NewPublisher(&cfg.Outbox, db)
+	if err != nil {
+		logger.FatalKV(ctx, "Failed init events publisher", "err", err)
+	}
+	defer func() {
+		if closeErr := closePublisher(); closeErr != nil {
+			logger.ErrorKV(ctx, "Failed close events publisher", "err", closeErr)
+		}
+	}()
+
+	relayCtx, stopRelay := context.WithCancel(ctx)
+	relayDone := make(chan struct{})
+	go func() {
+		events.NewRelay(repo, publisher, &cfg.Outbox).Run(relayCtx)
+		close(relayDone)
+	}()
+
+	dispatcherDone := make(chan struct{})
+	go func() {
+		webhooks.NewDispatcher(repo, &cfg.Webhooks).Run(relayCtx)
+		close(dispatcherDone)
+	}()
+
 	httpClient := client.New(&cfg.Client)
+	normalizer := normalize.New(&cfg.Normalization)
 
-	service := service.New(repo, httpClient)
+	service := service.New(repo, httpClient, normalizer, &cfg.Duplicates, &cfg.Enrichment)
 	handler := transport.NewHandler(service)
+	grpcHandler := transport.NewGRPCHandler(service)
+	grpcServer := server.NewGRPC(&cfg.GRPC, grpcHandler.Register, grpc.ChainUnaryInterceptor(transport.AuditInterceptor))
 	server := server.New(handler, &cfg.HTTP)
 
 	server.Run()
+	grpcServer.Run()
 	logger.InfoKV(ctx, "Starting server")
 
 	quit := make(chan os.Signal, 1)
@@ -68,11 +99,20 @@ func Run() {
 		logger.InfoKV(ctx, "Starting gracefully shutdown")
 	case err = <-server.Notify():
 		logger.FatalKV(ctx, "Failed starting server", "err", err.Error())
+	case err = <-grpcServer.Notify():
+		logger.FatalKV(ctx, "Failed starting gRPC server", "err", err.Error())
 	}
 
+	grpcServer.Shutdown()
 	if err := server.Shutdown(); err != nil {
 		logger.FatalKV(ctx, "Failed shutdown server", "err", err.Error())
 	}
+
+	service.Wait()
+
+	stopRelay()
+	<-relayDone
+	<-dispatcherDone
 }
 
 func initLogger(ctx context.Context, cfg *config.Config) (syncFn func()) {
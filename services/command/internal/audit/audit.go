@@ -0,0 +1,102 @@
+// Package audit describes entries of the person change history
+package audit
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionMerge  Action = "merge"
+	ActionEnrich Action = "enrich"
+)
+
+const anonymous = "anonymous"
+
+// Meta identifies who made the change and within which request
+type Meta struct {
+	Actor     string
+	RequestID string
+}
+
+// Change holds field values before and after the change, nil means absent
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type Changes map[string]Change
+
+type metaKey struct{}
+
+type actionKey struct{}
+
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+func MetaFrom(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	if meta.Actor == "" {
+		meta.Actor = anonymous
+	}
+	return meta
+}
+
+// WithAction overrides the action changes made with ctx are recorded as
+func WithAction(ctx context.Context, action Action) context.Context {
+	return context.WithValue(ctx, actionKey{}, action)
+}
+
+func ActionFrom(ctx context.Context, fallback Action) Action {
+	if action, ok := ctx.Value(actionKey{}).(Action); ok {
+		return action
+	}
+	return fallback
+}
+
+var fieldNames = []string{"name", "surname", "patronymic", "age", "gender", "nationalize", "possible_duplicate_of"}
+
+// Diff returns changed fields between two states of a person.
+// Nil before means the person is created, nil after means it's deleted.
+func Diff(before, after *entity.Person) Changes {
+	b, a := fields(before), fields(after)
+
+	changes := Changes{}
+	for _, name := range fieldNames {
+		if !reflect.DeepEqual(b[name], a[name]) {
+			changes[name] = Change{Before: b[name], After: a[name]}
+		}
+	}
+	return changes
+}
+
+func fields(person *entity.Person) map[string]any {
+	if person == nil {
+		return nil
+	}
+
+	values := map[string]any{
+		"name":    person.Name,
+		"surname": person.Surname,
+		"age":     person.Age,
+		"gender":  person.Gender,
+	}
+	if person.Patronymic != "" {
+		values["patronymic"] = person.Patronymic
+	}
+	if len(person.Nationalize) > 0 {
+		values["nationalize"] = person.Nationalize
+	}
+	if person.PossibleDuplicateOf != nil {
+		values["possible_duplicate_of"] = *person.PossibleDuplicateOf
+	}
+	return values
+}
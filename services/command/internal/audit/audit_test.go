@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Diff(t *testing.T) {
+	person := entity.Person{
+		Name:        "Ivan",
+		Surname:     "Ivanov",
+		Age:         18,
+		Gender:      "male",
+		Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.2}},
+	}
+	renamed := person
+	renamed.Surname = "Petrov"
+	renamed.Patronymic = "Ivanovich"
+
+	tests := []struct {
+		name   string
+		before *entity.Person
+		after  *entity.Person
+		want   Changes
+	}{
+		{
+			name:  "Create",
+			after: &person,
+			want: Changes{
+				"name":        {After: "Ivan"},
+				"surname":     {After: "Ivanov"},
+				"age":         {After: 18},
+				"gender":      {After: "male"},
+				"nationalize": {After: person.Nationalize},
+			},
+		},
+		{
+			name:   "Update",
+			before: &person,
+			after:  &renamed,
+			want: Changes{
+				"surname":    {Before: "Ivanov", After: "Petrov"},
+				"patronymic": {After: "Ivanovich"},
+			},
+		},
+		{
+			name:   "Unchanged",
+			before: &person,
+			after:  &person,
+			want:   Changes{},
+		},
+		{
+			name:   "Delete",
+			before: &renamed,
+			want: Changes{
+				"name":        {Before: "Ivan"},
+				"surname":     {Before: "Petrov"},
+				"patronymic":  {Before: "Ivanovich"},
+				"age":         {Before: 18},
+				"gender":      {Before: "male"},
+				"nationalize": {Before: person.Nationalize},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.before, tt.after))
+		})
+	}
+}
+
+func Test_Context(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Meta{Actor: anonymous}, MetaFrom(ctx))
+	assert.Equal(t, ActionUpdate, ActionFrom(ctx, ActionUpdate))
+
+	ctx = WithAction(WithMeta(ctx, Meta{Actor: "admin", RequestID: "42"}), ActionEnrich)
+	assert.Equal(t, Meta{Actor: "admin", RequestID: "42"}, MetaFrom(ctx))
+	assert.Equal(t, ActionEnrich, ActionFrom(ctx, ActionUpdate))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pintoter/persons/pkg/normalize"
 	"github.com/spf13/viper"
 )
 
@@ -45,6 +46,20 @@ func (h *HTTP) GetShutdownTimeout() time.Duration {
 	return h.ShutdownTimeout
 }
 
+type GRPC struct {
+	Host            string
+	Port            string
+	ShutdownTimeout time.Duration
+}
+
+func (g *GRPC) GetAddr() string {
+	return fmt.Sprintf("%s:%s", g.Host, g.Port)
+}
+
+func (g *GRPC) GetShutdownTimeout() time.Duration {
+	return g.ShutdownTimeout
+}
+
 type DB struct {
 	User            string
 	Password        string
@@ -96,11 +111,112 @@ func (c *Client) GetTimeout() time.Duration {
 	return c.Timeout
 }
 
+// Normalization is shared with the other service
+type Normalization = normalize.Settings
+
+type Duplicates struct {
+	Policy    string
+	Fuzzy     bool
+	Threshold float64
+}
+
+func (d *Duplicates) GetPolicy() string {
+	return d.Policy
+}
+
+func (d *Duplicates) IsFuzzy() bool {
+	return d.Fuzzy
+}
+
+func (d *Duplicates) GetThreshold() float64 {
+	return d.Threshold
+}
+
+type Enrichment struct {
+	ReEnrich        string
+	ReEnrichTimeout time.Duration
+}
+
+func (e *Enrichment) GetReEnrichMode() string {
+	return e.ReEnrich
+}
+
+func (e *Enrichment) GetReEnrichTimeout() time.Duration {
+	return e.ReEnrichTimeout
+}
+
+type Outbox struct {
+	Publisher     string
+	File          string
+	Channel       string
+	RelayInterval time.Duration
+	BatchSize     int
+}
+
+func (o *Outbox) GetPublisher() string {
+	return o.Publisher
+}
+
+func (o *Outbox) GetFile() string {
+	return o.File
+}
+
+func (o *Outbox) GetChannel() string {
+	return o.Channel
+}
+
+func (o *Outbox) GetRelayInterval() time.Duration {
+	return o.RelayInterval
+}
+
+func (o *Outbox) GetBatchSize() int {
+	return o.BatchSize
+}
+
+type Webhooks struct {
+	Interval    time.Duration
+	BatchSize   int
+	Timeout     time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+func (w *Webhooks) GetInterval() time.Duration {
+	return w.Interval
+}
+
+func (w *Webhooks) GetBatchSize() int {
+	return w.BatchSize
+}
+
+func (w *Webhooks) GetTimeout() time.Duration {
+	return w.Timeout
+}
+
+func (w *Webhooks) GetMaxAttempts() int {
+	return w.MaxAttempts
+}
+
+func (w *Webhooks) GetBackoffBase() time.Duration {
+	return w.BackoffBase
+}
+
+func (w *Webhooks) GetBackoffMax() time.Duration {
+	return w.BackoffMax
+}
+
 type Config struct {
-	HTTP    HTTP
-	DB      DB
-	Project Project
-	Client  Client
+	HTTP          HTTP
+	GRPC          GRPC
+	DB            DB
+	Project       Project
+	Client        Client
+	Normalization Normalization
+	Duplicates    Duplicates
+	Enrichment    Enrichment
+	Outbox        Outbox
+	Webhooks      Webhooks
 }
 
 var config = new(Config)
@@ -7,4 +7,20 @@ var (
 	ErrInternalService = errors.New("unexpected server error")
 	ErrInvalidInput    = errors.New("invalid input parameters")
 	ErrInvalidQueryId  = errors.New("invalid ID")
+	ErrPersonDuplicate = errors.New("person already exists")
+	ErrNameChanged     = errors.New("person name changed meanwhile")
 )
+
+// DuplicateError is returned when creation is rejected because of persons
+// with the same full name
+type DuplicateError struct {
+	IDs []int
+}
+
+func (e *DuplicateError) Error() string {
+	return ErrPersonDuplicate.Error()
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrPersonDuplicate
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 const (
 	Male   = "male"
 	Female = "female"
@@ -10,6 +12,12 @@ type Nationality struct {
 	Probability float64 `json:"probability"`
 }
 
+type FullName struct {
+	Name       string `json:"name"`
+	Surname    string `json:"surname"`
+	Patronymic string `json:"patronymic,omitempty"`
+}
+
 type Person struct {
 	ID          int           `json:"id"`
 	Name        string        `json:"name"`
@@ -18,4 +26,9 @@ type Person struct {
 	Age         int           `json:"age"`
 	Gender      string        `json:"gender"`
 	Nationalize []Nationality `json:"nationalize"`
+	Original    *FullName     `json:"original,omitempty"`
+
+	PossibleDuplicateOf *int `json:"possible_duplicate_of,omitempty"`
+
+	UpdatedAt time.Time `json:"-"`
 }
@@ -0,0 +1,49 @@
+// Package events describes person domain events and delivers them from the
+// transactional outbox to publishers
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+type Type string
+
+const (
+	PersonCreated Type = "PersonCreated"
+	PersonUpdated Type = "PersonUpdated"
+	PersonDeleted Type = "PersonDeleted"
+	// PersonEnriched is an update of derived fields made by background
+	// re-enrichment
+	PersonEnriched Type = "PersonEnriched"
+)
+
+// Event is a change of person stored in the outbox. ID grows monotonically
+// and lets consumers drop events delivered more than once.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       Type            `json:"type"`
+	PersonID   int             `json:"person_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Payload is the body of person events
+type Payload struct {
+	Person     *entity.Person `json:"person,omitempty"`
+	Changes    audit.Changes  `json:"changes,omitempty"`
+	MergedInto *int           `json:"merged_into,omitempty"`
+	// Phonetic holds phonetic keys of the person name and surname, the read
+	// model indexes them for phonetic search
+	Phonetic []string `json:"phonetic,omitempty"`
+}
+
+// Publisher delivers events to consumers. Events are published one by one
+// in the outbox order.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var occurredAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func Test_InProcess(t *testing.T) {
+	p := NewInProcess()
+
+	var got []int64
+	p.Subscribe(func(ctx context.Context, event Event) error {
+		got = append(got, event.ID)
+		return nil
+	})
+	p.Subscribe(func(ctx context.Context, event Event) error {
+		if event.ID == 2 {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	assert.NoError(t, p.Publish(context.Background(), Event{ID: 1}))
+	assert.Error(t, p.Publish(context.Background(), Event{ID: 2}))
+	assert.Equal(t, []int64{1, 2}, got)
+}
+
+func Test_Writer(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewWriter(&buf)
+
+	err := p.Publish(context.Background(), Event{
+		ID:         1,
+		Type:       PersonCreated,
+		PersonID:   5,
+		Payload:    []byte(`{"person":{"id":5}}`),
+		OccurredAt: occurredAt,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"type":"PersonCreated","person_id":5,"payload":{"person":{"id":5}},"occurred_at":"2026-10-19T12:00:00Z"}`+"\n", buf.String())
+}
+
+func Test_Notify(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	p := NewNotify(db, "person_events")
+	query := regexp.QuoteMeta("SELECT pg_notify($1, $2)")
+
+	mock.ExpectExec(query).
+		WithArgs("person_events", `{"id":1,"type":"PersonDeleted","person_id":5,"payload":{"merged_into":3},"occurred_at":"2026-10-19T12:00:00Z"}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = p.Publish(context.Background(), Event{ID: 1, Type: PersonDeleted, PersonID: 5, Payload: []byte(`{"merged_into":3}`), OccurredAt: occurredAt})
+	assert.NoError(t, err)
+
+	// payload exceeding NOTIFY limit is dropped
+	mock.ExpectExec(query).
+		WithArgs("person_events", `{"id":2,"type":"PersonUpdated","person_id":5,"occurred_at":"2026-10-19T12:00:00Z"}`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	large := []byte(`{"changes":"` + strings.Repeat("a", maxNotifyPayload) + `"}`)
+	err = p.Publish(context.Background(), Event{ID: 2, Type: PersonUpdated, PersonID: 5, Payload: large, OccurredAt: occurredAt})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+type fakeOutbox struct {
+	mu      sync.Mutex
+	pending []Event
+}
+
+func (o *fakeOutbox) ProcessOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []Event) (int, error)) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	batch := o.pending[:min(limit, len(o.pending))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	published, err := publish(ctx, batch)
+	o.pending = o.pending[published:]
+	return published, err
+}
+
+type relayConfig struct{}
+
+func (relayConfig) GetRelayInterval() time.Duration { return time.Millisecond }
+func (relayConfig) GetBatchSize() int               { return 2 }
+
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failOnce  map[int64]bool
+	published []int64
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failOnce[event.ID] {
+		delete(p.failOnce, event.ID)
+		return errors.New("publisher is unavailable")
+	}
+	p.published = append(p.published, event.ID)
+	return nil
+}
+
+func (p *flakyPublisher) Published() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]int64(nil), p.published...)
+}
+
+func Test_Relay(t *testing.T) {
+	outbox := &fakeOutbox{pending: []Event{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}}
+	publisher := &flakyPublisher{failOnce: map[int64]bool{3: true}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewRelay(outbox, publisher, relayConfig{}).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.Published()) == 5
+	}, time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	// failed event is retried before the following ones
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, publisher.Published())
+}
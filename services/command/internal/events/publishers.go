@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Handler consumes events published in process
+type Handler func(ctx context.Context, event Event) error
+
+// InProcess publishes events to handlers subscribed in the same process
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewInProcess() *InProcess {
+	return &InProcess{}
+}
+
+func (p *InProcess) Subscribe(handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.handlers = append(p.handlers, handler)
+}
+
+// Publish passes event to every handler and fails on the first error, so
+// the event is published again later
+func (p *InProcess) Publish(ctx context.Context, event Event) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, handler := range p.handlers {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writer publishes events as JSON lines, e.g. to stdout or a file
+type Writer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+func (p *Writer) Publish(_ context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.encoder.Encode(event)
+}
+
+// maxNotifyPayload is a bit less than the default 8000 bytes limit of
+// NOTIFY payload
+const maxNotifyPayload = 7900
+
+// Notify publishes events with Postgres NOTIFY on the channel. Events too
+// large for NOTIFY are sent without payload.
+type Notify struct {
+	db      *sql.DB
+	channel string
+}
+
+func NewNotify(db *sql.DB, channel string) *Notify {
+	return &Notify{
+		db:      db,
+		channel: channel,
+	}
+}
+
+func (p *Notify) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if len(data) > maxNotifyPayload {
+		event.Payload = nil
+		if data, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+
+	_, err = p.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", p.channel, string(data))
+	return err
+}
+
+const (
+	PublisherInProcess = "inprocess"
+	PublisherStdout    = "stdout"
+	PublisherFile      = "file"
+	PublisherNotify    = "notify"
+)
+
+type PublisherConfig interface {
+	GetPublisher() string
+	GetFile() string
+	GetChannel() string
+}
+
+// NewPublisher builds the publisher chosen in config. The returned close
+// function releases resources held by the publisher.
+func NewPublisher(cfg PublisherConfig, db *sql.DB) (Publisher, func() error, error) {
+	noop := func() error { return nil }
+
+	switch cfg.GetPublisher() {
+	case PublisherInProcess:
+		return NewInProcess(), noop, nil
+	case PublisherStdout, "":
+		return NewWriter(os.Stdout), noop, nil
+	case PublisherFile:
+		f, err := os.OpenFile(cfg.GetFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewWriter(f), f.Close, nil
+	case PublisherNotify:
+		return NewNotify(db, cfg.GetChannel()), noop, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown events publisher %q", cfg.GetPublisher())
+	}
+}
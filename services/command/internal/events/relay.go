@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/pintoter/persons/pkg/logger"
+)
+
+// Outbox gives access to unpublished events. ProcessOutbox passes the oldest
+// events to publish and marks as published as many of them as it reports.
+type Outbox interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []Event) (int, error)) (int, error)
+}
+
+type RelayConfig interface {
+	GetRelayInterval() time.Duration
+	GetBatchSize() int
+}
+
+// Relay moves events from the outbox to the publisher. Events are marked
+// published only after successful delivery, so delivery is at-least-once.
+// Publishing stops at the first failed event to keep the order per person.
+type Relay struct {
+	outbox    Outbox
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRelay(outbox Outbox, publisher Publisher, cfg RelayConfig) *Relay {
+	return &Relay{
+		outbox:    outbox,
+		publisher: publisher,
+		interval:  cfg.GetRelayInterval(),
+		batchSize: cfg.GetBatchSize(),
+	}
+}
+
+// Run relays events until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	layer := "events.Relay"
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := r.outbox.ProcessOutbox(ctx, r.batchSize, r.publish)
+		if err != nil {
+			logger.ErrorKV(ctx, "relay events", "layer", layer, "published", published, "err", err)
+		}
+
+		// full batch means there are probably more events waiting
+		if err == nil && published == r.batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.interval)
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, events []Event) (int, error) {
+	for i, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/events"
+)
+
+// outboxLockKey guards the outbox from concurrent relays, which would break
+// the order of events
+const outboxLockKey = 7_305_221_034
+
+func insertEventBuilder(eventType events.Type, personID int, payload events.Payload) (string, []interface{}, error) {
+	if payload.Person != nil {
+		payload.Phonetic = phoneticKeys(*payload.Person)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	builder := sq.Insert(outboxTable).
+		Columns("event_type", "aggregate_id", "payload").
+		Values(eventType, personID, data).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// writeEvent stores event in the outbox within tx of the change
+func writeEvent(ctx context.Context, tx *sql.Tx, eventType events.Type, personID int, payload events.Payload) error {
+	logMethod := "repository.writeEvent"
+
+	query, args, err := insertEventBuilder(eventType, personID, payload)
+	logger.DebugKV(ctx, "insert event builder", "layer", logMethod, "query", query, "err", err)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func unpublishedEventsBuilder(limit int) (string, []interface{}, error) {
+	builder := sq.Select("id", "event_type", "aggregate_id", "payload", "created_at").
+		From(outboxTable).
+		Where(sq.Eq{"published_at": nil}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func markPublishedBuilder(ids []int64) (string, []interface{}, error) {
+	builder := sq.Update(outboxTable).
+		Set("published_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": ids}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// ProcessOutbox passes up to limit oldest unpublished events to publish and
+// marks as published the number of events it reports. Nothing is done while
+// another relay holds the outbox.
+func (r *DBRepo) ProcessOutbox(ctx context.Context, limit int, publish func(ctx context.Context, events []events.Event) (int, error)) (int, error) {
+	logMethod := "repository.ProcessOutbox"
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var locked bool
+	if err = tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxLockKey).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		logger.DebugKV(ctx, "outbox is locked by another relay", "layer", logMethod)
+		return 0, nil
+	}
+
+	unpublished, err := r.unpublishedEvents(ctx, tx, limit)
+	if err != nil || len(unpublished) == 0 {
+		return 0, err
+	}
+
+	published, publishErr := publish(ctx, unpublished)
+	logger.DebugKV(ctx, "publish events", "layer", logMethod, "published", published, "err", publishErr)
+	if published == 0 {
+		return 0, publishErr
+	}
+
+	ids := make([]int64, 0, published)
+	for _, event := range unpublished[:published] {
+		ids = append(ids, event.ID)
+	}
+
+	query, args, err := markPublishedBuilder(ids)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
+
+func (r *DBRepo) unpublishedEvents(ctx context.Context, tx *sql.Tx, limit int) ([]events.Event, error) {
+	query, args, err := unpublishedEventsBuilder(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var unpublished []events.Event
+	for rows.Next() {
+		var event events.Event
+		var payload []byte
+		if err = rows.Scan(&event.ID, &event.Type, &event.PersonID, &payload, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		unpublished = append(unpublished, event)
+	}
+
+	return unpublished, rows.Err()
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_insertEventBuilder(t *testing.T) {
+	query, args, err := insertEventBuilder(events.PersonCreated, 1, events.Payload{
+		Person: &entity.Person{ID: 1, Name: "Jon", Surname: "Shmidt", Age: 30, Gender: "male"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO outbox (event_type,aggregate_id,payload) VALUES ($1,$2,$3)", query)
+	assert.Equal(t, []interface{}{events.PersonCreated, 1,
+		[]byte(`{"person":{"id":1,"name":"Jon","surname":"Shmidt","age":30,"gender":"male","nationalize":null},"phonetic":["JN","AN","XMT"]}`)}, args)
+}
+
+func Test_ProcessOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	lockQuery := "SELECT pg_try_advisory_xact_lock($1)"
+	selectQuery := "SELECT id, event_type, aggregate_id, payload, created_at FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT 10"
+	columns := []string{"id", "event_type", "aggregate_id", "payload", "created_at"}
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	unpublished := []events.Event{
+		{ID: 1, Type: events.PersonCreated, PersonID: 1, Payload: []byte(`{"person":{"id":1}}`), OccurredAt: createdAt},
+		{ID: 2, Type: events.PersonUpdated, PersonID: 1, Payload: []byte(`{"person":{"id":1}}`), OccurredAt: createdAt},
+	}
+	expectUnpublished := func() {
+		rows := sqlmock.NewRows(columns)
+		for _, event := range unpublished {
+			rows.AddRow(event.ID, event.Type, event.PersonID, []byte(event.Payload), event.OccurredAt)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  func()
+		publish       func(ctx context.Context, got []events.Event) (int, error)
+		wantPublished int
+		wantErr       bool
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				expectUnpublished()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET published_at = now() WHERE id IN ($1,$2)")).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			publish: func(ctx context.Context, got []events.Event) (int, error) {
+				assert.Equal(t, unpublished, got)
+				return len(got), nil
+			},
+			wantPublished: 2,
+		},
+		{
+			name: "PartiallyPublished",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				expectUnpublished()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET published_at = now() WHERE id IN ($1)")).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			publish: func(ctx context.Context, got []events.Event) (int, error) {
+				return 1, errors.New("publisher is unavailable")
+			},
+			wantPublished: 1,
+			wantErr:       true,
+		},
+		{
+			name: "NothingPublished",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+				expectUnpublished()
+				mock.ExpectRollback()
+			},
+			publish: func(ctx context.Context, got []events.Event) (int, error) {
+				return 0, errors.New("publisher is unavailable")
+			},
+			wantErr: true,
+		},
+		{
+			name: "LockedByAnotherRelay",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).WithArgs(outboxLockKey).
+					WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+				mock.ExpectRollback()
+			},
+			publish: func(ctx context.Context, got []events.Event) (int, error) {
+				t.Fatal("publish must not be called")
+				return 0, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			published, err := r.ProcessOutbox(context.Background(), 10, tt.publish)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantPublished, published)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectEvent expects event of person to be written to the outbox
+func expectEvent(mock sqlmock.Sqlmock, eventType events.Type, personID int) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox (event_type,aggregate_id,payload) VALUES ($1,$2,$3)")).
+		WithArgs(eventType, personID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/audit"
+)
+
+func insertAuditBuilder(personID int, action audit.Action, meta audit.Meta, changes audit.Changes) (string, []interface{}, error) {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	builder := sq.Insert(auditTable).
+		Columns("person_id", "action", "actor", "request_id", "changes").
+		Values(personID, action, meta.Actor, meta.RequestID, data).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// writeAudit appends history entry for person in tx. Action from ctx takes
+// precedence over the given one, entries without changes are skipped.
+func writeAudit(ctx context.Context, tx *sql.Tx, personID int, action audit.Action, changes audit.Changes) error {
+	logMethod := "repository.writeAudit"
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	query, args, err := insertAuditBuilder(personID, audit.ActionFrom(ctx, action), audit.MetaFrom(ctx), changes)
+	logger.DebugKV(ctx, "insert audit builder", "layer", logMethod, "query", query, "err", err)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
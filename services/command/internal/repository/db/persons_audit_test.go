@@ -0,0 +1,35 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_insertAuditBuilder(t *testing.T) {
+	query, args, err := insertAuditBuilder(1, audit.ActionUpdate, audit.Meta{Actor: "admin", RequestID: "42"}, audit.Changes{
+		"surname": {Before: "Ivanov", After: "Petrov"},
+		"age":     {Before: 18, After: nil},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO person_audit (person_id,action,actor,request_id,changes) VALUES ($1,$2,$3,$4,$5)", query)
+	assert.Equal(t, []interface{}{1, audit.ActionUpdate, "admin", "42",
+		[]byte(`{"age":{"before":18,"after":null},"surname":{"before":"Ivanov","after":"Petrov"}}`)}, args)
+}
+
+// expectAudit expects history entry written by anonymous actor. Empty changes match any.
+func expectAudit(mock sqlmock.Sqlmock, personID int, action audit.Action, changes string) {
+	var changesArg interface{} = sqlmock.AnyArg()
+	if changes != "" {
+		changesArg = []byte(changes)
+	}
+
+	expectedQuery := "INSERT INTO person_audit (person_id,action,actor,request_id,changes) VALUES ($1,$2,$3,$4,$5)"
+	mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+		WithArgs(personID, action, "anonymous", "", changesArg).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
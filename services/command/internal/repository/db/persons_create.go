@@ -6,13 +6,28 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/audit"
 	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/service"
 )
 
 func createPersonBuilder(person entity.Person) (string, []interface{}, error) {
+	original := person.Original
+	if original == nil {
+		original = &entity.FullName{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}
+	}
+
+	columns := []string{"name", "surname", "patronymic", "age", "gender", "name_original", "surname_original", "patronymic_original"}
+	values := []interface{}{person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, original.Name, original.Surname, original.Patronymic}
+	if person.PossibleDuplicateOf != nil {
+		columns = append(columns, "possible_duplicate_of")
+		values = append(values, *person.PossibleDuplicateOf)
+	}
+
 	builder := sq.Insert(personTable).
-		Columns("name", "surname", "patronymic", "age", "gender").
-		Values(person.Name, person.Surname, person.Patronymic, person.Age, person.Gender).
+		Columns(columns...).
+		Values(values...).
 		Suffix("RETURNING id").
 		PlaceholderFormat(sq.Dollar)
 
@@ -31,7 +46,10 @@ func createNationalizeBuilder(person entity.Person) (string, []interface{}, erro
 	return builder.ToSql()
 }
 
-func (r *DBRepo) Create(ctx context.Context, person entity.Person) (int, error) {
+// Create stores the person. With check set, duplicates are looked up again
+// in the transaction, so concurrent creations of the same person can't both
+// pass the duplicate policy.
+func (r *DBRepo) Create(ctx context.Context, person entity.Person, check *service.DuplicateCheck) (int, error) {
 	logMethod := "repository.Create"
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelReadCommitted,
@@ -43,6 +61,12 @@ func (r *DBRepo) Create(ctx context.Context, person entity.Person) (int, error)
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	if check != nil {
+		if person.PossibleDuplicateOf, err = checkDuplicates(ctx, tx, person, check); err != nil {
+			return 0, err
+		}
+	}
+
 	query, args, err := createPersonBuilder(person)
 	logger.DebugKV(ctx, "create builder", "layer", logMethod, "query", query, "args", args, "err", err)
 	if err != nil {
@@ -66,6 +90,15 @@ func (r *DBRepo) Create(ctx context.Context, person entity.Person) (int, error)
 	if err != nil {
 		return 0, err
 	}
+	if err = writeAudit(ctx, tx, person.ID, audit.ActionCreate, audit.Diff(nil, &person)); err != nil {
+		return 0, err
+	}
+	if err = writeVersion(ctx, tx, person.ID, &person); err != nil {
+		return 0, err
+	}
+	if err = writeEvent(ctx, tx, events.PersonCreated, person.ID, events.Payload{Person: &person}); err != nil {
+		return 0, err
+	}
 	logger.DebugKV(ctx, "end of creating person", "layer", logMethod)
 
 	return person.ID, tx.Commit()
@@ -8,7 +8,10 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/audit"
 	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/service"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,6 +26,7 @@ func Test_Create(t *testing.T) {
 
 	type args struct {
 		person entity.Person
+		check  *service.DuplicateCheck
 	}
 
 	type mockBehavior func(args args)
@@ -39,7 +43,7 @@ func Test_Create(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
-				expectedExecInPerson := "INSERT INTO person (name,surname,patronymic,age,gender) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+				expectedExecInPerson := "INSERT INTO person (name,surname,patronymic,age,gender,name_original,surname_original,patronymic_original) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
 				mock.ExpectQuery(regexp.QuoteMeta(expectedExecInPerson)).
 					WithArgs(
 						args.person.Name,
@@ -47,6 +51,9 @@ func Test_Create(t *testing.T) {
 						args.person.Patronymic,
 						args.person.Age,
 						args.person.Gender,
+						args.person.Name,
+						args.person.Surname,
+						args.person.Patronymic,
 					).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 				expectedExecInNationality := "INSERT INTO person_nationality (person_id,nationalize,probability) VALUES ($1,$2,$3),($4,$5,$6)"
@@ -55,6 +62,10 @@ func Test_Create(t *testing.T) {
 					WithArgs(1, args.person.Nationalize[0].Country, args.person.Nationalize[0].Probability, 1, args.person.Nationalize[1].Country, args.person.Nationalize[1].Probability).
 					WillReturnResult(sqlmock.NewResult(0, 2))
 
+				expectAudit(mock, 1, audit.ActionCreate, "")
+				expectVersion(mock, 1, false)
+				expectEvent(mock, events.PersonCreated, 1)
+
 				mock.ExpectCommit()
 			},
 			args: args{
@@ -83,7 +94,7 @@ func Test_Create(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
-				expectedExec := "INSERT INTO person (name,surname,patronymic,age,gender) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+				expectedExec := "INSERT INTO person (name,surname,patronymic,age,gender,name_original,surname_original,patronymic_original) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
 				mock.ExpectQuery(regexp.QuoteMeta(expectedExec)).
 					WithArgs(
 						args.person.Name,
@@ -91,12 +102,19 @@ func Test_Create(t *testing.T) {
 						args.person.Patronymic,
 						args.person.Age,
 						args.person.Gender,
+						args.person.Name,
+						args.person.Surname,
+						args.person.Patronymic,
 					).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 				expectedExecInNationality := "INSERT INTO person_nationality (person_id,nationalize,probability) VALUES ($1,$2,$3)"
 				mock.ExpectExec(regexp.QuoteMeta(expectedExecInNationality)).
 					WithArgs(1, args.person.Nationalize[0].Country, args.person.Nationalize[0].Probability, 1, args.person.Nationalize[1].Country, args.person.Nationalize[1].Probability).
 					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				expectAudit(mock, 1, audit.ActionCreate, "")
+				expectVersion(mock, 1, false)
+				expectEvent(mock, events.PersonCreated, 1)
 				mock.ExpectCommit()
 			},
 			args: args{
@@ -119,12 +137,72 @@ func Test_Create(t *testing.T) {
 			},
 			wantId: 1,
 		},
+		{
+			name: "Success_WithDuplicateCheck",
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).
+					WithArgs(duplicateLockClass, "ivanov ivan ").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, surname, COALESCE(patronymic, ''), age, gender FROM person")).
+					WithArgs("ivan", "ivanov", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender"}).
+						AddRow(3, "Ivan", "Ivanov", "", 40, "male"))
+
+				expectedExec := "INSERT INTO person (name,surname,patronymic,age,gender,name_original,surname_original,patronymic_original,possible_duplicate_of) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING id"
+				mock.ExpectQuery(regexp.QuoteMeta(expectedExec)).
+					WithArgs("Ivan", "Ivanov", "", 18, "male", "Ivan", "Ivanov", "", 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO person_nationality (person_id,nationalize,probability) VALUES ($1,$2,$3)")).
+					WithArgs(4, "RU", 0.5).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				expectAudit(mock, 4, audit.ActionCreate, "")
+				expectVersion(mock, 4, false)
+				expectEvent(mock, events.PersonCreated, 4)
+				mock.ExpectCommit()
+			},
+			args: args{
+				person: entity.Person{
+					Name:        "Ivan",
+					Surname:     "Ivanov",
+					Age:         18,
+					Gender:      "male",
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.5}},
+				},
+				check: &service.DuplicateCheck{Resolve: func(candidates []entity.Person) (*int, error) {
+					return &candidates[0].ID, nil
+				}},
+			},
+			wantId: 4,
+		},
+		{
+			name: "Failed_Duplicate",
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1, hashtext($2))")).
+					WithArgs(duplicateLockClass, "i i").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, surname, COALESCE(patronymic, ''), age, gender FROM person")).
+					WithArgs("i", "i", "ivanov ivan ").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender"}).
+						AddRow(3, "Ivan", "Ivanof", "", 40, "male"))
+				mock.ExpectRollback()
+			},
+			args: args{
+				person: entity.Person{Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				check: &service.DuplicateCheck{Fuzzy: true, Resolve: func(candidates []entity.Person) (*int, error) {
+					return nil, &entity.DuplicateError{IDs: []int{candidates[0].ID}}
+				}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Failed_EmptyName",
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
-				expectedExec := "INSERT INTO person (name,surname,patronymic,age,gender) VALUES ($1,$2,$3,$4,$5) RETURNING id"
+				expectedExec := "INSERT INTO person (name,surname,patronymic,age,gender,name_original,surname_original,patronymic_original) VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id"
 				mock.ExpectQuery(regexp.QuoteMeta(expectedExec)).
 					WithArgs(
 						args.person.Name,
@@ -132,6 +210,9 @@ func Test_Create(t *testing.T) {
 						args.person.Patronymic,
 						args.person.Age,
 						args.person.Gender,
+						args.person.Name,
+						args.person.Surname,
+						args.person.Patronymic,
 					).WillReturnError(errors.New("some error"))
 
 				mock.ExpectRollback()
@@ -162,7 +243,7 @@ func Test_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior(tt.args)
 
-			gotId, err := r.Create(context.Background(), tt.args.person)
+			gotId, err := r.Create(context.Background(), tt.args.person, tt.args.check)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
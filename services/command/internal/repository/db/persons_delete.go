@@ -5,14 +5,18 @@ import (
 	"database/sql"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
 )
 
 func deleteQuery(table string, id int) (string, []interface{}, error) {
 	builder := sq.Delete(table).
 		PlaceholderFormat(sq.Dollar)
 
+	// persons merged into others stay soft-deleted
 	if table == personTable {
-		builder = builder.Where(sq.Eq{"id": id})
+		builder = builder.Where(sq.Eq{"id": id, "deleted_at": nil})
 	}
 
 	if table == nationalityTable {
@@ -32,6 +36,11 @@ func (r *DBRepo) Delete(ctx context.Context, id int) error {
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	before, err := getPerson(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
 	query, args, err := deleteQuery(nationalityTable, id)
 	if err != nil {
 		return err
@@ -47,10 +56,28 @@ func (r *DBRepo) Delete(ctx context.Context, id int) error {
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return entity.ErrPersonNotExists
+	}
+
+	if err = writeAudit(ctx, tx, id, audit.ActionDelete, audit.Diff(&before, nil)); err != nil {
+		return err
+	}
+	if err = writeVersion(ctx, tx, id, nil); err != nil {
+		return err
+	}
+	if err = writeEvent(ctx, tx, events.PersonDeleted, id, events.Payload{Person: &before}); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
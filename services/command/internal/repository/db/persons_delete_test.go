@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,7 +33,7 @@ func Test_Delete(t *testing.T) {
 		name         string
 		args         args
 		mockBehavior mockBehavior
-		wantErr      bool
+		wantErr      error
 	}{
 		{
 			name: "Success",
@@ -40,16 +43,22 @@ func Test_Delete(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
+				expectGetPerson(mock, args.id, "Ivan")
+
 				expectedPNQuery := "DELETE FROM person_nationality WHERE person_id = $1"
 				mock.ExpectExec(regexp.QuoteMeta(expectedPNQuery)).
 					WithArgs(args.id).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
-				expectedPQuery := "DELETE FROM person WHERE id = $1"
+				expectedPQuery := "DELETE FROM person WHERE deleted_at IS NULL AND id = $1"
 				mock.ExpectExec(regexp.QuoteMeta(expectedPQuery)).
 					WithArgs(args.id).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
+				expectAudit(mock, args.id, audit.ActionDelete, "")
+				expectVersion(mock, args.id, true)
+				expectEvent(mock, events.PersonDeleted, args.id)
+
 				mock.ExpectCommit()
 			},
 		},
@@ -61,19 +70,21 @@ func Test_Delete(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
+				expectGetPerson(mock, args.id, "Ivan")
+
 				expectedPNQuery := "DELETE FROM person_nationality WHERE person_id = $1"
 				mock.ExpectExec(regexp.QuoteMeta(expectedPNQuery)).
 					WithArgs(args.id).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
-				expectedQuery := "DELETE FROM person WHERE id = $1"
+				expectedQuery := "DELETE FROM person WHERE deleted_at IS NULL AND id = $1"
 				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
 					WithArgs(args.id).
 					WillReturnError(errors.New("new error"))
 
 				mock.ExpectRollback()
 			},
-			wantErr: true,
+			wantErr: errors.New("new error"),
 		},
 		{
 			name: "FailedOnPersonNationality",
@@ -83,6 +94,8 @@ func Test_Delete(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
+				expectGetPerson(mock, args.id, "Ivan")
+
 				expectedPNQuery := "DELETE FROM person_nationality WHERE person_id = $1"
 				mock.ExpectExec(regexp.QuoteMeta(expectedPNQuery)).
 					WithArgs(args.id).
@@ -90,7 +103,25 @@ func Test_Delete(t *testing.T) {
 
 				mock.ExpectRollback()
 			},
-			wantErr: true,
+			wantErr: errors.New("new error"),
+		},
+		{
+			name: "FailedMerged",
+			args: args{
+				id: 2,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				// persons merged into others are soft-deleted and not read
+				expectedQuery := "FROM person LEFT JOIN person_nationality n ON n.person_id = person.id WHERE person.deleted_at IS NULL AND person.id = $1"
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.id).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrPersonNotExists,
 		},
 	}
 
@@ -99,8 +130,8 @@ func Test_Delete(t *testing.T) {
 			tt.mockBehavior(tt.args)
 
 			err := r.Delete(context.Background(), tt.args.id)
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 			}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/service"
+)
+
+const maxDuplicateCandidates = 1000
+
+// duplicateLockClass namespaces advisory locks of full names, the lock key
+// is a hash of the name
+const duplicateLockClass = 7_305_221
+
+// fullNameColumn is the lower-cased full name fuzzy candidates are ranked by
+const fullNameColumn = "lower(surname || ' ' || name || ' ' || COALESCE(patronymic, ''))"
+
+// findDuplicatesBuilder matches the full name exactly or, for fuzzy search,
+// selects candidates sharing name and surname initials, the most similar by
+// trigrams first, for the service to rank
+func findDuplicatesBuilder(name entity.FullName, fuzzy bool) (string, []interface{}, error) {
+	builder := sq.Select("id", "name", "surname", "COALESCE(patronymic, '')", "age", "gender").
+		From(personTable).
+		Where(sq.Eq{"deleted_at": nil}).
+		Limit(maxDuplicateCandidates).
+		PlaceholderFormat(sq.Dollar)
+
+	if fuzzy {
+		builder = builder.
+			Where(sq.Expr("lower(left(name, 1)) = ?", initial(name.Name))).
+			Where(sq.Expr("lower(left(surname, 1)) = ?", initial(name.Surname))).
+			OrderByClause("similarity("+fullNameColumn+", ?) DESC, id", fullNameKey(name))
+	} else {
+		builder = builder.
+			Where(sq.Expr("lower(name) = ?", strings.ToLower(name.Name))).
+			Where(sq.Expr("lower(surname) = ?", strings.ToLower(name.Surname))).
+			Where(sq.Expr("lower(COALESCE(patronymic, '')) = ?", strings.ToLower(name.Patronymic))).
+			OrderBy("id")
+	}
+
+	return builder.ToSql()
+}
+
+func initial(s string) string {
+	r, _ := utf8.DecodeRuneInString(strings.ToLower(s))
+	return string(r)
+}
+
+func fullNameKey(name entity.FullName) string {
+	return strings.ToLower(name.Surname + " " + name.Name + " " + name.Patronymic)
+}
+
+// duplicateLockKey is the part of the full name all its duplicates share:
+// the whole name, or the initials for fuzzy search
+func duplicateLockKey(name entity.FullName, fuzzy bool) string {
+	if fuzzy {
+		return initial(name.Surname) + " " + initial(name.Name)
+	}
+	return fullNameKey(name)
+}
+
+func (r *DBRepo) FindDuplicates(ctx context.Context, name entity.FullName, fuzzy bool) ([]entity.Person, error) {
+	return findDuplicates(ctx, r.db, name, fuzzy)
+}
+
+func findDuplicates(ctx context.Context, q querier, name entity.FullName, fuzzy bool) ([]entity.Person, error) {
+	logMethod := "repository.FindDuplicates"
+
+	query, args, err := findDuplicatesBuilder(name, fuzzy)
+	logger.DebugKV(ctx, "find duplicates builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var persons []entity.Person
+	for rows.Next() {
+		var person entity.Person
+		err = rows.Scan(&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender)
+		if err != nil {
+			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+			return nil, err
+		}
+		persons = append(persons, person)
+	}
+
+	return persons, rows.Err()
+}
+
+// checkDuplicates locks the full name for the transaction, so creations of
+// the same person are serialized, and resolves its stored duplicates. It
+// returns the person to link as a possible duplicate.
+func checkDuplicates(ctx context.Context, tx *sql.Tx, person entity.Person, check *service.DuplicateCheck) (*int, error) {
+	logMethod := "repository.checkDuplicates"
+
+	name := entity.FullName{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", duplicateLockClass, duplicateLockKey(name, check.Fuzzy))
+	logger.DebugKV(ctx, "lock full name", "layer", logMethod, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := findDuplicates(ctx, tx, name, check.Fuzzy)
+	if err != nil {
+		return nil, err
+	}
+	return check.Resolve(candidates)
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindDuplicates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	type args struct {
+		name  entity.FullName
+		fuzzy bool
+	}
+
+	type mockBehavior func(args args)
+
+	tests := []struct {
+		name         string
+		args         args
+		mockBehavior mockBehavior
+		wantPersons  []entity.Person
+		wantErr      bool
+	}{
+		{
+			name: "SuccessExact",
+			args: args{name: entity.FullName{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich"}},
+			mockBehavior: func(args args) {
+				expectedQuery := `SELECT id, name, surname, COALESCE(patronymic, ''), age, gender FROM person
+					WHERE deleted_at IS NULL AND lower(name) = $1 AND lower(surname) = $2 AND lower(COALESCE(patronymic, '')) = $3 ORDER BY id LIMIT 1000`
+				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender"}).
+					AddRow(1, "Ivan", "Ivanov", "Ivanovich", 18, "male")
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("ivan", "ivanov", "ivanovich").
+					WillReturnRows(rows)
+			},
+			wantPersons: []entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich", Age: 18, Gender: "male"}},
+		},
+		{
+			name: "SuccessFuzzy",
+			args: args{name: entity.FullName{Name: "Дмитрий", Surname: "Ушаков"}, fuzzy: true},
+			mockBehavior: func(args args) {
+				expectedQuery := `SELECT id, name, surname, COALESCE(patronymic, ''), age, gender FROM person
+					WHERE deleted_at IS NULL AND lower(left(name, 1)) = $1 AND lower(left(surname, 1)) = $2
+					ORDER BY similarity(lower(surname || ' ' || name || ' ' || COALESCE(patronymic, '')), $3) DESC, id LIMIT 1000`
+				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender"})
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+					WithArgs("д", "у", "ушаков дмитрий ").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "Failed",
+			args: args{name: entity.FullName{Name: "Ivan", Surname: "Ivanov"}},
+			mockBehavior: func(args args) {
+				expectedQuery := `SELECT id, name, surname, COALESCE(patronymic, ''), age, gender FROM person`
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior(tt.args)
+
+			got, err := r.FindDuplicates(context.Background(), tt.args.name, tt.args.fuzzy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantPersons, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// getPersonBuilder selects a person with nationalities, persons merged into
+// others are soft-deleted and not selected
+func getPersonBuilder(id int) (string, []interface{}, error) {
+	builder := sq.Select("person.id", "person.name", "person.surname", "COALESCE(person.patronymic, '')", "person.age", "person.gender",
+		"COALESCE(person.name_original, person.name)", "COALESCE(person.surname_original, person.surname)",
+		"COALESCE(person.patronymic_original, person.patronymic, '')", "person.updated_at",
+		"n.nationalize", "n.probability").
+		From(personTable).
+		LeftJoin(nationalityTable + " n ON n.person_id = person.id").
+		Where(sq.Eq{"person.id": id, "person.deleted_at": nil}).
+		OrderBy("n.probability DESC").
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func (r *DBRepo) Get(ctx context.Context, id int) (entity.Person, error) {
+	return getPerson(ctx, r.db, id)
+}
+
+func getPerson(ctx context.Context, q querier, id int) (entity.Person, error) {
+	logMethod := "repository.Get"
+
+	query, args, err := getPersonBuilder(id)
+	logger.DebugKV(ctx, "get builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return entity.Person{}, err
+	}
+	defer rows.Close()
+
+	areRowsExist := false
+	person := entity.Person{Original: &entity.FullName{}}
+	for rows.Next() {
+		var nationalize sql.NullString
+		var probability sql.NullFloat64
+		err = rows.Scan(&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender,
+			&person.Original.Name, &person.Original.Surname, &person.Original.Patronymic, &person.UpdatedAt,
+			&nationalize, &probability)
+		if err != nil {
+			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+			return entity.Person{}, err
+		}
+		if nationalize.Valid {
+			person.Nationalize = append(person.Nationalize, entity.Nationality{Country: nationalize.String, Probability: probability.Float64})
+		}
+		areRowsExist = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.Person{}, err
+	}
+
+	if !areRowsExist {
+		return entity.Person{}, entity.ErrPersonNotExists
+	}
+
+	return person, nil
+}
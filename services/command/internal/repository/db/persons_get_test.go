@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	type args struct {
+		id int
+	}
+
+	type mockBehavior func(args args)
+
+	expectedQuery := `SELECT person.id, person.name, person.surname, COALESCE(person.patronymic, ''), person.age, person.gender,
+		COALESCE(person.name_original, person.name), COALESCE(person.surname_original, person.surname),
+		COALESCE(person.patronymic_original, person.patronymic, ''), person.updated_at, n.nationalize, n.probability
+		FROM person LEFT JOIN person_nationality n ON n.person_id = person.id WHERE person.deleted_at IS NULL AND person.id = $1 ORDER BY n.probability DESC`
+
+	columns := []string{"id", "name", "surname", "patronymic", "age", "gender",
+		"name_original", "surname_original", "patronymic_original", "updated_at", "nationalize", "probability"}
+	updatedAt := time.Date(2024, 1, 22, 6, 45, 18, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		args         args
+		mockBehavior mockBehavior
+		wantPerson   entity.Person
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			args: args{id: 1},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "Ivan", "Ivanov", "", 18, "male", "ivan", "Ivanov", "", updatedAt, "RU", 0.2).
+					AddRow(1, "Ivan", "Ivanov", "", 18, "male", "ivan", "Ivanov", "", updatedAt, "KZ", 0.1)
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			wantPerson: entity.Person{
+				ID:          1,
+				Name:        "Ivan",
+				Surname:     "Ivanov",
+				Age:         18,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.2}, {Country: "KZ", Probability: 0.1}},
+				Original:    &entity.FullName{Name: "ivan", Surname: "Ivanov"},
+				UpdatedAt:   updatedAt,
+			},
+		},
+		{
+			name: "SuccessWithoutNationalities",
+			args: args{id: 2},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, "Ivan", "Ivanov", "Ivanovich", 18, "male", "Ivan", "Ivanov", "Ivanovich", updatedAt, nil, nil)
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			wantPerson: entity.Person{
+				ID:         2,
+				Name:       "Ivan",
+				Surname:    "Ivanov",
+				Patronymic: "Ivanovich",
+				Age:        18,
+				Gender:     "male",
+				Original:   &entity.FullName{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich"},
+				UpdatedAt:  updatedAt,
+			},
+		},
+		{
+			name: "FailedNotExists",
+			args: args{id: 3},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(columns)
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			wantErr: entity.ErrPersonNotExists,
+		},
+		{
+			name: "Failed",
+			args: args{id: 4},
+			mockBehavior: func(args args) {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnError(errors.New("some error"))
+			},
+			wantErr: errors.New("some error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior(tt.args)
+
+			got, err := r.Get(context.Background(), tt.args.id)
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantPerson, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectGetPerson expects person to be read inside transaction
+func expectGetPerson(mock sqlmock.Sqlmock, id int, name string) {
+	expectedQuery := "SELECT person.id, person.name, person.surname, COALESCE(person.patronymic, ''), person.age, person.gender, " +
+		"COALESCE(person.name_original, person.name), COALESCE(person.surname_original, person.surname), " +
+		"COALESCE(person.patronymic_original, person.patronymic, ''), person.updated_at, n.nationalize, n.probability " +
+		"FROM person LEFT JOIN person_nationality n ON n.person_id = person.id WHERE person.deleted_at IS NULL AND person.id = $1 ORDER BY n.probability DESC"
+
+	rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender",
+		"name_original", "surname_original", "patronymic_original", "updated_at", "nationalize", "probability"}).
+		AddRow(id, name, "Ivanov", "", 18, "male", name, "Ivanov", "", time.Now(), "RU", 0.9)
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(id).WillReturnRows(rows)
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/service"
+)
+
+func lockPersonsBuilder(ids ...int) (string, []interface{}, error) {
+	builder := sq.Select("id").
+		From(personTable).
+		Where(sq.Eq{"id": ids, "deleted_at": nil}).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// lockPersons locks rows of the persons until the transaction ends, so they
+// aren't changed between reading and updating them. It fails with
+// entity.ErrPersonNotExists if any of them is missing or merged.
+func lockPersons(ctx context.Context, tx *sql.Tx, ids ...int) error {
+	logMethod := "repository.lockPersons"
+
+	query, args, err := lockPersonsBuilder(ids...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	var locked int
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	logger.DebugKV(ctx, "lock persons", "layer", logMethod, "locked", locked, "err", rows.Err())
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if locked != len(ids) {
+		return entity.ErrPersonNotExists
+	}
+	return nil
+}
+
+func mergeTargetBuilder(person entity.Person) (string, []interface{}, error) {
+	builder := sq.Update(personTable).
+		Set("name", person.Name).
+		Set("surname", person.Surname).
+		Set("patronymic", person.Patronymic).
+		Set("age", person.Age).
+		Set("gender", person.Gender).
+		Set("name_original", person.Original.Name).
+		Set("surname_original", person.Original.Surname).
+		Set("patronymic_original", person.Original.Patronymic).
+		Where(sq.Eq{"id": person.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func mergeSourceBuilder(targetID, sourceID int) (string, []interface{}, error) {
+	builder := sq.Update(personTable).
+		Set("merged_into", targetID).
+		Set("deleted_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": sourceID}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// redirectMergedBuilder points persons merged into the source earlier
+// straight to the new survivor
+func redirectMergedBuilder(targetID, sourceID int) (string, []interface{}, error) {
+	builder := sq.Update(personTable).
+		Set("merged_into", targetID).
+		Where(sq.Eq{"merged_into": sourceID}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func (r *DBRepo) Merge(ctx context.Context, targetID int, params *service.MergeParams) (entity.Person, error) {
+	logMethod := "repository.Merge"
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
+	if err != nil {
+		return entity.Person{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = lockPersons(ctx, tx, targetID, params.SourceID); err != nil {
+		return entity.Person{}, err
+	}
+
+	target, err := getPerson(ctx, tx, targetID)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	source, err := getPerson(ctx, tx, params.SourceID)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	merged := params.Resolve(target, source)
+	logger.DebugKV(ctx, "resolved merge", "layer", logMethod, "person", merged)
+
+	builders := []func() (string, []interface{}, error){
+		func() (string, []interface{}, error) { return mergeTargetBuilder(merged) },
+		func() (string, []interface{}, error) { return deleteQuery(nationalityTable, targetID) },
+		func() (string, []interface{}, error) { return deleteQuery(nationalityTable, params.SourceID) },
+		func() (string, []interface{}, error) { return mergeSourceBuilder(targetID, params.SourceID) },
+		func() (string, []interface{}, error) { return redirectMergedBuilder(targetID, params.SourceID) },
+	}
+	if len(merged.Nationalize) > 0 {
+		builders = append(builders, func() (string, []interface{}, error) { return createNationalizeBuilder(merged) })
+	}
+
+	for _, builder := range builders {
+		var query string
+		var args []interface{}
+		query, args, err = builder()
+		logger.DebugKV(ctx, "merge builder", "layer", logMethod, "query", query, "args", args, "err", err)
+		if err != nil {
+			return entity.Person{}, err
+		}
+
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return entity.Person{}, err
+		}
+	}
+
+	changes := audit.Diff(&target, &merged)
+	changes["merged_from"] = audit.Change{After: params.SourceID}
+	if err = writeAudit(ctx, tx, targetID, audit.ActionMerge, changes); err != nil {
+		return entity.Person{}, err
+	}
+	if err = writeVersion(ctx, tx, targetID, &merged); err != nil {
+		return entity.Person{}, err
+	}
+	if err = writeEvent(ctx, tx, events.PersonUpdated, targetID, events.Payload{Person: &merged, Changes: changes}); err != nil {
+		return entity.Person{}, err
+	}
+
+	changes = audit.Changes{"merged_into": {After: targetID}}
+	if err = writeAudit(ctx, tx, params.SourceID, audit.ActionMerge, changes); err != nil {
+		return entity.Person{}, err
+	}
+	if err = writeVersion(ctx, tx, params.SourceID, nil); err != nil {
+		return entity.Person{}, err
+	}
+	if err = writeEvent(ctx, tx, events.PersonDeleted, params.SourceID, events.Payload{Person: &source, MergedInto: &targetID}); err != nil {
+		return entity.Person{}, err
+	}
+
+	return merged, tx.Commit()
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Merge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	type args struct {
+		targetID int
+		params   *service.MergeParams
+	}
+
+	type mockBehavior func(args args)
+
+	lockQuery := "SELECT id FROM person WHERE deleted_at IS NULL AND id IN ($1,$2) ORDER BY id FOR UPDATE"
+	getQuery := "SELECT person.id, person.name, person.surname"
+	columns := []string{"id", "name", "surname", "patronymic", "age", "gender",
+		"name_original", "surname_original", "patronymic_original", "updated_at", "nationalize", "probability"}
+	older := time.Date(2024, 1, 22, 6, 45, 18, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		args         args
+		mockBehavior mockBehavior
+		wantPerson   entity.Person
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			args: args{
+				targetID: 1,
+				params: &service.MergeParams{
+					SourceID:   2,
+					Name:       service.MergeKeepTarget,
+					Surname:    service.MergeKeepSource,
+					Patronymic: service.MergeKeepTarget,
+					Age:        service.MergeNewest,
+					Gender:     service.MergeKeepTarget,
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+				mock.ExpectQuery(regexp.QuoteMeta(getQuery)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, "Ivan", "Ivanov", "", 30, "male", "Ivan", "Ivanov", "", older, "RU", 0.2).
+						AddRow(1, "Ivan", "Ivanov", "", 30, "male", "Ivan", "Ivanov", "", older, "KZ", 0.1))
+				mock.ExpectQuery(regexp.QuoteMeta(getQuery)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(2, "Ivan", "Ivanoff", "", 31, "male", "ivan", "ivanoff", "", newer, "RU", 0.3))
+
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE person SET name = $1, surname = $2, patronymic = $3, age = $4, gender = $5, name_original = $6, surname_original = $7, patronymic_original = $8 WHERE id = $9")).
+					WithArgs("Ivan", "Ivanoff", "", 31, "male", "Ivan", "ivanoff", "", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM person_nationality WHERE person_id = $1")).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM person_nationality WHERE person_id = $1")).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE person SET merged_into = $1, deleted_at = now() WHERE id = $2")).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE person SET merged_into = $1 WHERE merged_into = $2")).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO person_nationality (person_id,nationalize,probability) VALUES ($1,$2,$3),($4,$5,$6)")).
+					WithArgs(1, "RU", 0.3, 1, "KZ", 0.1).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				expectAudit(mock, 1, audit.ActionMerge,
+					`{"age":{"before":30,"after":31},"merged_from":{"before":null,"after":2},"nationalize":{"before":[{"country_id":"RU","probability":0.2},{"country_id":"KZ","probability":0.1}],"after":[{"country_id":"RU","probability":0.3},{"country_id":"KZ","probability":0.1}]},"surname":{"before":"Ivanov","after":"Ivanoff"}}`)
+				expectVersion(mock, 1, false)
+				expectEvent(mock, events.PersonUpdated, 1)
+				expectAudit(mock, 2, audit.ActionMerge, `{"merged_into":{"before":null,"after":1}}`)
+				expectVersion(mock, 2, true)
+				expectEvent(mock, events.PersonDeleted, 2)
+				mock.ExpectCommit()
+			},
+			wantPerson: entity.Person{
+				ID:          1,
+				Name:        "Ivan",
+				Surname:     "Ivanoff",
+				Age:         31,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.3}, {Country: "KZ", Probability: 0.1}},
+				Original:    &entity.FullName{Name: "Ivan", Surname: "ivanoff"},
+				UpdatedAt:   older,
+			},
+		},
+		{
+			name: "FailedNotExists",
+			args: args{
+				targetID: 1,
+				params:   &service.MergeParams{SourceID: 3},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(lockQuery)).
+					WithArgs(1, 3).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrPersonNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior(tt.args)
+
+			got, err := r.Merge(context.Background(), tt.args.targetID, tt.args.params)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantPerson, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
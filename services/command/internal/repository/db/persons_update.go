@@ -3,16 +3,18 @@ package db
 import (
 	"context"
 	"database/sql"
-	"errors"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
 	"github.com/pintoter/persons/services/command/internal/service"
 )
 
 func updateBuilder(id int, data *service.UpdateParams) (string, []interface{}, error) {
 	builder := sq.Update(personTable).
-		Where(sq.Eq{"id": id}).
+		Where(sq.Eq{"id": id, "deleted_at": nil}).
 		PlaceholderFormat(sq.Dollar)
 	if data.Name != nil {
 		builder = builder.Set("name", *data.Name)
@@ -23,10 +25,33 @@ func updateBuilder(id int, data *service.UpdateParams) (string, []interface{}, e
 	if data.Patronymic != nil {
 		builder = builder.Set("patronymic", *data.Patronymic)
 	}
+	if data.NameOriginal != nil {
+		builder = builder.Set("name_original", *data.NameOriginal)
+	}
+	if data.SurnameOriginal != nil {
+		builder = builder.Set("surname_original", *data.SurnameOriginal)
+	}
+	if data.PatronymicOriginal != nil {
+		builder = builder.Set("patronymic_original", *data.PatronymicOriginal)
+	}
+	if data.Age != nil {
+		builder = builder.Set("age", *data.Age)
+	}
+	if data.Gender != nil {
+		builder = builder.Set("gender", *data.Gender)
+	}
+	if data.IfName != nil {
+		builder = builder.Where(sq.Eq{"name": *data.IfName})
+	}
+	if !data.HasColumns() {
+		// only nationalities are replaced, touch the row to check it exists
+		builder = builder.Set("updated_at", sq.Expr("now()"))
+	}
 	return builder.ToSql()
 }
 
-func (r *DBRepo) Update(ctx context.Context, id int, params *service.UpdateParams) error {
+// Update changes person and returns its updated representation
+func (r *DBRepo) Update(ctx context.Context, id int, params *service.UpdateParams) (entity.Person, error) {
 	logMethod := "repository.Update"
 	logger.DebugKV(ctx, "update start", "layer", logMethod, "params", params)
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
@@ -34,24 +59,96 @@ func (r *DBRepo) Update(ctx context.Context, id int, params *service.UpdateParam
 		ReadOnly:  false,
 	})
 	if err != nil {
-		return err
+		return entity.Person{}, err
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	if err = lockPersons(ctx, tx, id); err != nil {
+		return entity.Person{}, err
+	}
+
+	before, err := getPerson(ctx, tx, id)
+	if err != nil {
+		return entity.Person{}, err
+	}
+	if params.IfName != nil && before.Name != *params.IfName {
+		return entity.Person{}, entity.ErrNameChanged
+	}
+
 	query, args, err := updateBuilder(id, params)
 	logger.DebugKV(ctx, "update builder", "layer", logMethod, "query", query, "err", err)
 	if err != nil {
-		return err
+		return entity.Person{}, err
 	}
 
 	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return entity.Person{}, err
+	}
+	if rows == 0 {
+		return entity.Person{}, entity.ErrPersonNotExists
+	}
+
+	if params.Nationalize != nil {
+		if err = replaceNationalities(ctx, tx, id, params.Nationalize); err != nil {
+			return entity.Person{}, err
+		}
+	}
+
+	person, err := getPerson(ctx, tx, id)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	if changes := audit.Diff(&before, &person); len(changes) > 0 {
+		if err = writeAudit(ctx, tx, id, audit.ActionUpdate, changes); err != nil {
+			return entity.Person{}, err
+		}
+		if err = writeVersion(ctx, tx, id, &person); err != nil {
+			return entity.Person{}, err
+		}
+		eventType := events.PersonUpdated
+		if audit.ActionFrom(ctx, audit.ActionUpdate) == audit.ActionEnrich {
+			eventType = events.PersonEnriched
+		}
+		if err = writeEvent(ctx, tx, eventType, id, events.Payload{Person: &person, Changes: changes}); err != nil {
+			return entity.Person{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return entity.Person{}, err
+	}
+
+	return person, nil
+}
+
+func replaceNationalities(ctx context.Context, tx *sql.Tx, id int, nationalities []entity.Nationality) error {
+	logMethod := "repository.replaceNationalities"
+
+	query, args, err := deleteQuery(nationalityTable, id)
+	logger.DebugKV(ctx, "delete nationalities builder", "layer", logMethod, "query", query, "err", err)
 	if err != nil {
 		return err
 	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
 
-	if rows, err := result.RowsAffected(); err != nil || rows == 0 {
-		return errors.New("no rows affected")
+	if len(nationalities) == 0 {
+		return nil
 	}
 
-	return tx.Commit()
+	query, args, err = createNationalizeBuilder(entity.Person{ID: id, Nationalize: nationalities})
+	logger.DebugKV(ctx, "create nationalities builder", "layer", logMethod, "query", query, "err", err)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
 }
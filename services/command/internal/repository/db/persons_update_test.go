@@ -6,8 +6,12 @@ import (
 	"log"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
 	"github.com/pintoter/persons/services/command/internal/service"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,8 +35,9 @@ func Test_Update(t *testing.T) {
 	tests := []struct {
 		name         string
 		args         args
+		action       audit.Action
 		mockBehavior mockBehavior
-		wantErr      bool
+		wantErr      error
 	}{
 		{
 			name: "Success",
@@ -45,14 +50,230 @@ func Test_Update(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
-				expectedQuery := "UPDATE person SET name = $1 WHERE id = $2"
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Ivan")
+
+				expectedQuery := "UPDATE person SET name = $1 WHERE deleted_at IS NULL AND id = $2"
 				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
 					WithArgs(args.params.Name, args.id).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectAudit(mock, args.id, audit.ActionUpdate, `{"name":{"before":"Ivan","after":"Vlad"}}`)
+				expectVersion(mock, args.id, false)
+				expectEvent(mock, events.PersonUpdated, args.id)
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "SuccessEnriched",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Age:    GetAddress[int](40),
+					IfName: GetAddress[string]("Vlad"),
+				},
+			},
+			action: audit.ActionEnrich,
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET age = $1 WHERE deleted_at IS NULL AND id = $2 AND name = $3"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.params.Age, args.id, args.params.IfName).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectQuery("SELECT (.+) FROM person").
+					WithArgs(args.id).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender",
+						"name_original", "surname_original", "patronymic_original", "updated_at", "nationalize", "probability"}).
+						AddRow(args.id, "Vlad", "Ivanov", "", 40, "male", "Vlad", "Ivanov", "", time.Now(), "RU", 0.9))
+
+				expectAudit(mock, args.id, audit.ActionEnrich, "")
+				expectVersion(mock, args.id, false)
+				expectEvent(mock, events.PersonEnriched, args.id)
+
 				mock.ExpectCommit()
 			},
-			wantErr: false,
+		},
+		{
+			name: "SuccessWithOriginal",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Surname:         GetAddress[string]("O'Neil"),
+					SurnameOriginal: GetAddress[string]("o'neil"),
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET surname = $1, surname_original = $2 WHERE deleted_at IS NULL AND id = $3"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.params.Surname, args.params.SurnameOriginal, args.id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				expectGetPerson(mock, args.id, "Vlad")
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "SuccessWithDerivedFields",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Name:        GetAddress[string]("Petr"),
+					Age:         GetAddress[int](40),
+					Gender:      GetAddress[string]("male"),
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}},
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET name = $1, age = $2, gender = $3 WHERE deleted_at IS NULL AND id = $4"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.params.Name, args.params.Age, args.params.Gender, args.id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				expectedPNQuery := "DELETE FROM person_nationality WHERE person_id = $1"
+				mock.ExpectExec(regexp.QuoteMeta(expectedPNQuery)).
+					WithArgs(args.id).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				expectedNQuery := "INSERT INTO person_nationality (person_id,nationalize,probability) VALUES ($1,$2,$3)"
+				mock.ExpectExec(regexp.QuoteMeta(expectedNQuery)).
+					WithArgs(args.id, "RU", 0.9).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				expectGetPerson(mock, args.id, "Vlad")
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "SuccessWithNationalitiesOnly",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Nationalize: []entity.Nationality{},
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET updated_at = now() WHERE deleted_at IS NULL AND id = $1"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				expectedPNQuery := "DELETE FROM person_nationality WHERE person_id = $1"
+				mock.ExpectExec(regexp.QuoteMeta(expectedPNQuery)).
+					WithArgs(args.id).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+
+				expectGetPerson(mock, args.id, "Vlad")
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "FailedNameChangedMeanwhile",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Age:    GetAddress[int](40),
+					IfName: GetAddress[string]("Petr"),
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrNameChanged,
+		},
+		{
+			name: "FailedDeletedBeforeUpdate",
+			args: args{
+				id: 1,
+				params: &service.UpdateParams{
+					Age:    GetAddress[int](40),
+					IfName: GetAddress[string]("Vlad"),
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET age = $1 WHERE deleted_at IS NULL AND id = $2 AND name = $3"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.params.Age, args.id, args.params.IfName).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrPersonNotExists,
+		},
+		{
+			name: "FailedNotExists",
+			args: args{
+				id: 100,
+				params: &service.UpdateParams{
+					Name: GetAddress[string]("Vlad"),
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET name = $1 WHERE deleted_at IS NULL AND id = $2"
+				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
+					WithArgs(args.params.Name, args.id).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrPersonNotExists,
+		},
+		{
+			name: "FailedNotFound",
+			args: args{
+				id: 100,
+				params: &service.UpdateParams{
+					Name: GetAddress[string]("Vlad"),
+				},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				expectLockPerson(mock, args.id, false)
+
+				mock.ExpectRollback()
+			},
+			wantErr: entity.ErrPersonNotExists,
 		},
 		{
 			name: "Failed",
@@ -65,24 +286,32 @@ func Test_Update(t *testing.T) {
 			mockBehavior: func(args args) {
 				mock.ExpectBegin()
 
-				expectedQuery := "UPDATE person SET surname = $1 WHERE id = $2"
+				expectLockPerson(mock, args.id, true)
+				expectGetPerson(mock, args.id, "Vlad")
+
+				expectedQuery := "UPDATE person SET surname = $1 WHERE deleted_at IS NULL AND id = $2"
 				mock.ExpectExec(regexp.QuoteMeta(expectedQuery)).
 					WithArgs(args.params.Surname, args.id).
 					WillReturnError(errors.New("some error"))
 
 				mock.ExpectRollback()
 			},
-			wantErr: true,
+			wantErr: errors.New("some error"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockBehavior(tt.args)
-			err := r.Update(context.Background(), tt.args.id, tt.args.params)
 
-			if tt.wantErr {
-				assert.Error(t, err)
+			ctx := context.Background()
+			if tt.action != "" {
+				ctx = audit.WithAction(ctx, tt.action)
+			}
+			_, err := r.Update(ctx, tt.args.id, tt.args.params)
+
+			if tt.wantErr != nil {
+				assert.EqualError(t, err, tt.wantErr.Error())
 			} else {
 				assert.NoError(t, err)
 			}
@@ -90,3 +319,13 @@ func Test_Update(t *testing.T) {
 		})
 	}
 }
+
+// expectLockPerson expects person row to be locked before it's read
+func expectLockPerson(mock sqlmock.Sqlmock, id int, exists bool) {
+	expectedQuery := "SELECT id FROM person WHERE deleted_at IS NULL AND id IN ($1) ORDER BY id FOR UPDATE"
+	rows := sqlmock.NewRows([]string{"id"})
+	if exists {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(id).WillReturnRows(rows)
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/pkg/phonetic"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+func closeVersionBuilder(id int) (string, []interface{}, error) {
+	builder := sq.Update(versionTable).
+		Set("valid_to", sq.Expr("now()")).
+		Where(sq.Eq{"person_id": id, "valid_to": nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func insertVersionBuilder(person entity.Person) (string, []interface{}, error) {
+	nationalize := person.Nationalize
+	if nationalize == nil {
+		nationalize = []entity.Nationality{}
+	}
+	data, err := json.Marshal(nationalize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys, err := json.Marshal(phoneticKeys(person))
+	if err != nil {
+		return "", nil, err
+	}
+
+	builder := sq.Insert(versionTable).
+		Columns("person_id", "name", "surname", "patronymic", "age", "gender", "nationalize", "phonetic").
+		Values(person.ID, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, data, keys).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// phoneticKeys returns phonetic keys of the person name and surname, they're
+// computed once on write for the read side to index
+func phoneticKeys(person entity.Person) []string {
+	return phonetic.Keys(person.Name + " " + person.Surname)
+}
+
+// writeVersion closes the current snapshot of person in tx and, unless
+// person is deleted (nil), opens a new one valid from now on
+func writeVersion(ctx context.Context, tx *sql.Tx, id int, person *entity.Person) error {
+	logMethod := "repository.writeVersion"
+
+	builders := []func() (string, []interface{}, error){
+		func() (string, []interface{}, error) { return closeVersionBuilder(id) },
+	}
+	if person != nil {
+		builders = append(builders, func() (string, []interface{}, error) { return insertVersionBuilder(*person) })
+	}
+
+	for _, builder := range builders {
+		query, args, err := builder()
+		logger.DebugKV(ctx, "version builder", "layer", logMethod, "query", query, "err", err)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_insertVersionBuilder(t *testing.T) {
+	tests := []struct {
+		name     string
+		person   entity.Person
+		wantArgs []interface{}
+	}{
+		{
+			name: "WithNationalities",
+			person: entity.Person{
+				ID:          1,
+				Name:        "Ivan",
+				Surname:     "Ivanov",
+				Age:         18,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.2}},
+			},
+			wantArgs: []interface{}{1, "Ivan", "Ivanov", "", 18, "male", []byte(`[{"country_id":"RU","probability":0.2}]`),
+				[]byte(`["AFN","AFNF"]`)},
+		},
+		{
+			name:     "WithoutNationalities",
+			person:   entity.Person{ID: 2, Name: "Иван", Surname: "Иванов", Patronymic: "Иванович", Age: 18, Gender: "male"},
+			wantArgs: []interface{}{2, "Иван", "Иванов", "Иванович", 18, "male", []byte(`[]`), []byte(`["иван","иван4"]`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := insertVersionBuilder(tt.person)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "INSERT INTO person_version (person_id,name,surname,patronymic,age,gender,nationalize,phonetic) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)", query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+// expectVersion expects the current snapshot of person to be closed and,
+// unless deleted, a new one to be written
+func expectVersion(mock sqlmock.Sqlmock, id int, deleted bool) {
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE person_version SET valid_to = now() WHERE person_id = $1 AND valid_to IS NULL")).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if !deleted {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO person_version (person_id,name,surname,patronymic,age,gender,nationalize,phonetic) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)")).
+			WithArgs(id, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
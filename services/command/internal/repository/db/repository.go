@@ -7,6 +7,12 @@ import (
 const (
 	personTable      = "person"
 	nationalityTable = "person_nationality"
+	auditTable       = "person_audit"
+	versionTable     = "person_version"
+	outboxTable      = "outbox"
+	webhookTable     = "webhook_subscription"
+	deliveryTable    = "webhook_delivery"
+	attemptTable     = "webhook_attempt"
 )
 
 type DBRepo struct {
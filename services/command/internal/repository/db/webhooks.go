@@ -0,0 +1,470 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/webhooks"
+)
+
+func createWebhookBuilder(webhook entity.Webhook) (string, []interface{}, error) {
+	builder := sq.Insert(webhookTable).
+		Columns("url", "secret", "event_types").
+		Values(webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes)).
+		Suffix("RETURNING id, created_at").
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func (r *DBRepo) CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	logMethod := "repository.CreateWebhook"
+
+	query, args, err := createWebhookBuilder(webhook)
+	logger.DebugKV(ctx, "create webhook builder", "layer", logMethod, "query", query, "err", err)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (r *DBRepo) GetWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	query, args, err := sq.Select("id", "url", "event_types", "created_at").
+		From(webhookTable).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []entity.Webhook{}
+	for rows.Next() {
+		var webhook entity.Webhook
+		var eventTypes pq.StringArray
+		if err = rows.Scan(&webhook.ID, &webhook.URL, &eventTypes, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhook.EventTypes = eventTypes
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook removes the subscription together with its deliveries
+func (r *DBRepo) DeleteWebhook(ctx context.Context, id int) error {
+	query, args, err := sq.Delete(webhookTable).
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return entity.ErrWebhookNotExists
+	}
+	return nil
+}
+
+func selectDeliveries() sq.SelectBuilder {
+	return sq.Select("d.id", "d.subscription_id", "d.event_id", "o.event_type", "d.status", "d.attempts", "d.next_attempt_at",
+		"d.last_status_code", "COALESCE(d.last_error, '')", "d.delivered_at", "d.created_at").
+		From(deliveryTable + " d").
+		Join(outboxTable + " o ON o.id = d.event_id").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func getDeliveriesBuilder(subscriptionID int, status string, limit, offset int64) (string, []interface{}, error) {
+	builder := selectDeliveries().
+		Where(sq.Eq{"d.subscription_id": subscriptionID})
+
+	if status != "" {
+		builder = builder.Where(sq.Eq{"d.status": status})
+	}
+
+	return builder.
+		OrderBy("d.id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row rowScanner) (entity.Delivery, error) {
+	var delivery entity.Delivery
+	var nextAttemptAt time.Time
+	var lastStatusCode sql.NullInt64
+	var deliveredAt sql.NullTime
+	err := row.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Status,
+		&delivery.Attempts, &nextAttemptAt, &lastStatusCode, &delivery.LastError, &deliveredAt, &delivery.CreatedAt)
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	if delivery.Status == entity.DeliveryPending {
+		delivery.NextAttemptAt = &nextAttemptAt
+	}
+	if lastStatusCode.Valid {
+		code := int(lastStatusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
+
+// GetDeliveries returns deliveries of the subscription, the newest first
+func (r *DBRepo) GetDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int64) ([]entity.Delivery, error) {
+	logMethod := "repository.GetDeliveries"
+
+	if err := r.webhookExists(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	query, args, err := getDeliveriesBuilder(subscriptionID, status, limit, offset)
+	logger.DebugKV(ctx, "get deliveries builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []entity.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *DBRepo) webhookExists(ctx context.Context, id int) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+webhookTable+" WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return entity.ErrWebhookNotExists
+	}
+	return nil
+}
+
+// GetDelivery returns delivery with the log of its attempts
+func (r *DBRepo) GetDelivery(ctx context.Context, subscriptionID int, id int64) (entity.Delivery, error) {
+	query, args, err := selectDeliveries().
+		Where(sq.Eq{"d.id": id, "d.subscription_id": subscriptionID}).
+		ToSql()
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	delivery, err := scanDelivery(r.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Delivery{}, entity.ErrDeliveryNotExists
+	}
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	query, args, err = sq.Select("status_code", "COALESCE(error, '')", "duration_ms", "created_at").
+		From(attemptTable).
+		Where(sq.Eq{"delivery_id": id}).
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt entity.DeliveryAttempt
+		var statusCode sql.NullInt64
+		if err = rows.Scan(&statusCode, &attempt.Error, &attempt.DurationMs, &attempt.CreatedAt); err != nil {
+			return entity.Delivery{}, err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			attempt.StatusCode = &code
+		}
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	return delivery, rows.Err()
+}
+
+// Redeliver queues the delivery again with a fresh set of attempts
+func (r *DBRepo) Redeliver(ctx context.Context, subscriptionID int, id int64) error {
+	query, args, err := sq.Update(deliveryTable).
+		Set("status", entity.DeliveryPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", sq.Expr("now()")).
+		Set("delivered_at", nil).
+		Set("lease_token", nil).
+		Where(sq.Eq{"id": id, "subscription_id": subscriptionID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return entity.ErrDeliveryNotExists
+	}
+	return nil
+}
+
+func dueDeliveriesBuilder(limit int) (string, []interface{}, error) {
+	builder := sq.Select("d.id", "d.attempts", "s.url", "s.secret",
+		"o.id", "o.event_type", "o.aggregate_id", "o.payload", "o.created_at").
+		From(deliveryTable+" d").
+		Join(webhookTable+" s ON s.id = d.subscription_id").
+		Join(outboxTable+" o ON o.id = d.event_id").
+		Where(sq.Eq{"d.status": entity.DeliveryPending}).
+		Where("d.next_attempt_at <= now()").
+		OrderBy("d.next_attempt_at", "d.id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF d SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func recordOutcomeBuilder(outcome webhooks.Outcome) (string, []interface{}, error) {
+	var statusCode, lastError any
+	if outcome.StatusCode != 0 {
+		statusCode = outcome.StatusCode
+	}
+	if outcome.Error != "" {
+		lastError = outcome.Error
+	}
+
+	builder := sq.Update(deliveryTable).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_status_code", statusCode).
+		Set("last_error", lastError).
+		Where(sq.Eq{"id": outcome.DeliveryID, "lease_token": outcome.LeaseToken}).
+		PlaceholderFormat(sq.Dollar)
+
+	switch {
+	case outcome.Delivered:
+		builder = builder.Set("status", entity.DeliveryDelivered).Set("delivered_at", sq.Expr("now()"))
+	case outcome.NextAttemptAt.IsZero():
+		builder = builder.Set("status", entity.DeliveryDead)
+	default:
+		builder = builder.Set("next_attempt_at", outcome.NextAttemptAt)
+	}
+
+	return builder.ToSql()
+}
+
+func insertAttemptBuilder(outcome webhooks.Outcome) (string, []interface{}, error) {
+	var statusCode, attemptError any
+	if outcome.StatusCode != 0 {
+		statusCode = outcome.StatusCode
+	}
+	if outcome.Error != "" {
+		attemptError = outcome.Error
+	}
+
+	builder := sq.Insert(attemptTable).
+		Columns("delivery_id", "status_code", "error", "duration_ms").
+		Values(outcome.DeliveryID, statusCode, attemptError, outcome.Duration.Milliseconds()).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// leaseTokenSize is the number of random bytes in a lease token
+const leaseTokenSize = 16
+
+func leaseDeliveriesBuilder(ids []int64, lease time.Duration, token string) (string, []interface{}, error) {
+	builder := sq.Update(deliveryTable).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Set("lease_token", token).
+		Where(sq.Eq{"id": ids}).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// ClaimDeliveries locks due deliveries, so concurrent dispatchers skip them,
+// and postpones their next attempt by lease. If outcomes aren't recorded
+// before the lease expires, the deliveries become due again and the next
+// claim replaces their lease token.
+func (r *DBRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhooks.Job, error) {
+	logMethod := "repository.ClaimDeliveries"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	jobs, err := dueDeliveries(ctx, tx, limit)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	random := make([]byte, leaseTokenSize)
+	if _, err = rand.Read(random); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(random)
+
+	ids := make([]int64, len(jobs))
+	for i := range jobs {
+		ids[i] = jobs[i].DeliveryID
+		jobs[i].LeaseToken = token
+	}
+
+	query, args, err := leaseDeliveriesBuilder(ids, lease, token)
+	logger.DebugKV(ctx, "lease deliveries builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// RecordOutcomes stores attempts and updates the deliveries by their outcomes.
+// An outcome whose lease token no longer matches is skipped: the delivery was
+// claimed again or redelivered meanwhile.
+func (r *DBRepo) RecordOutcomes(ctx context.Context, outcomes []webhooks.Outcome) error {
+	logMethod := "repository.RecordOutcomes"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, outcome := range outcomes {
+		query, args, err := recordOutcomeBuilder(outcome)
+		logger.DebugKV(ctx, "record outcome builder", "layer", logMethod, "query", query, "args", args, "err", err)
+		if err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			logger.DebugKV(ctx, "lease lost, outcome skipped", "layer", logMethod, "delivery_id", outcome.DeliveryID)
+			continue
+		}
+
+		query, args, err = insertAttemptBuilder(outcome)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func dueDeliveries(ctx context.Context, tx *sql.Tx, limit int) ([]webhooks.Job, error) {
+	query, args, err := dueDeliveriesBuilder(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []webhooks.Job
+	for rows.Next() {
+		var job webhooks.Job
+		var payload []byte
+		err = rows.Scan(&job.DeliveryID, &job.Attempts, &job.URL, &job.Secret,
+			&job.Event.ID, &job.Event.Type, &job.Event.PersonID, &payload, &job.Event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		job.Event.Payload = payload
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_recordOutcomeBuilder(t *testing.T) {
+	nextAttemptAt := time.Date(2026, 10, 19, 12, 0, 10, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		outcome   webhooks.Outcome
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "Delivered",
+			outcome:   webhooks.Outcome{DeliveryID: 1, LeaseToken: "token", Delivered: true, StatusCode: 204},
+			wantQuery: "UPDATE webhook_delivery SET attempts = attempts + 1, last_status_code = $1, last_error = $2, status = $3, delivered_at = now() WHERE id = $4 AND lease_token = $5",
+			wantArgs:  []interface{}{204, nil, entity.DeliveryDelivered, int64(1), "token"},
+		},
+		{
+			name:      "Retry",
+			outcome:   webhooks.Outcome{DeliveryID: 2, LeaseToken: "token", StatusCode: 500, Error: "unexpected status 500", NextAttemptAt: nextAttemptAt},
+			wantQuery: "UPDATE webhook_delivery SET attempts = attempts + 1, last_status_code = $1, last_error = $2, next_attempt_at = $3 WHERE id = $4 AND lease_token = $5",
+			wantArgs:  []interface{}{500, "unexpected status 500", nextAttemptAt, int64(2), "token"},
+		},
+		{
+			name:      "Dead",
+			outcome:   webhooks.Outcome{DeliveryID: 3, LeaseToken: "token", Error: "connection refused"},
+			wantQuery: "UPDATE webhook_delivery SET attempts = attempts + 1, last_status_code = $1, last_error = $2, status = $3 WHERE id = $4 AND lease_token = $5",
+			wantArgs:  []interface{}{nil, "connection refused", entity.DeliveryDead, int64(3), "token"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args, err := recordOutcomeBuilder(tc.outcome)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, query)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func Test_ClaimDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	selectQuery := "SELECT d.id, d.attempts, s.url, s.secret, o.id, o.event_type, o.aggregate_id, o.payload, o.created_at " +
+		"FROM webhook_delivery d JOIN webhook_subscription s ON s.id = d.subscription_id JOIN outbox o ON o.id = d.event_id " +
+		"WHERE d.status = $1 AND d.next_attempt_at <= now() ORDER BY d.next_attempt_at, d.id LIMIT 10 FOR UPDATE OF d SKIP LOCKED"
+	leaseQuery := "UPDATE webhook_delivery SET next_attempt_at = now() + make_interval(secs => $1), lease_token = $2 WHERE id IN ($3)"
+	columns := []string{"id", "attempts", "url", "secret", "id", "event_type", "aggregate_id", "payload", "created_at"}
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	job := webhooks.Job{
+		DeliveryID: 4,
+		Attempts:   1,
+		URL:        "http://localhost:9090/hook",
+		Secret:     "secret",
+		Event:      events.Event{ID: 7, Type: events.PersonCreated, PersonID: 5, Payload: []byte(`{"person":{"id":5}}`), OccurredAt: createdAt},
+	}
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		want         []webhooks.Job
+		wantErr      bool
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).WithArgs(entity.DeliveryPending).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(job.DeliveryID, job.Attempts, job.URL, job.Secret,
+						job.Event.ID, job.Event.Type, job.Event.PersonID, []byte(job.Event.Payload), job.Event.OccurredAt))
+				mock.ExpectExec(regexp.QuoteMeta(leaseQuery)).
+					WithArgs(float64(10), sqlmock.AnyArg(), int64(4)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: []webhooks.Job{job},
+		},
+		{
+			name: "NothingDue",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).WithArgs(entity.DeliveryPending).
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name: "FailedLease",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(selectQuery)).WithArgs(entity.DeliveryPending).
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(job.DeliveryID, job.Attempts, job.URL, job.Secret,
+						job.Event.ID, job.Event.Type, job.Event.PersonID, []byte(job.Event.Payload), job.Event.OccurredAt))
+				mock.ExpectExec(regexp.QuoteMeta(leaseQuery)).
+					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			jobs, err := r.ClaimDeliveries(context.Background(), 10, 10*time.Second)
+
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				for i := range jobs {
+					assert.Len(t, jobs[i].LeaseToken, 2*leaseTokenSize)
+					jobs[i].LeaseToken = ""
+				}
+				assert.Equal(t, tc.want, jobs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_RecordOutcomes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	outcome := webhooks.Outcome{DeliveryID: 4, LeaseToken: "token", Delivered: true, StatusCode: 200, Duration: 15 * time.Millisecond}
+	recordQuery := "UPDATE webhook_delivery SET attempts = attempts + 1, last_status_code = $1, last_error = $2, status = $3, delivered_at = now() WHERE id = $4 AND lease_token = $5"
+	attemptQuery := "INSERT INTO webhook_attempt (delivery_id,status_code,error,duration_ms) VALUES ($1,$2,$3,$4)"
+
+	tests := []struct {
+		name         string
+		mockBehavior func()
+		wantErr      bool
+	}{
+		{
+			name: "Success",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(recordQuery)).
+					WithArgs(200, nil, entity.DeliveryDelivered, int64(4), "token").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(attemptQuery)).
+					WithArgs(int64(4), 200, nil, int64(15)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "SkippedLeaseLost",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(recordQuery)).
+					WithArgs(200, nil, entity.DeliveryDelivered, int64(4), "token").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "FailedRecord",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(recordQuery)).
+					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "FailedAttempt",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(recordQuery)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(attemptQuery)).
+					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			err := r.RecordOutcomes(context.Background(), []webhooks.Outcome{outcome})
+
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_Redeliver(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+	query := regexp.QuoteMeta("UPDATE webhook_delivery SET status = $1, attempts = $2, next_attempt_at = now(), delivered_at = $3, lease_token = $4 WHERE id = $5 AND subscription_id = $6")
+
+	mock.ExpectExec(query).WithArgs(entity.DeliveryPending, 0, nil, nil, int64(4), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.Redeliver(context.Background(), 1, 4))
+
+	mock.ExpectExec(query).WithArgs(entity.DeliveryPending, 0, nil, nil, int64(5), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.Redeliver(context.Background(), 1, 5), entity.ErrDeliveryNotExists)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPC serves gRPC services together with health checking and reflection
+type GRPC struct {
+	grpcServer      *grpc.Server
+	health          *health.Server
+	addr            string
+	notify          chan error
+	shutdownTimeout time.Duration
+}
+
+type GRPCConfig interface {
+	GetAddr() string
+	GetShutdownTimeout() time.Duration
+}
+
+// NewGRPC creates the server, register adds the services to serve
+func NewGRPC(cfg GRPCConfig, register func(s *grpc.Server), opts ...grpc.ServerOption) *GRPC {
+	grpcServer := grpc.NewServer(opts...)
+	register(grpcServer)
+
+	healthServer := health.NewServer()
+	for name := range grpcServer.GetServiceInfo() {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	return &GRPC{
+		grpcServer:      grpcServer,
+		health:          healthServer,
+		addr:            cfg.GetAddr(),
+		notify:          make(chan error, 1),
+		shutdownTimeout: cfg.GetShutdownTimeout(),
+	}
+}
+
+func (s *GRPC) Run() {
+	go func() {
+		listener, err := net.Listen("tcp", s.addr)
+		if err == nil {
+			err = s.grpcServer.Serve(listener)
+		}
+		s.notify <- err
+		close(s.notify)
+	}()
+}
+
+func (s *GRPC) Notify() <-chan error {
+	return s.notify
+}
+
+// Shutdown reports NOT_SERVING to health checks and waits for running calls
+// until the shutdown timeout, then cancels them
+func (s *GRPC) Shutdown() {
+	s.health.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, s.grpcServer.Stop)
+	defer stop()
+
+	s.grpcServer.GracefulStop()
+}
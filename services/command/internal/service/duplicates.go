@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+type DuplicatePolicy string
+
+const (
+	// DuplicateReject fails creation with the IDs of matching persons
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateReturnExisting returns the best matching person instead of creating one
+	DuplicateReturnExisting DuplicatePolicy = "return_existing"
+	// DuplicateCreate creates the person with a link to the best match
+	DuplicateCreate DuplicatePolicy = "create"
+)
+
+type DuplicatesConfig interface {
+	GetPolicy() string
+	IsFuzzy() bool
+	GetThreshold() float64
+}
+
+// DuplicateCheck repeats the duplicate lookup in the transaction creating
+// the person, after the lock serializing creations of the same name
+type DuplicateCheck struct {
+	Fuzzy bool
+	// Resolve gets the stored candidates and returns the person to link as a
+	// possible duplicate, or an error aborting the creation
+	Resolve func(candidates []entity.Person) (*int, error)
+}
+
+// findDuplicates returns stored persons matching the full name, the most
+// similar first
+func (s *Service) findDuplicates(ctx context.Context, person entity.Person) ([]entity.Person, error) {
+	layer := "service.findDuplicates"
+
+	candidates, err := s.repo.FindDuplicates(ctx, fullName(person), s.dup.IsFuzzy())
+	logger.DebugKV(ctx, "find duplicates", "layer", layer, "candidates", len(candidates), "err", err)
+	if err != nil {
+		return nil, err
+	}
+	return s.rankDuplicates(person, candidates), nil
+}
+
+// rankDuplicates keeps fuzzy candidates similar enough to the person, the
+// most similar first. Exact matches are kept as is.
+func (s *Service) rankDuplicates(person entity.Person, candidates []entity.Person) []entity.Person {
+	if !s.dup.IsFuzzy() {
+		return candidates
+	}
+
+	key := fullNameKey(fullName(person))
+	scores := make(map[int]float64, len(candidates))
+	var duplicates []entity.Person
+	for _, candidate := range candidates {
+		score := similarity(key, fullNameKey(fullName(candidate)))
+		if score >= s.dup.GetThreshold() {
+			scores[candidate.ID] = score
+			duplicates = append(duplicates, candidate)
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return scores[duplicates[i].ID] > scores[duplicates[j].ID]
+	})
+
+	return duplicates
+}
+
+// applyPolicy links the person to the best duplicate for DuplicateCreate and
+// fails with the duplicates for the other policies
+func applyPolicy(person *entity.Person, policy DuplicatePolicy, duplicates []entity.Person) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case DuplicateReject, DuplicateReturnExisting:
+		return &entity.DuplicateError{IDs: personIDs(duplicates)}
+	default:
+		person.PossibleDuplicateOf = &duplicates[0].ID
+		return nil
+	}
+}
+
+func fullName(person entity.Person) entity.FullName {
+	return entity.FullName{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}
+}
+
+func fullNameKey(name entity.FullName) string {
+	return strings.ToLower(strings.Join([]string{name.Surname, name.Name, name.Patronymic}, " "))
+}
+
+func personIDs(persons []entity.Person) []int {
+	ids := make([]int, 0, len(persons))
+	for _, person := range persons {
+		ids = append(ids, person.ID)
+	}
+	return ids
+}
+
+// similarity is the normalized Levenshtein similarity of two strings in [0, 1]
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+// Fields derived from the name by enrichment
+const (
+	FieldAge         = "age"
+	FieldGender      = "gender"
+	FieldNationalize = "nationalize"
+)
+
+var derivedFields = []string{FieldAge, FieldGender, FieldNationalize}
+
+type ReEnrichMode string
+
+const (
+	// ReEnrichInline recomputes derived fields before the update is stored
+	ReEnrichInline ReEnrichMode = "inline"
+	// ReEnrichBackground stores the update and recomputes derived fields afterwards
+	ReEnrichBackground ReEnrichMode = "background"
+	ReEnrichOff        ReEnrichMode = "off"
+)
+
+type EnrichmentConfig interface {
+	GetReEnrichMode() string
+	GetReEnrichTimeout() time.Duration
+}
+
+// enrich fills the given derived fields of person concurrently by its name
+func (s *Service) enrich(ctx context.Context, person *entity.Person, fields []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(fields))
+	for _, field := range fields {
+		wg.Add(1)
+		go func(field string) {
+			defer wg.Done()
+			if err := s.generate(ctx, person, field); err != nil {
+				errChan <- entity.ErrInvalidInput
+				cancel()
+			}
+		}(field)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	return <-errChan
+}
+
+func (s *Service) generate(ctx context.Context, person *entity.Person, field string) error {
+	layer := "service.enrich"
+
+	var err error
+	switch field {
+	case FieldAge:
+		person.Age, err = s.gen.GenerateAge(ctx, person.Name)
+		logger.DebugKV(ctx, "generate age", "layer", layer, "age", person.Age, "err", err)
+	case FieldGender:
+		person.Gender, err = s.gen.GenerateGender(ctx, person.Name)
+		logger.DebugKV(ctx, "generate gender", "layer", layer, "gender", person.Gender, "err", err)
+	case FieldNationalize:
+		person.Nationalize, err = s.gen.GenerateNationalize(ctx, person.Name)
+		logger.DebugKV(ctx, "generate nationalize", "layer", layer, "nationalize", person.Nationalize, "err", err)
+		if err == nil && person.Nationalize == nil {
+			err = entity.ErrInvalidInput
+		}
+	}
+
+	return err
+}
+
+// PreviewEnrichment returns the derived fields the providers give for the
+// normalized name. Nothing is stored.
+func (s *Service) PreviewEnrichment(ctx context.Context, name string) (entity.Person, error) {
+	person := entity.Person{Name: s.norm.Normalize(name)}
+	if err := s.enrich(ctx, &person, derivedFields); err != nil {
+		return entity.Person{}, err
+	}
+
+	return person, nil
+}
+
+// staleFields returns derived fields that have to be recomputed if the name
+// changes because the caller didn't provide them
+func (s *Service) staleFields(params *UpdateParams) []string {
+	if params.Name == nil || ReEnrichMode(s.enrichment.GetReEnrichMode()) == ReEnrichOff {
+		return nil
+	}
+
+	var fields []string
+	for _, field := range derivedFields {
+		if !params.provides(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// reEnrichInBackground recomputes derived fields after the update is stored.
+// Results are dropped if the name was changed again meanwhile.
+func (s *Service) reEnrichInBackground(ctx context.Context, id int, name string, fields []string) {
+	layer := "service.reEnrichInBackground"
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+
+		// the job outlives the request, only its values are kept
+		jobCtx, cancel := context.WithTimeout(audit.WithAction(context.WithoutCancel(ctx), audit.ActionEnrich),
+			s.enrichment.GetReEnrichTimeout())
+		defer cancel()
+
+		person := entity.Person{Name: name}
+		if err := s.enrich(jobCtx, &person, fields); err != nil {
+			logger.ErrorKV(jobCtx, "re-enrich person", "layer", layer, "id", id, "err", err)
+			return
+		}
+
+		params := &UpdateParams{IfName: &name}
+		params.setDerived(person, fields)
+		_, err := s.repo.Update(jobCtx, id, params)
+		logger.DebugKV(jobCtx, "store re-enriched fields", "layer", layer, "id", id, "fields", fields, "err", err)
+	}()
+}
+
+// Wait blocks until all background jobs are finished
+func (s *Service) Wait() {
+	s.jobs.Wait()
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+type MergeStrategy string
+
+const (
+	MergeKeepTarget MergeStrategy = "keep_target"
+	MergeKeepSource MergeStrategy = "keep_source"
+	// MergeNewest takes the value of the most recently updated person
+	MergeNewest MergeStrategy = "newest"
+)
+
+type MergeParams struct {
+	SourceID   int
+	Name       MergeStrategy
+	Surname    MergeStrategy
+	Patronymic MergeStrategy
+	Age        MergeStrategy
+	Gender     MergeStrategy
+}
+
+// Resolve builds the surviving person from target and source according to
+// field strategies. Nationalities of both persons are always combined.
+func (p *MergeParams) Resolve(target, source entity.Person) entity.Person {
+	merged := target
+	original := originalOf(target)
+
+	if p.pick(p.Name, target, source) {
+		merged.Name = source.Name
+		original.Name = originalOf(source).Name
+	}
+	if p.pick(p.Surname, target, source) {
+		merged.Surname = source.Surname
+		original.Surname = originalOf(source).Surname
+	}
+	if p.pick(p.Patronymic, target, source) {
+		merged.Patronymic = source.Patronymic
+		original.Patronymic = originalOf(source).Patronymic
+	}
+	if p.pick(p.Age, target, source) {
+		merged.Age = source.Age
+	}
+	if p.pick(p.Gender, target, source) {
+		merged.Gender = source.Gender
+	}
+
+	merged.Original = &original
+	merged.Nationalize = combineNationalities(target.Nationalize, source.Nationalize)
+
+	return merged
+}
+
+// pick reports whether the source value wins
+func (p *MergeParams) pick(strategy MergeStrategy, target, source entity.Person) bool {
+	switch strategy {
+	case MergeKeepSource:
+		return true
+	case MergeNewest:
+		return source.UpdatedAt.After(target.UpdatedAt)
+	default:
+		return false
+	}
+}
+
+func originalOf(person entity.Person) entity.FullName {
+	if person.Original != nil {
+		return *person.Original
+	}
+	return fullName(person)
+}
+
+// combineNationalities unites both lists keeping the highest probability
+// for every country
+func combineNationalities(lists ...[]entity.Nationality) []entity.Nationality {
+	probabilities := make(map[string]float64)
+	for _, list := range lists {
+		for _, nationality := range list {
+			if probability, ok := probabilities[nationality.Country]; !ok || nationality.Probability > probability {
+				probabilities[nationality.Country] = nationality.Probability
+			}
+		}
+	}
+
+	combined := make([]entity.Nationality, 0, len(probabilities))
+	for country, probability := range probabilities {
+		combined = append(combined, entity.Nationality{Country: country, Probability: probability})
+	}
+	sort.Slice(combined, func(i, j int) bool {
+		if combined[i].Probability != combined[j].Probability {
+			return combined[i].Probability > combined[j].Probability
+		}
+		return combined[i].Country < combined[j].Country
+	})
+
+	return combined
+}
+
+// Merge folds the source person into the target one. The source is
+// soft-deleted and redirects to the target afterwards.
+func (s *Service) Merge(ctx context.Context, targetID int, params *MergeParams) (entity.Person, error) {
+	layer := "service.Merge"
+
+	if targetID == params.SourceID {
+		return entity.Person{}, entity.ErrInvalidInput
+	}
+
+	person, err := s.repo.Merge(ctx, targetID, params)
+	logger.DebugKV(ctx, "merge persons", "layer", layer, "target", targetID, "source", params.SourceID, "err", err)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	return person, nil
+}
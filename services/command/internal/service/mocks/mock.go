@@ -37,18 +37,33 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockRepository) Create(ctx context.Context, person entity.Person) (int, error) {
+func (m *MockRepository) Create(ctx context.Context, person entity.Person, check *service.DuplicateCheck) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, person)
+	ret := m.ctrl.Call(m, "Create", ctx, person, check)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRepositoryMockRecorder) Create(ctx, person interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Create(ctx, person, check interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, person)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, person, check)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockRepository) CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockRepositoryMockRecorder) CreateWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockRepository)(nil).CreateWebhook), ctx, webhook)
 }
 
 // Delete mocks base method.
@@ -65,14 +80,133 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, id)
 }
 
-// Update mocks base method.
-func (m *MockRepository) Update(ctx context.Context, id int, params *service.UpdateParams) error {
+// DeleteWebhook mocks base method.
+func (m *MockRepository) DeleteWebhook(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, id, params)
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockRepositoryMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepository)(nil).DeleteWebhook), ctx, id)
+}
+
+// FindDuplicates mocks base method.
+func (m *MockRepository) FindDuplicates(ctx context.Context, name entity.FullName, fuzzy bool) ([]entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDuplicates", ctx, name, fuzzy)
+	ret0, _ := ret[0].([]entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDuplicates indicates an expected call of FindDuplicates.
+func (mr *MockRepositoryMockRecorder) FindDuplicates(ctx, name, fuzzy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDuplicates", reflect.TypeOf((*MockRepository)(nil).FindDuplicates), ctx, name, fuzzy)
+}
+
+// Get mocks base method.
+func (m *MockRepository) Get(ctx context.Context, id int) (entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRepositoryMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepository)(nil).Get), ctx, id)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockRepository) GetDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int64) ([]entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, subscriptionID, status, limit, offset)
+	ret0, _ := ret[0].([]entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockRepositoryMockRecorder) GetDeliveries(ctx, subscriptionID, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockRepository)(nil).GetDeliveries), ctx, subscriptionID, status, limit, offset)
+}
+
+// GetDelivery mocks base method.
+func (m *MockRepository) GetDelivery(ctx context.Context, subscriptionID int, id int64) (entity.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelivery", ctx, subscriptionID, id)
+	ret0, _ := ret[0].(entity.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelivery indicates an expected call of GetDelivery.
+func (mr *MockRepositoryMockRecorder) GetDelivery(ctx, subscriptionID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelivery", reflect.TypeOf((*MockRepository)(nil).GetDelivery), ctx, subscriptionID, id)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockRepository) GetWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]entity.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockRepositoryMockRecorder) GetWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockRepository)(nil).GetWebhooks), ctx)
+}
+
+// Merge mocks base method.
+func (m *MockRepository) Merge(ctx context.Context, targetID int, params *service.MergeParams) (entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Merge", ctx, targetID, params)
+	ret0, _ := ret[0].(entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Merge indicates an expected call of Merge.
+func (mr *MockRepositoryMockRecorder) Merge(ctx, targetID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Merge", reflect.TypeOf((*MockRepository)(nil).Merge), ctx, targetID, params)
+}
+
+// Redeliver mocks base method.
+func (m *MockRepository) Redeliver(ctx context.Context, subscriptionID int, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redeliver", ctx, subscriptionID, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// Redeliver indicates an expected call of Redeliver.
+func (mr *MockRepositoryMockRecorder) Redeliver(ctx, subscriptionID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redeliver", reflect.TypeOf((*MockRepository)(nil).Redeliver), ctx, subscriptionID, id)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, id int, params *service.UpdateParams) (entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, params)
+	ret0, _ := ret[0].(entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Update indicates an expected call of Update.
 func (mr *MockRepositoryMockRecorder) Update(ctx, id, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
@@ -146,3 +280,40 @@ func (mr *MockGeneratorMockRecorder) GenerateNationalize(ctx, name interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateNationalize", reflect.TypeOf((*MockGenerator)(nil).GenerateNationalize), ctx, name)
 }
+
+// MockNormalizer is a mock of Normalizer interface.
+type MockNormalizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockNormalizerMockRecorder
+}
+
+// MockNormalizerMockRecorder is the mock recorder for MockNormalizer.
+type MockNormalizerMockRecorder struct {
+	mock *MockNormalizer
+}
+
+// NewMockNormalizer creates a new mock instance.
+func NewMockNormalizer(ctrl *gomock.Controller) *MockNormalizer {
+	mock := &MockNormalizer{ctrl: ctrl}
+	mock.recorder = &MockNormalizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNormalizer) EXPECT() *MockNormalizerMockRecorder {
+	return m.recorder
+}
+
+// Normalize mocks base method.
+func (m *MockNormalizer) Normalize(value string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Normalize", value)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Normalize indicates an expected call of Normalize.
+func (mr *MockNormalizerMockRecorder) Normalize(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Normalize", reflect.TypeOf((*MockNormalizer)(nil).Normalize), value)
+}
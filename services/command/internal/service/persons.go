@@ -2,78 +2,232 @@ package service
 
 import (
 	"context"
-	"sync"
+	"errors"
 
 	"github.com/pintoter/persons/pkg/logger"
 	"github.com/pintoter/persons/services/command/internal/entity"
 )
 
-func (s *Service) CreatePerson(ctx context.Context, person entity.Person) (int, error) {
+// CreatePerson enriches and stores the person. When a duplicate is found and
+// the policy is DuplicateReturnExisting, the stored person is returned instead
+// with existed set to true.
+func (s *Service) CreatePerson(ctx context.Context, person entity.Person, policy DuplicatePolicy) (created entity.Person, existed bool, err error) {
 	layer := "service.Create"
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	var wg sync.WaitGroup
-	errChan := make(chan error)
-	go func() {
-		wg.Add(3)
-		go func() {
-			defer wg.Done()
-			var err error
-			person.Age, err = s.gen.GenerateAge(ctx, person.Name)
-			logger.DebugKV(ctx, "generate age", "layer", layer, "age", person.Age, "err", err)
-			if err != nil {
-				errChan <- entity.ErrInvalidInput
-			}
-		}()
-
-		go func() {
-			defer wg.Done()
-			var err error
-			person.Gender, err = s.gen.GenerateGender(ctx, person.Name)
-			logger.DebugKV(ctx, "generate gender", "layer", layer, "gender", person.Gender, "err", err)
-			if err != nil {
-				errChan <- entity.ErrInvalidInput
-			}
-		}()
-
-		go func() {
-			defer wg.Done()
-			var err error
-			person.Nationalize, err = s.gen.GenerateNationalize(ctx, person.Name)
-			logger.DebugKV(ctx, "generate nationalize", "layer", layer, "nationalize", person.Nationalize, "err", err)
-			if err != nil || person.Nationalize == nil {
-				errChan <- entity.ErrInvalidInput
-			}
-		}()
-
-		wg.Wait()
-		close(errChan)
-	}()
-
-	for err := range errChan {
+	s.normalizePerson(&person)
+	logger.DebugKV(ctx, "normalized person", "layer", layer, "person", person)
+
+	if policy == "" {
+		policy = DuplicatePolicy(s.dup.GetPolicy())
+	}
+
+	// duplicates found before the enrichment save calls to the providers,
+	// the check is repeated when the person is stored
+	duplicates, err := s.findDuplicates(ctx, person)
+	if err != nil {
+		return entity.Person{}, false, err
+	}
+	if err = applyPolicy(&person, policy, duplicates); err != nil {
+		return s.duplicateResult(ctx, policy, err)
+	}
+
+	if err = s.enrich(ctx, &person, derivedFields); err != nil {
+		return entity.Person{}, false, err
+	}
+
+	person.ID, err = s.repo.Create(ctx, person, &DuplicateCheck{
+		Fuzzy: s.dup.IsFuzzy(),
+		// the link found in the transaction is returned with the person
+		Resolve: func(candidates []entity.Person) (*int, error) {
+			person.PossibleDuplicateOf = nil
+			policyErr := applyPolicy(&person, policy, s.rankDuplicates(person, candidates))
+			return person.PossibleDuplicateOf, policyErr
+		},
+	})
+	if err != nil {
+		return s.duplicateResult(ctx, policy, err)
+	}
+
+	return person, false, nil
+}
+
+// duplicateResult returns the best duplicate for DuplicateReturnExisting,
+// and err otherwise
+func (s *Service) duplicateResult(ctx context.Context, policy DuplicatePolicy, err error) (entity.Person, bool, error) {
+	layer := "service.duplicateResult"
+
+	var dupErr *entity.DuplicateError
+	if !errors.As(err, &dupErr) {
+		return entity.Person{}, false, err
+	}
+	logger.DebugKV(ctx, "found duplicates", "layer", layer, "policy", policy, "ids", dupErr.IDs)
+
+	if policy != DuplicateReturnExisting {
+		return entity.Person{}, false, err
+	}
+	existing, err := s.repo.Get(ctx, dupErr.IDs[0])
+	return existing, err == nil, err
+}
+
+type UpdateParams struct {
+	Name        *string
+	Surname     *string
+	Patronymic  *string
+	Age         *int
+	Gender      *string
+	Nationalize []entity.Nationality
+
+	NameOriginal       *string
+	SurnameOriginal    *string
+	PatronymicOriginal *string
+
+	// IfName makes the update conditional on the stored name
+	IfName *string
+}
+
+// HasColumns reports whether params change any person column
+func (p *UpdateParams) HasColumns() bool {
+	return p.Name != nil || p.Surname != nil || p.Patronymic != nil || p.Age != nil || p.Gender != nil
+}
+
+func (p *UpdateParams) provides(field string) bool {
+	switch field {
+	case FieldAge:
+		return p.Age != nil
+	case FieldGender:
+		return p.Gender != nil
+	case FieldNationalize:
+		return p.Nationalize != nil
+	default:
+		return false
+	}
+}
+
+func (p *UpdateParams) setDerived(person entity.Person, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case FieldAge:
+			p.Age = &person.Age
+		case FieldGender:
+			p.Gender = &person.Gender
+		case FieldNationalize:
+			p.Nationalize = person.Nationalize
+		}
+	}
+}
+
+type UpdateResult struct {
+	// Person is the updated representation
+	Person entity.Person
+	// Recomputed lists derived fields re-enriched and stored with the update
+	Recomputed []string
+	// Scheduled lists derived fields being re-enriched in background
+	Scheduled []string
+}
+
+// updateAttempts limits how many times an update is retried when the name it
+// was compared with changes before the update is stored
+const updateAttempts = 3
+
+// Update stores changed fields. When the name changes, derived fields that
+// weren't provided by the caller are re-enriched according to the config.
+func (s *Service) Update(ctx context.Context, id int, params *UpdateParams) (UpdateResult, error) {
+	layer := "service.Update"
+
+	s.normalizeParams(params)
+
+	fields := s.staleFields(params)
+	if len(fields) == 0 {
+		person, err := s.repo.Update(ctx, id, params)
 		if err != nil {
-			return 0, err
+			return UpdateResult{}, err
 		}
+		return UpdateResult{Person: person}, nil
 	}
 
-	id, err := s.repo.Create(ctx, person)
+	var err error
+	for attempt := 1; attempt <= updateAttempts; attempt++ {
+		var result UpdateResult
+		result, err = s.rename(ctx, id, *params, fields)
+		if !errors.Is(err, entity.ErrNameChanged) {
+			return result, err
+		}
+		logger.DebugKV(ctx, "name changed meanwhile", "layer", layer, "id", id, "attempt", attempt)
+	}
+
+	return UpdateResult{}, err
+}
+
+// rename stores an update that sets the name. Stale fields are recomputed if
+// the name differs from the stored one. The update is conditional on the
+// stored name, so the repository rejects it with entity.ErrNameChanged if a
+// concurrent update renamed the person after the comparison.
+func (s *Service) rename(ctx context.Context, id int, params UpdateParams, stale []string) (UpdateResult, error) {
+	layer := "service.rename"
+
+	current, err := s.repo.Get(ctx, id)
 	if err != nil {
-		return 0, err
+		return UpdateResult{}, err
+	}
+	params.IfName = &current.Name
+
+	var fields []string
+	if current.Name != *params.Name {
+		fields = stale
 	}
+	logger.DebugKV(ctx, "stale fields", "layer", layer, "fields", fields)
 
-	return id, nil
+	var result UpdateResult
+	background := ReEnrichMode(s.enrichment.GetReEnrichMode()) == ReEnrichBackground
+	if len(fields) > 0 && !background {
+		person := entity.Person{Name: *params.Name}
+		if err = s.enrich(ctx, &person, fields); err != nil {
+			return UpdateResult{}, err
+		}
+		params.setDerived(person, fields)
+		result.Recomputed = fields
+	}
+
+	result.Person, err = s.repo.Update(ctx, id, &params)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	if len(fields) > 0 && background {
+		s.reEnrichInBackground(ctx, id, *params.Name, fields)
+		result.Scheduled = fields
+	}
+
+	return result, nil
 }
 
-type UpdateParams struct {
-	Name       *string
-	Surname    *string
-	Patronymic *string
+// normalizePerson keeps the as-entered full name and replaces it with the
+// normalized one, so enrichment and storage always see the canonical form
+func (s *Service) normalizePerson(person *entity.Person) {
+	person.Original = &entity.FullName{
+		Name:       person.Name,
+		Surname:    person.Surname,
+		Patronymic: person.Patronymic,
+	}
+
+	person.Name = s.norm.Normalize(person.Name)
+	person.Surname = s.norm.Normalize(person.Surname)
+	person.Patronymic = s.norm.Normalize(person.Patronymic)
 }
 
-func (s *Service) Update(ctx context.Context, id int, params *UpdateParams) error {
-	return s.repo.Update(ctx, id, params)
+func (s *Service) normalizeParams(params *UpdateParams) {
+	params.NameOriginal, params.Name = s.normalizeField(params.Name)
+	params.SurnameOriginal, params.Surname = s.normalizeField(params.Surname)
+	params.PatronymicOriginal, params.Patronymic = s.normalizeField(params.Patronymic)
+}
+
+func (s *Service) normalizeField(value *string) (original, normalized *string) {
+	if value == nil {
+		return nil, nil
+	}
+
+	result := s.norm.Normalize(*value)
+	return value, &result
 }
 
 func (s *Service) Delete(ctx context.Context, id int) error {
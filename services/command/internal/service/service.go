@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sync"
 
 	"github.com/pintoter/persons/services/command/internal/entity"
 )
@@ -9,9 +10,19 @@ import (
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Repository interface {
-	Create(ctx context.Context, person entity.Person) (int, error)
-	Update(ctx context.Context, id int, params *UpdateParams) error
+	Get(ctx context.Context, id int) (entity.Person, error)
+	FindDuplicates(ctx context.Context, name entity.FullName, fuzzy bool) ([]entity.Person, error)
+	Create(ctx context.Context, person entity.Person, check *DuplicateCheck) (int, error)
+	Update(ctx context.Context, id int, params *UpdateParams) (entity.Person, error)
 	Delete(ctx context.Context, id int) error
+	Merge(ctx context.Context, targetID int, params *MergeParams) (entity.Person, error)
+
+	CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int64) ([]entity.Delivery, error)
+	GetDelivery(ctx context.Context, subscriptionID int, id int64) (entity.Delivery, error)
+	Redeliver(ctx context.Context, subscriptionID int, id int64) error
 }
 
 type Generator interface {
@@ -20,14 +31,26 @@ type Generator interface {
 	GenerateNationalize(ctx context.Context, name string) ([]entity.Nationality, error)
 }
 
+type Normalizer interface {
+	Normalize(value string) string
+}
+
 type Service struct {
-	repo Repository
-	gen  Generator
+	repo       Repository
+	gen        Generator
+	norm       Normalizer
+	dup        DuplicatesConfig
+	enrichment EnrichmentConfig
+
+	jobs sync.WaitGroup
 }
 
-func New(repo Repository, gen Generator) *Service {
+func New(repo Repository, gen Generator, norm Normalizer, dup DuplicatesConfig, enrichment EnrichmentConfig) *Service {
 	return &Service{
-		repo: repo,
-		gen:  gen,
+		repo:       repo,
+		gen:        gen,
+		norm:       norm,
+		dup:        dup,
+		enrichment: enrichment,
 	}
 }
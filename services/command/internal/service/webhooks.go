@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+// secretSize is the number of random bytes in generated webhook secrets
+const secretSize = 32
+
+// CreateWebhook subscribes url to person events. A secret for signatures is
+// generated when the subscriber doesn't provide one.
+func (s *Service) CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	layer := "service.CreateWebhook"
+
+	if webhook.Secret == "" {
+		secret := make([]byte, secretSize)
+		if _, err := rand.Read(secret); err != nil {
+			logger.ErrorKV(ctx, "generate secret", "layer", layer, "err", err)
+			return entity.Webhook{}, entity.ErrInternalService
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+
+	webhook, err := s.repo.CreateWebhook(ctx, webhook)
+	if err != nil {
+		logger.ErrorKV(ctx, "create webhook", "layer", layer, "err", err)
+		return entity.Webhook{}, entity.ErrInternalService
+	}
+
+	return webhook, nil
+}
+
+func (s *Service) GetWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	webhooks, err := s.repo.GetWebhooks(ctx)
+	if err != nil {
+		logger.ErrorKV(ctx, "get webhooks", "layer", "service.GetWebhooks", "err", err)
+		return nil, entity.ErrInternalService
+	}
+
+	return webhooks, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id int) error {
+	return webhookError(ctx, "service.DeleteWebhook", s.repo.DeleteWebhook(ctx, id))
+}
+
+// GetDeliveries returns the delivery log of the webhook. Deliveries with
+// status dead form its dead-letter list.
+func (s *Service) GetDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int64) ([]entity.Delivery, error) {
+	deliveries, err := s.repo.GetDeliveries(ctx, subscriptionID, status, limit, offset)
+	if err != nil {
+		return nil, webhookError(ctx, "service.GetDeliveries", err)
+	}
+
+	return deliveries, nil
+}
+
+func (s *Service) GetDelivery(ctx context.Context, subscriptionID int, id int64) (entity.Delivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, subscriptionID, id)
+	if err != nil {
+		return entity.Delivery{}, webhookError(ctx, "service.GetDelivery", err)
+	}
+
+	return delivery, nil
+}
+
+// Redeliver queues delivery again, e.g. from the dead-letter list after the
+// receiver is fixed
+func (s *Service) Redeliver(ctx context.Context, subscriptionID int, id int64) (entity.Delivery, error) {
+	layer := "service.Redeliver"
+
+	if err := s.repo.Redeliver(ctx, subscriptionID, id); err != nil {
+		return entity.Delivery{}, webhookError(ctx, layer, err)
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, subscriptionID, id)
+	if err != nil {
+		return entity.Delivery{}, webhookError(ctx, layer, err)
+	}
+
+	return delivery, nil
+}
+
+// webhookError keeps not found errors and hides the rest
+func webhookError(ctx context.Context, layer string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, entity.ErrWebhookNotExists), errors.Is(err, entity.ErrDeliveryNotExists):
+		return err
+	default:
+		logger.ErrorKV(ctx, "webhook repository", "layer", layer, "err", err)
+		return entity.ErrInternalService
+	}
+}
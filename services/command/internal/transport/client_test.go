@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/normalize"
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/service"
+	mock_service "github.com/pintoter/persons/services/command/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// newClient returns the SDK client of a server built from the real handler
+func newClient(t *testing.T, policy string, behavior func(r *mock_service.MockRepository, g *mock_service.MockGenerator)) *personsclient.Client {
+	c := gomock.NewController(t)
+
+	repo := mock_service.NewMockRepository(c)
+	gen := mock_service.NewMockGenerator(c)
+	behavior(repo, gen)
+
+	services := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: policy}, enrichmentConfig{mode: "inline"})
+	server := httptest.NewServer(NewHandler(services))
+	t.Cleanup(server.Close)
+
+	client, err := personsclient.New(server.URL, personsclient.WithRetries(1, time.Millisecond))
+	assert.NoError(t, err)
+	return client
+}
+
+func Test_ClientCreatePerson(t *testing.T) {
+	client := newClient(t, "reject", func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		name := entity.FullName{Name: "Ivan", Surname: "Ivanov"}
+		r.EXPECT().FindDuplicates(gomock.Any(), name, false).Return(nil, nil)
+		g.EXPECT().GenerateAge(gomock.Any(), "Ivan").Return(18, nil)
+		g.EXPECT().GenerateGender(gomock.Any(), "Ivan").Return("male", nil)
+		g.EXPECT().GenerateNationalize(gomock.Any(), "Ivan").Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+		r.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
+
+		existing := []entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}}
+		r.EXPECT().FindDuplicates(gomock.Any(), name, false).Return(existing, nil).Times(2)
+		r.EXPECT().Get(gomock.Any(), 1).Return(existing[0], nil)
+	})
+
+	ctx := context.Background()
+	person, created, err := client.CreatePerson(ctx, personsclient.FullName{Name: "Ivan", Surname: "Ivanov"}, personsclient.DuplicateDefault)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, personsclient.Person{
+		ID:          1,
+		Name:        "Ivan",
+		Surname:     "Ivanov",
+		Age:         18,
+		Gender:      personsclient.Male,
+		Nationalize: []personsclient.Nationality{{Country: "RU", Probability: 0.1}},
+		Original:    &personsclient.FullName{Name: "Ivan", Surname: "Ivanov"},
+	}, person)
+
+	_, _, err = client.CreatePerson(ctx, personsclient.FullName{Name: "Ivan", Surname: "Ivanov"}, personsclient.DuplicateDefault)
+	assert.ErrorIs(t, err, personsclient.ErrDuplicate)
+	var apiErr *personsclient.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, []int{1}, apiErr.Duplicates)
+	}
+
+	person, created, err = client.CreatePerson(ctx, personsclient.FullName{Name: "Ivan", Surname: "Ivanov"}, personsclient.DuplicateReturnExisting)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, 1, person.ID)
+}
+
+func Test_ClientCreatePersonInvalid(t *testing.T) {
+	client := newClient(t, "reject", func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {})
+
+	_, _, err := client.CreatePerson(context.Background(), personsclient.FullName{Name: "I"}, personsclient.DuplicateDefault)
+	assert.ErrorIs(t, err, personsclient.ErrInvalidInput)
+
+	var apiErr *personsclient.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, []personsclient.Violation{
+			{Field: "name", Code: codeTooShort, Message: "name must be at least 2 characters"},
+			{Field: "surname", Code: codeRequired, Message: "surname is required"},
+		}, apiErr.Violations)
+	}
+}
+
+func Test_ClientUpdateDeleteMerge(t *testing.T) {
+	surname := personsclient.String("Petrov")
+	client := newClient(t, "reject", func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		r.EXPECT().Update(gomock.Any(), 1, &service.UpdateParams{Surname: surname, SurnameOriginal: surname}).
+			Return(entity.Person{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 18, Gender: "male"}, nil)
+		r.EXPECT().Delete(gomock.Any(), 2).Return(entity.ErrPersonNotExists)
+		r.EXPECT().Merge(gomock.Any(), 1, &service.MergeParams{
+			SourceID:   3,
+			Name:       service.MergeKeepTarget,
+			Surname:    service.MergeKeepTarget,
+			Patronymic: service.MergeKeepTarget,
+			Age:        service.MergeKeepSource,
+			Gender:     service.MergeKeepTarget,
+		}).
+			Return(entity.Person{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 40, Gender: "male"}, nil)
+	})
+
+	ctx := context.Background()
+	result, err := client.UpdatePerson(ctx, 1, personsclient.UpdatePersonInput{Surname: surname})
+	assert.NoError(t, err)
+	assert.Equal(t, "Petrov", result.Person.Surname)
+
+	err = client.DeletePerson(ctx, 2)
+	assert.ErrorIs(t, err, personsclient.ErrNotFound)
+
+	person, err := client.MergePersons(ctx, 1, personsclient.MergeInput{SourceID: 3, Rules: map[string]string{"age": personsclient.KeepSource}})
+	assert.NoError(t, err)
+	assert.Equal(t, 40, person.Age)
+}
+
+func Test_ClientWebhooks(t *testing.T) {
+	deliveries := []entity.Delivery{
+		{ID: 2, SubscriptionID: 1, EventID: 7, EventType: "PersonCreated", Status: entity.DeliveryDead, Attempts: 5, CreatedAt: webhookCreatedAt},
+		{ID: 1, SubscriptionID: 1, EventID: 6, EventType: "PersonCreated", Status: entity.DeliveryDead, Attempts: 5, CreatedAt: webhookCreatedAt},
+	}
+
+	client := newClient(t, "reject", func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		r.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).
+			Return(entity.Webhook{ID: 1, URL: "http://localhost:9090/hook", Secret: "0123456789abcdef", CreatedAt: webhookCreatedAt}, nil)
+		r.EXPECT().GetDeliveries(gomock.Any(), 1, entity.DeliveryDead, int64(100), int64(0)).Return(deliveries, nil)
+		r.EXPECT().Redeliver(gomock.Any(), 1, int64(2)).Return(nil)
+		r.EXPECT().GetDelivery(gomock.Any(), 1, int64(2)).Return(entity.Delivery{ID: 2, SubscriptionID: 1, Status: entity.DeliveryPending}, nil)
+		r.EXPECT().DeleteWebhook(gomock.Any(), 5).Return(entity.ErrWebhookNotExists)
+	})
+
+	ctx := context.Background()
+	webhook, err := client.CreateWebhook(ctx, personsclient.WebhookInput{URL: "http://localhost:9090/hook", Secret: "0123456789abcdef"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, webhook.ID)
+	assert.Equal(t, webhookCreatedAt, webhook.CreatedAt)
+
+	var ids []int64
+	for delivery, err := range client.Deliveries(ctx, 1, personsclient.DeliveryDead) {
+		assert.NoError(t, err)
+		ids = append(ids, delivery.ID)
+	}
+	assert.Equal(t, []int64{2, 1}, ids)
+
+	delivery, err := client.Redeliver(ctx, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, personsclient.DeliveryPending, delivery.Status)
+
+	err = client.DeleteWebhook(ctx, 5)
+	assert.ErrorIs(t, err, personsclient.ErrNotFound)
+}
+
+func Test_ClientPreviewEnrichment(t *testing.T) {
+	client := newClient(t, "reject", func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		g.EXPECT().GenerateAge(gomock.Any(), "Anna").Return(25, nil)
+		g.EXPECT().GenerateGender(gomock.Any(), "Anna").Return("female", nil)
+		g.EXPECT().GenerateNationalize(gomock.Any(), "Anna").Return([]entity.Nationality{{Country: "UA", Probability: 0.3}}, nil)
+	})
+
+	enrichment, err := client.PreviewEnrichment(context.Background(), "anna")
+	assert.NoError(t, err)
+	assert.Equal(t, personsclient.Enrichment{
+		Name:        "Anna",
+		Age:         25,
+		Gender:      personsclient.Female,
+		Nationalize: []personsclient.Nationality{{Country: "UA", Probability: 0.3}},
+	}, enrichment)
+}
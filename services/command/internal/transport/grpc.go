@@ -0,0 +1,233 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	commandv1 "github.com/pintoter/persons/services/command/api/persons/command/v1"
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/service"
+)
+
+const errorDomain = "persons.command"
+
+// GRPCHandler serves PersonCommandService with the same validation and
+// service as the HTTP handler
+type GRPCHandler struct {
+	commandv1.UnimplementedPersonCommandServiceServer
+	service *service.Service
+}
+
+func NewGRPCHandler(service *service.Service) *GRPCHandler {
+	return &GRPCHandler{service: service}
+}
+
+func (h *GRPCHandler) Register(s *grpc.Server) {
+	commandv1.RegisterPersonCommandServiceServer(s, h)
+}
+
+// AuditInterceptor is auditMiddleware for gRPC: actor and request ID are
+// taken from x-actor and x-request-id metadata
+func AuditInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	value := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	requestID := value(strings.ToLower(requestIDHeader))
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(requestIDHeader), requestID))
+
+	ctx = audit.WithMeta(ctx, audit.Meta{
+		Actor:     value(strings.ToLower(actorHeader)),
+		RequestID: requestID,
+	})
+	return handler(ctx, req)
+}
+
+var duplicatePolicies = map[commandv1.DuplicatePolicy]service.DuplicatePolicy{
+	commandv1.DuplicatePolicy_DUPLICATE_POLICY_REJECT:          service.DuplicateReject,
+	commandv1.DuplicatePolicy_DUPLICATE_POLICY_RETURN_EXISTING: service.DuplicateReturnExisting,
+	commandv1.DuplicatePolicy_DUPLICATE_POLICY_CREATE:          service.DuplicateCreate,
+}
+
+func (h *GRPCHandler) CreatePerson(ctx context.Context, req *commandv1.CreatePersonRequest) (*commandv1.CreatePersonResponse, error) {
+	input := createPersonInput{
+		Name:        req.GetName(),
+		Surname:     req.GetSurname(),
+		Patronymic:  req.GetPatronymic(),
+		OnDuplicate: string(duplicatePolicies[req.GetOnDuplicate()]),
+	}
+	if err := input.validate(); err != nil {
+		return nil, grpcError(err)
+	}
+
+	person, existed, err := h.service.CreatePerson(ctx, entity.Person{
+		Name:       input.Name,
+		Surname:    input.Surname,
+		Patronymic: input.Patronymic,
+	}, service.DuplicatePolicy(input.OnDuplicate))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &commandv1.CreatePersonResponse{Person: personToProto(person), Existed: existed}, nil
+}
+
+func (h *GRPCHandler) UpdatePerson(ctx context.Context, req *commandv1.UpdatePersonRequest) (*commandv1.UpdatePersonResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, grpcError(entity.ErrInvalidQueryId)
+	}
+
+	input := updatePersonInput{
+		ID:         int(req.GetId()),
+		Name:       req.Name,
+		Surname:    req.Surname,
+		Patronymic: req.Patronymic,
+		Gender:     req.Gender,
+	}
+	if req.Age != nil {
+		age := int(req.GetAge())
+		input.Age = &age
+	}
+	if req.Nationalize != nil {
+		input.Nationalize = nationalitiesFromProto(req.GetNationalize().GetItems())
+	}
+	if err := input.validate(); err != nil {
+		return nil, grpcError(err)
+	}
+
+	result, err := h.service.Update(ctx, input.ID, &service.UpdateParams{
+		Name:        input.Name,
+		Surname:     input.Surname,
+		Patronymic:  input.Patronymic,
+		Age:         input.Age,
+		Gender:      input.Gender,
+		Nationalize: input.Nationalize,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &commandv1.UpdatePersonResponse{
+		Person:     personToProto(result.Person),
+		Recomputed: result.Recomputed,
+		Scheduled:  result.Scheduled,
+	}, nil
+}
+
+func (h *GRPCHandler) DeletePerson(ctx context.Context, req *commandv1.DeletePersonRequest) (*commandv1.DeletePersonResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, grpcError(entity.ErrInvalidQueryId)
+	}
+
+	if err := h.service.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &commandv1.DeletePersonResponse{}, nil
+}
+
+// grpcError maps service errors to status codes the way renderError maps
+// them to HTTP statuses. Violations and duplicates are sent as error details.
+func grpcError(err error) error {
+	var vErr *validationError
+	if errors.As(err, &vErr) {
+		details := &errdetails.BadRequest{}
+		for _, v := range vErr.violations {
+			details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+				Reason:      v.Code,
+			})
+		}
+		return withDetails(status.New(codes.InvalidArgument, vErr.Error()), details)
+	}
+
+	var dupErr *entity.DuplicateError
+	if errors.As(err, &dupErr) {
+		ids := make([]string, len(dupErr.IDs))
+		for i, id := range dupErr.IDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		return withDetails(status.New(codes.AlreadyExists, dupErr.Error()), &errdetails.ErrorInfo{
+			Reason:   "DUPLICATE_PERSON",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"duplicates": strings.Join(ids, ",")},
+		})
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrPersonNotExists):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrNameChanged):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, entity.ErrInvalidInput), errors.Is(err, entity.ErrInvalidQueryId):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func personToProto(person entity.Person) *commandv1.Person {
+	result := &commandv1.Person{
+		Id:          int64(person.ID),
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		Age:         int32(person.Age),
+		Gender:      person.Gender,
+		Nationalize: nationalitiesToProto(person.Nationalize),
+	}
+	if person.Original != nil {
+		result.Original = &commandv1.FullName{
+			Name:       person.Original.Name,
+			Surname:    person.Original.Surname,
+			Patronymic: person.Original.Patronymic,
+		}
+	}
+	if person.PossibleDuplicateOf != nil {
+		id := int64(*person.PossibleDuplicateOf)
+		result.PossibleDuplicateOf = &id
+	}
+	return result
+}
+
+func nationalitiesToProto(nationalities []entity.Nationality) []*commandv1.Nationality {
+	result := make([]*commandv1.Nationality, len(nationalities))
+	for i, n := range nationalities {
+		result[i] = &commandv1.Nationality{CountryId: n.Country, Probability: n.Probability}
+	}
+	return result
+}
+
+func nationalitiesFromProto(nationalities []*commandv1.Nationality) []entity.Nationality {
+	result := make([]entity.Nationality, len(nationalities))
+	for i, n := range nationalities {
+		result[i] = entity.Nationality{Country: n.GetCountryId(), Probability: n.GetProbability()}
+	}
+	return result
+}
@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pintoter/persons/pkg/normalize"
+	commandv1 "github.com/pintoter/persons/services/command/api/persons/command/v1"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/service"
+	mock_service "github.com/pintoter/persons/services/command/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGRPCClient(t *testing.T, behavior func(r *mock_service.MockRepository, g *mock_service.MockGenerator)) commandv1.PersonCommandServiceClient {
+	c := gomock.NewController(t)
+
+	repo := mock_service.NewMockRepository(c)
+	gen := mock_service.NewMockGenerator(c)
+	behavior(repo, gen)
+
+	services := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "reject"}, enrichmentConfig{mode: "inline"})
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(AuditInterceptor))
+	NewGRPCHandler(services).Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return commandv1.NewPersonCommandServiceClient(conn)
+}
+
+func Test_GRPCCreatePerson(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		person := entity.Person{
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			Age:         18,
+			Gender:      "male",
+			Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}},
+			Original:    &entity.FullName{Name: "Ivan", Surname: "Ivanov"},
+		}
+		r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).Return(nil, nil)
+		g.EXPECT().GenerateAge(gomock.Any(), "Ivan").Return(18, nil)
+		g.EXPECT().GenerateGender(gomock.Any(), "Ivan").Return("male", nil)
+		g.EXPECT().GenerateNationalize(gomock.Any(), "Ivan").Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+		r.EXPECT().Create(gomock.Any(), person, gomock.Any()).Return(1, nil)
+	})
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1")
+	var header metadata.MD
+	resp, err := client.CreatePerson(ctx, &commandv1.CreatePersonRequest{Name: "Ivan", Surname: "Ivanov"}, grpc.Header(&header))
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(1), resp.GetPerson().GetId())
+	assert.Equal(t, "Ivan", resp.GetPerson().GetName())
+	assert.Equal(t, int32(18), resp.GetPerson().GetAge())
+	assert.Equal(t, "RU", resp.GetPerson().GetNationalize()[0].GetCountryId())
+	assert.False(t, resp.GetExisted())
+	assert.Equal(t, []string{"req-1"}, header.Get("x-request-id"))
+}
+
+func Test_GRPCCreatePersonInvalid(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {})
+
+	_, err := client.CreatePerson(context.Background(), &commandv1.CreatePersonRequest{Name: "I", Surname: "Ivanov1"})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		details := st.Details()[0].(*errdetails.BadRequest)
+		assert.Equal(t, "name", details.GetFieldViolations()[0].GetField())
+		assert.Equal(t, codeTooShort, details.GetFieldViolations()[0].GetReason())
+		assert.Equal(t, "surname", details.GetFieldViolations()[1].GetField())
+		assert.Equal(t, codeInvalidCharacters, details.GetFieldViolations()[1].GetReason())
+	}
+}
+
+func Test_GRPCCreatePersonDuplicate(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).
+			Return([]entity.Person{{ID: 7, Name: "Ivan", Surname: "Ivanov"}}, nil)
+	})
+
+	_, err := client.CreatePerson(context.Background(), &commandv1.CreatePersonRequest{
+		Name:        "Ivan",
+		Surname:     "Ivanov",
+		OnDuplicate: commandv1.DuplicatePolicy_DUPLICATE_POLICY_REJECT,
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.AlreadyExists, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, "DUPLICATE_PERSON", info.GetReason())
+		assert.Equal(t, "7", info.GetMetadata()["duplicates"])
+	}
+}
+
+func Test_GRPCUpdatePerson(t *testing.T) {
+	surname := proto.String("Petrov")
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		r.EXPECT().Update(gomock.Any(), 1, &service.UpdateParams{Surname: surname, SurnameOriginal: surname}).
+			Return(entity.Person{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 18, Gender: "male"}, nil)
+	})
+
+	resp, err := client.UpdatePerson(context.Background(), &commandv1.UpdatePersonRequest{Id: 1, Surname: surname})
+	assert.NoError(t, err)
+	assert.Equal(t, "Petrov", resp.GetPerson().GetSurname())
+	assert.Empty(t, resp.GetRecomputed())
+}
+
+func Test_GRPCUpdatePersonEmpty(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {})
+
+	_, err := client.UpdatePerson(context.Background(), &commandv1.UpdatePersonRequest{Id: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_GRPCUpdatePersonNaNProbability(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {})
+
+	_, err := client.UpdatePerson(context.Background(), &commandv1.UpdatePersonRequest{
+		Id: 1,
+		Nationalize: &commandv1.NationalityList{Items: []*commandv1.Nationality{
+			{CountryId: "RU", Probability: math.NaN()},
+		}},
+	})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		violations := st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "nationalize[0].probability", violations[0].GetField())
+		}
+	}
+}
+
+func Test_GRPCDeletePerson(t *testing.T) {
+	client := newGRPCClient(t, func(r *mock_service.MockRepository, g *mock_service.MockGenerator) {
+		r.EXPECT().Delete(gomock.Any(), 1).Return(nil)
+		r.EXPECT().Delete(gomock.Any(), 2).Return(entity.ErrPersonNotExists)
+	})
+
+	_, err := client.DeletePerson(context.Background(), &commandv1.DeletePersonRequest{Id: 1})
+	assert.NoError(t, err)
+
+	_, err = client.DeletePerson(context.Background(), &commandv1.DeletePersonRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.DeletePerson(context.Background(), &commandv1.DeletePersonRequest{Id: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -33,10 +33,20 @@ func NewHandler(service *service.Service) *Handler {
 
 func (h *Handler) InitRoutes() {
 	v1 := h.router.PathPrefix("/api/v1").Subrouter()
+	v1.Use(auditMiddleware)
 	{
 		v1.HandleFunc("/persons", h.createPerson).Methods(http.MethodPost)
 		v1.HandleFunc("/persons/{id:[0-9]+}", h.updatePerson).Methods(http.MethodPatch)
 		v1.HandleFunc("/persons/{id:[0-9]+}", h.deletePerson).Methods(http.MethodDelete)
+		v1.HandleFunc("/persons/{id:[0-9]+}/merge", h.mergePersons).Methods(http.MethodPost)
+		v1.HandleFunc("/enrichment", h.previewEnrichment).Methods(http.MethodGet)
+
+		v1.HandleFunc("/webhooks", h.createWebhook).Methods(http.MethodPost)
+		v1.HandleFunc("/webhooks", h.getWebhooks).Methods(http.MethodGet)
+		v1.HandleFunc("/webhooks/{id:[0-9]+}", h.deleteWebhook).Methods(http.MethodDelete)
+		v1.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", h.getDeliveries).Methods(http.MethodGet)
+		v1.HandleFunc("/webhooks/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}", h.getDelivery).Methods(http.MethodGet)
+		v1.HandleFunc("/webhooks/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}/redeliver", h.redeliver).Methods(http.MethodPost)
 	}
 }
 
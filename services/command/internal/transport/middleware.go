@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/pintoter/persons/services/command/internal/audit"
+)
+
+const (
+	actorHeader     = "X-Actor"
+	requestIDHeader = "X-Request-ID"
+)
+
+// auditMiddleware puts actor and request ID into the request context to be
+// recorded in person history. There is no authentication yet, so the actor
+// is taken from the header. Missing request ID is generated and returned.
+func auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		ctx := audit.WithMeta(r.Context(), audit.Meta{
+			Actor:     r.Header.Get(actorHeader),
+			RequestID: requestID,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
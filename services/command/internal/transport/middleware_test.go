@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pintoter/persons/services/command/internal/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		actor     string
+		requestID string
+		want      audit.Meta
+	}{
+		{
+			name:      "FromHeaders",
+			actor:     "admin",
+			requestID: "42",
+			want:      audit.Meta{Actor: "admin", RequestID: "42"},
+		},
+		{
+			name: "Anonymous",
+			want: audit.Meta{Actor: "anonymous"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got audit.Meta
+			handler := auditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = audit.MetaFrom(r.Context())
+			}))
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("DELETE", "/api/v1/persons/1", nil)
+			if tt.actor != "" {
+				r.Header.Set(actorHeader, tt.actor)
+			}
+			if tt.requestID != "" {
+				r.Header.Set(requestIDHeader, tt.requestID)
+			}
+
+			handler.ServeHTTP(w, r)
+
+			if tt.want.RequestID == "" {
+				assert.Len(t, got.RequestID, 32)
+				tt.want.RequestID = got.RequestID
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.RequestID, w.Header().Get(requestIDHeader))
+		})
+	}
+}
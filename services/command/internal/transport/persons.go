@@ -12,79 +12,114 @@ import (
 )
 
 // @Summary Create person
-// @Description Create person
+// @Description Create person. Persons with the same normalized full name are handled according to on_duplicate policy. Send Accept: application/vnd.persons.v1+json to get the legacy message response
 // @Tags persons
 // @Accept json
 // @Produce json
 // @Param input body createPersonInput true "Person's information"
-// @Success 201 {object} successResponse
+// @Param on_duplicate query string false "duplicate policy" Enums(reject, return_existing, create)
+// @Success 200 {object} getPersonResponse
+// @Success 201 {object} getPersonResponse
+// @Header 201 {string} Location "/api/v1/persons/{id}"
 // @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/v1/persons [post]
 func (h *Handler) createPerson(w http.ResponseWriter, r *http.Request) {
 	var input createPersonInput
 	if err := input.Set(r); err != nil {
-		renderJSON(w, r, http.StatusBadRequest, errorResponse{entity.ErrInvalidInput.Error()})
+		renderError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	id, err := h.service.CreatePerson(r.Context(), entity.Person{
+	person, existed, err := h.service.CreatePerson(r.Context(), entity.Person{
 		Name:       input.Name,
 		Surname:    input.Surname,
 		Patronymic: input.Patronymic,
-	})
+	}, service.DuplicatePolicy(input.OnDuplicate))
 
 	if err != nil {
-		renderJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+		var dupErr *entity.DuplicateError
+		if errors.As(err, &dupErr) {
+			renderJSON(w, r, http.StatusConflict, errorResponse{Err: dupErr.Error(), Duplicates: dupErr.IDs})
+		} else {
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		}
 		return
 	}
 
-	renderJSON(w, r, http.StatusCreated, successResponse{fmt.Sprintf("created new person ID: %d", id)})
+	if existed {
+		renderJSON(w, r, http.StatusOK, getPersonResponse{Person: person})
+		return
+	}
+
+	w.Header().Set("Location", personLocation(person.ID))
+	if isLegacy(r) {
+		renderJSON(w, r, http.StatusCreated, createPersonResponse{
+			Message:             fmt.Sprintf("created new person ID: %d", person.ID),
+			PossibleDuplicateOf: person.PossibleDuplicateOf,
+		})
+		return
+	}
+
+	renderJSON(w, r, http.StatusCreated, getPersonResponse{Person: person})
 }
 
 // @Summary Update persons
-// @Description update person by id
+// @Description update person by id and return its updated representation. Send Accept: application/vnd.persons.v1+json to get the legacy message response
 // @Tags persons
 // @Accept json
 // @Produce json
 // @Param id path int true "id"
 // @Param input body updatePersonInput true "updating params"
-// @Success 202 {object} successResponse
+// @Success 200 {object} updatedPersonResponse
 // @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/v1/persons/{id} [patch]
 func (h *Handler) updatePerson(w http.ResponseWriter, r *http.Request) {
 	var input updatePersonInput
-	var err error
-	if err = input.Set(r); err != nil {
-		renderJSON(w, r, http.StatusBadRequest, errorResponse{err.Error()})
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	var data service.UpdateParams
-	if input.Name != "" {
-		data.Name = &input.Name
-	}
-
-	if input.Surname != "" {
-		data.Surname = &input.Surname
-	}
-
-	if input.Patronymic != "" {
-		data.Patronymic = &input.Patronymic
+	data := service.UpdateParams{
+		Name:        input.Name,
+		Surname:     input.Surname,
+		Patronymic:  input.Patronymic,
+		Age:         input.Age,
+		Gender:      input.Gender,
+		Nationalize: input.Nationalize,
 	}
 
-	err = h.service.Update(r.Context(), input.ID, &data)
+	result, err := h.service.Update(r.Context(), input.ID, &data)
 	if err != nil {
-		if errors.Is(err, entity.ErrPersonNotExists) {
-			renderJSON(w, r, http.StatusBadRequest, errorResponse{entity.ErrPersonNotExists.Error()})
-		} else {
-			renderJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+		switch {
+		case errors.Is(err, entity.ErrPersonNotExists):
+			renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: entity.ErrPersonNotExists.Error()})
+		case errors.Is(err, entity.ErrNameChanged):
+			renderJSON(w, r, http.StatusConflict, errorResponse{Err: entity.ErrNameChanged.Error()})
+		default:
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
 		}
 		return
 	}
 
-	renderJSON(w, r, http.StatusAccepted, successResponse{Message: "person updated successfully"})
+	if isLegacy(r) {
+		renderJSON(w, r, http.StatusAccepted, updatePersonResponse{
+			Message:    "person updated successfully",
+			Recomputed: result.Recomputed,
+			Scheduled:  result.Scheduled,
+		})
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, updatedPersonResponse{
+		Person:     result.Person,
+		Recomputed: result.Recomputed,
+		Scheduled:  result.Scheduled,
+	})
 }
 
 // @Summary Delete person
@@ -99,18 +134,83 @@ func (h *Handler) updatePerson(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) deletePerson(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(mux.Vars(r)["id"])
 	if id == 0 {
-		renderJSON(w, r, http.StatusBadRequest, errorResponse{entity.ErrInvalidInput.Error()})
+		renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: entity.ErrInvalidInput.Error()})
 		return
 	}
 
 	if err := h.service.Delete(r.Context(), id); err != nil {
 		if errors.Is(err, entity.ErrPersonNotExists) {
-			renderJSON(w, r, http.StatusBadRequest, errorResponse{entity.ErrPersonNotExists.Error()})
+			renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: entity.ErrPersonNotExists.Error()})
 		} else {
-			renderJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
 		}
 		return
 	}
 
 	renderJSON(w, r, http.StatusOK, successResponse{Message: "person deleted succesfully"})
 }
+
+// @Summary Merge persons
+// @Description Merge source person into the person by id. Nationalities are combined, the source is soft-deleted and redirects to the target
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Param id path int true "target id"
+// @Param input body mergePersonsInput true "source id and field resolution rules: keep_target, keep_source or newest"
+// @Success 200 {object} getPersonResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/persons/{id}/merge [post]
+func (h *Handler) mergePersons(w http.ResponseWriter, r *http.Request) {
+	var input mergePersonsInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	person, err := h.service.Merge(r.Context(), input.TargetID, input.params())
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrPersonNotExists):
+			renderJSON(w, r, http.StatusNotFound, errorResponse{Err: entity.ErrPersonNotExists.Error()})
+		case errors.Is(err, entity.ErrInvalidInput):
+			renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: entity.ErrInvalidInput.Error()})
+		default:
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		}
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, getPersonResponse{Person: person})
+}
+
+// @Summary Preview enrichment
+// @Description Return age, gender and nationalities the providers give for the normalized name. Nothing is stored
+// @Tags persons
+// @Produce json
+// @Param name query string true "name"
+// @Success 200 {object} enrichmentResponse
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/enrichment [get]
+func (h *Handler) previewEnrichment(w http.ResponseWriter, r *http.Request) {
+	var input previewEnrichmentInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	person, err := h.service.PreviewEnrichment(r.Context(), input.Name)
+	if err != nil {
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, enrichmentResponse{
+		Name:        person.Name,
+		Age:         person.Age,
+		Gender:      person.Gender,
+		Nationalize: person.Nationalize,
+	})
+}
@@ -2,14 +2,18 @@ package transport
 
 import (
 	"bytes"
-	"database/sql"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/normalize"
+	"github.com/pintoter/persons/services/command/internal/audit"
 	"github.com/pintoter/persons/services/command/internal/entity"
 	"github.com/pintoter/persons/services/command/internal/service"
 	mock_service "github.com/pintoter/persons/services/command/internal/service/mocks"
@@ -17,15 +21,43 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+type normalizationConfig struct{}
+
+func (normalizationConfig) IsEnabled() bool        { return true }
+func (normalizationConfig) ShouldCapitalize() bool { return true }
+func (normalizationConfig) GetLanguage() string    { return "en" }
+func (normalizationConfig) GetParticles() []string { return nil }
+
+type duplicatesConfig struct {
+	policy    string
+	fuzzy     bool
+	threshold float64
+}
+
+func (c duplicatesConfig) GetPolicy() string     { return c.policy }
+func (c duplicatesConfig) IsFuzzy() bool         { return c.fuzzy }
+func (c duplicatesConfig) GetThreshold() float64 { return c.threshold }
+
+type enrichmentConfig struct {
+	mode string
+}
+
+func (c enrichmentConfig) GetReEnrichMode() string           { return c.mode }
+func (c enrichmentConfig) GetReEnrichTimeout() time.Duration { return time.Second }
+
 func Test_CreatePersonHandler(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockRepository, b *mock_service.MockGenerator, person entity.Person)
 
 	tests := []struct {
 		name                 string
+		query                string
+		accept               string
+		duplicates           duplicatesConfig
 		inputBody            string
 		inputPerson          entity.Person
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
+		expectedLocation     string
 		expectedResponseBody string
 	}{
 		{
@@ -51,18 +83,296 @@ func Test_CreatePersonHandler(t *testing.T) {
 						Probability: 0.05,
 					},
 				},
+				Original: &entity.FullName{
+					Name:       "Ivan",
+					Surname:    "Ivanov",
+					Patronymic: "Ivanovich",
+				},
 			},
 			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}, false).Return(nil, nil)
 				g.EXPECT().GenerateAge(gomock.Any(), person.Name).Times(1).Return(18, nil)
 				g.EXPECT().GenerateGender(gomock.Any(), person.Name).Times(1).Return("male", nil)
 				g.EXPECT().
 					GenerateNationalize(gomock.Any(), person.Name).Times(1).
 					Return([]entity.Nationality{{Country: "RU", Probability: 0.1}, {Country: "KZ", Probability: 0.05}}, nil)
-				r.EXPECT().Create(gomock.Any(), person).Return(1, nil)
+				r.EXPECT().Create(gomock.Any(), person, gomock.Any()).Return(1, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedLocation:   "/api/v1/persons/1",
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{
+					ID:          1,
+					Name:        "Ivan",
+					Surname:     "Ivanov",
+					Patronymic:  "Ivanovich",
+					Age:         18,
+					Gender:      "male",
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}, {Country: "KZ", Probability: 0.05}},
+					Original:    &entity.FullName{Name: "Ivan", Surname: "Ivanov", Patronymic: "Ivanovich"},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "SuccessLegacy",
+			accept:    legacyMediaType,
+			inputBody: `{"name": "Ivan", "surname": "Ivanov"}`,
+			inputPerson: entity.Person{
+				Name:        "Ivan",
+				Surname:     "Ivanov",
+				Age:         18,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}},
+				Original:    &entity.FullName{Name: "Ivan", Surname: "Ivanov"},
+			},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).Return(nil, nil)
+				g.EXPECT().GenerateAge(gomock.Any(), person.Name).Return(18, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), person.Name).Return("male", nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), person.Name).Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+				r.EXPECT().Create(gomock.Any(), person, gomock.Any()).Return(1, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedLocation:   "/api/v1/persons/1",
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(createPersonResponse{Message: "created new person ID: 1"}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "SuccessWithNormalization",
+			inputBody: `{
+					"name": " IVAN ",
+					"surname": "o'neil",
+					"patronymic": "rimsky-korsakov"
+				}`,
+			inputPerson: entity.Person{
+				Name:       "Ivan",
+				Surname:    "O'Neil",
+				Patronymic: "Rimsky-Korsakov",
+				Age:        18,
+				Gender:     "male",
+				Nationalize: []entity.Nationality{
+					{
+						Country:     "RU",
+						Probability: 0.1,
+					},
+				},
+				Original: &entity.FullName{
+					Name:       " IVAN ",
+					Surname:    "o'neil",
+					Patronymic: "rimsky-korsakov",
+				},
+			},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: person.Name, Surname: person.Surname, Patronymic: person.Patronymic}, false).Return(nil, nil)
+				g.EXPECT().GenerateAge(gomock.Any(), person.Name).Times(1).Return(18, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), person.Name).Times(1).Return("male", nil)
+				g.EXPECT().
+					GenerateNationalize(gomock.Any(), person.Name).Times(1).
+					Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+				r.EXPECT().Create(gomock.Any(), person, gomock.Any()).Return(2, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedLocation:   "/api/v1/persons/2",
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{
+					ID:          2,
+					Name:        "Ivan",
+					Surname:     "O'Neil",
+					Patronymic:  "Rimsky-Korsakov",
+					Age:         18,
+					Gender:      "male",
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}},
+					Original:    &entity.FullName{Name: " IVAN ", Surname: "o'neil", Patronymic: "rimsky-korsakov"},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "SuccessWithPossibleDuplicate",
+			inputBody: `{"name": "Ivan", "surname": "Ivanov"}`,
+			inputPerson: entity.Person{
+				Name:        "Ivan",
+				Surname:     "Ivanov",
+				Age:         18,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}},
+				Original:    &entity.FullName{Name: "Ivan", Surname: "Ivanov"},
+			},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).
+					Return([]entity.Person{{ID: 3, Name: "Ivan", Surname: "Ivanov"}}, nil)
+				g.EXPECT().GenerateAge(gomock.Any(), person.Name).Return(18, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), person.Name).Return("male", nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), person.Name).Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+				duplicateOf := 3
+				person.PossibleDuplicateOf = &duplicateOf
+				r.EXPECT().Create(gomock.Any(), person, gomock.Any()).Return(4, nil)
 			},
 			expectedStatusCode: http.StatusCreated,
+			expectedLocation:   "/api/v1/persons/4",
+			expectedResponseBody: func() string {
+				duplicateOf := 3
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{
+					ID:                  4,
+					Name:                "Ivan",
+					Surname:             "Ivanov",
+					Age:                 18,
+					Gender:              "male",
+					Nationalize:         []entity.Nationality{{Country: "RU", Probability: 0.1}},
+					Original:            &entity.FullName{Name: "Ivan", Surname: "Ivanov"},
+					PossibleDuplicateOf: &duplicateOf,
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:       "FailedWithDuplicateRejected",
+			query:      "?on_duplicate=reject",
+			inputBody:  `{"name": "ivan", "surname": "ivanov"}`,
+			duplicates: duplicatesConfig{policy: "create"},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).
+					Return([]entity.Person{{ID: 3}, {ID: 7}}, nil)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrPersonDuplicate.Error(), Duplicates: []int{3, 7}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:       "FailedWithDuplicateCreatedConcurrently",
+			query:      "?on_duplicate=reject",
+			inputBody:  `{"name": "Ivan", "surname": "Ivanov"}`,
+			duplicates: duplicatesConfig{policy: "create"},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanov"}, false).Return(nil, nil)
+				g.EXPECT().GenerateAge(gomock.Any(), "Ivan").Return(18, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), "Ivan").Return("male", nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), "Ivan").Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil)
+				r.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ entity.Person, check *service.DuplicateCheck) (int, error) {
+						_, err := check.Resolve([]entity.Person{{ID: 9, Name: "Ivan", Surname: "Ivanov"}})
+						return 0, err
+					})
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrPersonDuplicate.Error(), Duplicates: []int{9}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:       "SuccessWithFuzzyExisting",
+			inputBody:  `{"name": "Ivan", "surname": "Ivanof"}`,
+			duplicates: duplicatesConfig{policy: "return_existing", fuzzy: true, threshold: 0.8},
+			mockBehavior: func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {
+				r.EXPECT().FindDuplicates(gomock.Any(), entity.FullName{Name: "Ivan", Surname: "Ivanof"}, true).
+					Return([]entity.Person{
+						{ID: 3, Name: "Igor", Surname: "Ivanov"},
+						{ID: 5, Name: "Ivan", Surname: "Ivanov"},
+					}, nil)
+				r.EXPECT().Get(gomock.Any(), 5).Return(entity.Person{ID: 5, Name: "Ivan", Surname: "Ivanov", Age: 40, Gender: "male"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{ID: 5, Name: "Ivan", Surname: "Ivanov", Age: 40, Gender: "male"}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithUnknownPolicy",
+			query:              "?on_duplicate=merge",
+			inputBody:          `{"name": "Ivan", "surname": "Ivanov"}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "on_duplicate", Code: codeInvalidValue, Message: "on_duplicate must be one of: reject, return_existing, create"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithEmptyName",
+			inputBody:          `{"name": "", "surname": "Ivanov"}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "name", Code: codeRequired, Message: "name is required"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidFields",
+			inputBody:          `{"name": "I", "surname": "Ivanov1", "patronymic": "` + strings.Repeat("a", 65) + `"}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "name", Code: codeTooShort, Message: "name must be at least 2 characters"},
+						{Field: "surname", Code: codeInvalidCharacters, Message: "surname may contain only letters, spaces, hyphens and apostrophes"},
+						{Field: "patronymic", Code: codeTooLong, Message: "patronymic must be at most 64 characters"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithPaddedTooLongName",
+			inputBody:          `{"name": "  ` + strings.Repeat("a", 63) + `  ", "surname": "Ivanov"}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "name", Code: codeTooLong, Message: "name must be at most 64 characters"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithUnknownField",
+			inputBody:          `{"name": "Ivan", "surname": "Ivanov", "age": 18}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
 			expectedResponseBody: func() string {
-				resp, _ := json.MarshalIndent(successResponse{Message: "created new person ID: 1"}, "", "    ")
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "age", Code: codeUnknownField, Message: "age is not allowed"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidType",
+			inputBody:          `{"name": 1, "surname": "Ivanov"}`,
+			mockBehavior:       func(r *mock_service.MockRepository, g *mock_service.MockGenerator, person entity.Person) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "name", Code: codeInvalidType, Message: "name must be string"},
+					},
+				}, "", "    ")
 				return string(resp)
 			}(),
 		},
@@ -77,17 +387,24 @@ func Test_CreatePersonHandler(t *testing.T) {
 			gen := mock_service.NewMockGenerator(c)
 			tt.mockBehavior(repo, gen, tt.inputPerson)
 
-			service := service.New(repo, gen)
+			if tt.duplicates.policy == "" {
+				tt.duplicates.policy = "create"
+			}
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), tt.duplicates, enrichmentConfig{mode: "inline"})
 
 			handler := NewHandler(service)
 
 			// Create request
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("POST", "/api/v1/persons", bytes.NewBufferString(tt.inputBody))
+			r := httptest.NewRequest("POST", "/api/v1/persons"+tt.query, bytes.NewBufferString(tt.inputBody))
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
 
 			handler.ServeHTTP(w, r)
 
 			assert.Equal(t, w.Code, tt.expectedStatusCode)
+			assert.Equal(t, w.Header().Get("Location"), tt.expectedLocation)
 			assert.Equal(t, w.Body.String(), tt.expectedResponseBody)
 		})
 	}
@@ -129,7 +446,7 @@ func Test_Delete(t *testing.T) {
 			name: "FailedWithNoPerson",
 			id:   5,
 			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
-				s.EXPECT().Delete(gomock.Any(), id).Return(entity.Person{}, sql.ErrNoRows)
+				s.EXPECT().Delete(gomock.Any(), id).Return(entity.ErrPersonNotExists)
 			},
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponseBody: func() string {
@@ -148,7 +465,7 @@ func Test_Delete(t *testing.T) {
 			gen := mock_service.NewMockGenerator(c)
 			tt.mockBehavior(repo, gen, tt.id)
 
-			service := service.New(repo, gen)
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "inline"})
 
 			handler := NewHandler(service)
 
@@ -170,6 +487,7 @@ func Test_Update(t *testing.T) {
 		name                 string
 		id                   int
 		inputBody            string
+		accept               string
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
@@ -177,13 +495,39 @@ func Test_Update(t *testing.T) {
 		{
 			name:      "Ok",
 			id:        1,
-			inputBody: `{"name": "Ivan"}`,
+			inputBody: `{"name": "ivan  "}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				name, original := "Ivan", "ivan  "
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Ivan"}, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Name:         &name,
+					NameOriginal: &original,
+					IfName:       &name,
+				}).Return(entity.Person{ID: id, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(updatedPersonResponse{
+					Person: entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "OkLegacy",
+			id:        1,
+			inputBody: `{"surname": "Petrov"}`,
+			accept:    legacyMediaType,
 			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
-				s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(nil)
+				surname := "Petrov"
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Surname:         &surname,
+					SurnameOriginal: &surname,
+				}).Return(entity.Person{ID: id, Name: "Ivan", Surname: "Petrov"}, nil)
 			},
 			expectedStatusCode: http.StatusAccepted,
 			expectedResponseBody: func() string {
-				resp, _ := json.MarshalIndent(successResponse{Message: "person updated successfully"}, "", "    ")
+				resp, _ := json.MarshalIndent(updatePersonResponse{Message: "person updated successfully"}, "", "    ")
 				return string(resp)
 			}(),
 		},
@@ -204,7 +548,94 @@ func Test_Update(t *testing.T) {
 			id:        5,
 			inputBody: `{"name": "Ivan"}`,
 			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
-				s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(nil)
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{}, entity.ErrPersonNotExists)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrPersonNotExists.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "OkWithNameChanged",
+			id:        1,
+			inputBody: `{"name": "petr", "age": 40}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				name, original, current, age, gender := "Petr", "petr", "Ivan", 40, entity.Male
+				nationalize := []entity.Nationality{{Country: "RU", Probability: 0.9}}
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: current}, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), name).Return(gender, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), name).Return(nationalize, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Name:         &name,
+					NameOriginal: &original,
+					Age:          &age,
+					Gender:       &gender,
+					Nationalize:  nationalize,
+					IfName:       &current,
+				}).Return(entity.Person{ID: id, Name: name, Age: age, Gender: gender, Nationalize: nationalize}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(updatedPersonResponse{
+					Person: entity.Person{
+						ID:          1,
+						Name:        "Petr",
+						Age:         40,
+						Gender:      entity.Male,
+						Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}},
+					},
+					Recomputed: []string{service.FieldGender, service.FieldNationalize},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "OkWithNameChangedMeanwhile",
+			id:        1,
+			inputBody: `{"name": "petr", "age": 40}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				name, original, current, age, gender := "Petr", "petr", "Ivan", 40, entity.Male
+				nationalize := []entity.Nationality{{Country: "RU", Probability: 0.9}}
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: current}, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), name).Return(gender, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), name).Return(nationalize, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Name:         &name,
+					NameOriginal: &original,
+					Age:          &age,
+					Gender:       &gender,
+					Nationalize:  nationalize,
+					IfName:       &current,
+				}).Return(entity.Person{}, entity.ErrNameChanged)
+				// renamed to the same name concurrently, nothing to recompute
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: name}, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Name:         &name,
+					NameOriginal: &original,
+					Age:          &age,
+					IfName:       &name,
+				}).Return(entity.Person{ID: id, Name: name, Age: age}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(updatedPersonResponse{
+					Person: entity.Person{ID: 1, Name: "Petr", Age: 40},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "FailedWithPersonMergedMeanwhile",
+			id:        1,
+			inputBody: `{"name": "petr"}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Ivan"}, nil)
+				g.EXPECT().GenerateAge(gomock.Any(), "Petr").Return(30, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), "Petr").Return(entity.Male, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), "Petr").Return([]entity.Nationality{{Country: "RU", Probability: 0.9}}, nil)
+				// not retried, the person is gone
+				s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(entity.Person{}, entity.ErrPersonNotExists)
 			},
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponseBody: func() string {
@@ -212,6 +643,106 @@ func Test_Update(t *testing.T) {
 				return string(resp)
 			}(),
 		},
+		{
+			name:      "FailedWithNameChangingConcurrently",
+			id:        1,
+			inputBody: `{"name": "petr"}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Petr"}, nil).Times(3)
+				s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(entity.Person{}, entity.ErrNameChanged).Times(3)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrNameChanged.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithEmptyNationalize",
+			id:                 1,
+			inputBody:          `{"nationalize": []}`,
+			mockBehavior:       func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "nationalize", Code: codeRequired, Message: "nationalize must contain at least one country"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidDerivedFields",
+			id:                 1,
+			inputBody:          `{"age": 200, "gender": "other", "nationalize": [{"country_id": "rus", "probability": 2}]}`,
+			mockBehavior:       func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "age", Code: codeInvalidValue, Message: "age must be between 0 and 150"},
+						{Field: "gender", Code: codeInvalidValue, Message: "gender must be one of: male, female"},
+						{Field: "nationalize[0].country_id", Code: codeInvalidValue, Message: "nationalize[0].country_id must be a two-letter upper-case country code"},
+						{Field: "nationalize[0].probability", Code: codeInvalidValue, Message: "nationalize[0].probability must be between 0 and 1"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithEmptyBody",
+			id:                 1,
+			inputBody:          `{}`,
+			mockBehavior:       func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "body", Code: codeEmptyUpdate, Message: "at least one field is required"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "OkWithClearedPatronymic",
+			id:        1,
+			inputBody: `{"patronymic": ""}`,
+			mockBehavior: func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				patronymic := ""
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Patronymic:         &patronymic,
+					PatronymicOriginal: &patronymic,
+				}).Return(entity.Person{ID: id, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(updatedPersonResponse{
+					Person: entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithEmptyName",
+			id:                 1,
+			inputBody:          `{"name": ""}`,
+			mockBehavior:       func(s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "name", Code: codeRequired, Message: "name is required"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
 	}
 
 	for _, tt := range tests {
@@ -223,13 +754,16 @@ func Test_Update(t *testing.T) {
 			gen := mock_service.NewMockGenerator(c)
 			tt.mockBehavior(repo, gen, tt.id)
 
-			service := service.New(repo, gen)
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "inline"})
 
 			handler := NewHandler(service)
 
 			// Create request
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("PATCH", "/api/v1/persons/"+fmt.Sprintf("%d", tt.id), bytes.NewBufferString(tt.inputBody))
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
 
 			handler.ServeHTTP(w, r)
 
@@ -238,3 +772,264 @@ func Test_Update(t *testing.T) {
 		})
 	}
 }
+
+func Test_UpdateBackground(t *testing.T) {
+	type mockBehavior func(t *testing.T, s *mock_service.MockRepository, g *mock_service.MockGenerator, id int)
+
+	tests := []struct {
+		name         string
+		id           int
+		inputBody    string
+		mockBehavior mockBehavior
+	}{
+		{
+			name:      "Stored",
+			id:        1,
+			inputBody: `{"name": "petr", "age": 40}`,
+			mockBehavior: func(t *testing.T, s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				name, original, current, age, gender := "Petr", "petr", "Ivan", 40, entity.Male
+				nationalize := []entity.Nationality{{Country: "RU", Probability: 0.9}}
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: current}, nil)
+				stored := s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Name:         &name,
+					NameOriginal: &original,
+					Age:          &age,
+					IfName:       &current,
+				}).Return(entity.Person{ID: id, Name: name, Age: age}, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), name).Return(gender, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), name).Return(nationalize, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Gender:      &gender,
+					Nationalize: nationalize,
+					IfName:      &name,
+				}).DoAndReturn(func(ctx context.Context, id int, params *service.UpdateParams) (entity.Person, error) {
+					assert.Equal(t, audit.ActionEnrich, audit.ActionFrom(ctx, audit.ActionUpdate))
+					return entity.Person{ID: id, Name: name, Age: age, Gender: gender, Nationalize: nationalize}, nil
+				}).After(stored)
+			},
+		},
+		{
+			name:      "DroppedWhenEnrichmentFails",
+			id:        1,
+			inputBody: `{"name": "petr", "age": 40, "gender": "male"}`,
+			mockBehavior: func(t *testing.T, s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Ivan"}, nil)
+				s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(entity.Person{ID: id, Name: "Petr", Age: 40}, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), "Petr").Return(nil, entity.ErrInternalService)
+			},
+		},
+		{
+			name:      "DroppedWhenRenamedMeanwhile",
+			id:        1,
+			inputBody: `{"name": "petr", "age": 40, "gender": "male"}`,
+			mockBehavior: func(t *testing.T, s *mock_service.MockRepository, g *mock_service.MockGenerator, id int) {
+				name := "Petr"
+				nationalize := []entity.Nationality{{Country: "RU", Probability: 0.9}}
+				s.EXPECT().Get(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Ivan"}, nil)
+				stored := s.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(entity.Person{ID: id, Name: name, Age: 40}, nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), name).Return(nationalize, nil)
+				s.EXPECT().Update(gomock.Any(), id, &service.UpdateParams{
+					Nationalize: nationalize,
+					IfName:      &name,
+				}).Return(entity.Person{}, entity.ErrNameChanged).After(stored)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockRepository(c)
+			gen := mock_service.NewMockGenerator(c)
+			tt.mockBehavior(t, repo, gen, tt.id)
+
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "background"})
+
+			handler := NewHandler(service)
+
+			// Create request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("PATCH", "/api/v1/persons/"+fmt.Sprintf("%d", tt.id), bytes.NewBufferString(tt.inputBody))
+
+			handler.ServeHTTP(w, r)
+			service.Wait()
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var resp updatedPersonResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.NotEmpty(t, resp.Scheduled)
+			assert.Empty(t, resp.Recomputed)
+		})
+	}
+}
+
+func Test_Merge(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockRepository, id int)
+
+	tests := []struct {
+		name                 string
+		id                   int
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "Ok",
+			id:        1,
+			inputBody: `{"source_id": 2, "rules": {"surname": "keep_source", "age": "newest"}}`,
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().Merge(gomock.Any(), id, &service.MergeParams{
+					SourceID:   2,
+					Name:       service.MergeKeepTarget,
+					Surname:    service.MergeKeepSource,
+					Patronymic: service.MergeKeepTarget,
+					Age:        service.MergeNewest,
+					Gender:     service.MergeKeepTarget,
+				}).Return(entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanoff", Age: 31, Gender: "male"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanoff", Age: 31, Gender: "male"}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:      "FailedNotExists",
+			id:        1,
+			inputBody: `{"source_id": 2}`,
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().Merge(gomock.Any(), id, gomock.Any()).Return(entity.Person{}, entity.ErrPersonNotExists)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrPersonNotExists.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidRules",
+			id:                 1,
+			inputBody:          `{"source_id": 1, "rules": {"nationalize": "keep_source", "name": "oldest"}}`,
+			mockBehavior:       func(s *mock_service.MockRepository, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "source_id", Code: codeInvalidValue, Message: "source_id must differ from the merge target"},
+						{Field: "rules.name", Code: codeInvalidValue, Message: "rules.name must be one of: keep_target, keep_source, newest"},
+						{Field: "rules.nationalize", Code: codeUnknownField, Message: "nationalize can't be merged"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockRepository(c)
+			gen := mock_service.NewMockGenerator(c)
+			tt.mockBehavior(repo, tt.id)
+
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "inline"})
+
+			handler := NewHandler(service)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/persons/%d/merge", tt.id), bytes.NewBufferString(tt.inputBody))
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func Test_PreviewEnrichment(t *testing.T) {
+	type mockBehavior func(g *mock_service.MockGenerator)
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Ok",
+			query: "?name=%20ivan",
+			mockBehavior: func(g *mock_service.MockGenerator) {
+				g.EXPECT().GenerateAge(gomock.Any(), "Ivan").Return(42, nil)
+				g.EXPECT().GenerateGender(gomock.Any(), "Ivan").Return("male", nil)
+				g.EXPECT().GenerateNationalize(gomock.Any(), "Ivan").Return([]entity.Nationality{{Country: "RU", Probability: 0.4}}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(enrichmentResponse{
+					Name:        "Ivan",
+					Age:         42,
+					Gender:      "male",
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.4}},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:  "FailedProvider",
+			query: "?name=Ivan",
+			mockBehavior: func(g *mock_service.MockGenerator) {
+				g.EXPECT().GenerateAge(gomock.Any(), "Ivan").Return(0, context.DeadlineExceeded).AnyTimes()
+				g.EXPECT().GenerateGender(gomock.Any(), "Ivan").Return("male", nil).AnyTimes()
+				g.EXPECT().GenerateNationalize(gomock.Any(), "Ivan").Return([]entity.Nationality{{Country: "RU", Probability: 0.1}}, nil).AnyTimes()
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrInvalidInput.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithoutName",
+			mockBehavior:       func(g *mock_service.MockGenerator) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err:        entity.ErrInvalidInput.Error(),
+					Violations: []violation{{Field: "name", Code: codeRequired, Message: "name is required"}},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockRepository(c)
+			gen := mock_service.NewMockGenerator(c)
+			tt.mockBehavior(gen)
+
+			service := service.New(repo, gen, normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "inline"})
+
+			handler := NewHandler(service)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/api/v1/enrichment"+tt.query, nil)
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -1,35 +1,57 @@
 package transport
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/gorilla/mux"
 
 	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/events"
+	"github.com/pintoter/persons/services/command/internal/service"
 )
 
 type createPersonInput struct {
-	Name       string `json:"name" binding:"required,min=2,max=64"`
-	Surname    string `json:"surname" binding:"required,min=2,max=64"`
-	Patronymic string `json:"patronymic,omitempty"`
+	Name        string `json:"name"`
+	Surname     string `json:"surname"`
+	Patronymic  string `json:"patronymic,omitempty"`
+	OnDuplicate string `json:"-"`
 }
 
 func (p *createPersonInput) Set(r *http.Request) error {
-	var err error
-	if err = json.NewDecoder(r.Body).Decode(p); err != nil {
-		return entity.ErrInvalidInput
+	if err := decodeJSON(r, p); err != nil {
+		return err
 	}
+	p.OnDuplicate = r.URL.Query().Get("on_duplicate")
 
-	return nil
+	return p.validate()
+}
+
+func (p *createPersonInput) validate() error {
+	var v validator
+	v.requiredName("name", p.Name)
+	v.requiredName("surname", p.Surname)
+	if p.Patronymic != "" {
+		v.name("patronymic", p.Patronymic)
+	}
+	if p.OnDuplicate != "" {
+		v.oneOf("on_duplicate", p.OnDuplicate,
+			string(service.DuplicateReject), string(service.DuplicateReturnExisting), string(service.DuplicateCreate))
+	}
+	return v.err()
 }
 
 type updatePersonInput struct {
-	ID         int    `json:"-"`
-	Name       string `json:"name,omitempty"`
-	Surname    string `json:"surname,omitempty"`
-	Patronymic string `json:"patronymic,omitempty"`
+	ID         int     `json:"-"`
+	Name       *string `json:"name,omitempty"`
+	Surname    *string `json:"surname,omitempty"`
+	Patronymic *string `json:"patronymic,omitempty"`
+
+	Age         *int                 `json:"age,omitempty"`
+	Gender      *string              `json:"gender,omitempty"`
+	Nationalize []entity.Nationality `json:"nationalize,omitempty"`
 }
 
 func (p *updatePersonInput) Set(r *http.Request) error {
@@ -38,13 +60,202 @@ func (p *updatePersonInput) Set(r *http.Request) error {
 		return entity.ErrInvalidQueryId
 	}
 
-	err := json.NewDecoder(r.Body).Decode(p)
-	if err != nil {
-		return entity.ErrInvalidInput
+	if err := decodeJSON(r, p); err != nil {
+		return err
+	}
+
+	return p.validate()
+}
+
+func (p *updatePersonInput) validate() error {
+	var v validator
+	if p.Name == nil && p.Surname == nil && p.Patronymic == nil &&
+		p.Age == nil && p.Gender == nil && p.Nationalize == nil {
+		v.add("body", codeEmptyUpdate, "at least one field is required")
+		return v.err()
+	}
+
+	if p.Name != nil {
+		v.requiredName("name", *p.Name)
+	}
+	if p.Surname != nil {
+		v.requiredName("surname", *p.Surname)
+	}
+	// an empty patronymic clears it
+	if p.Patronymic != nil && *p.Patronymic != "" {
+		v.name("patronymic", *p.Patronymic)
+	}
+	if p.Age != nil {
+		v.intRange("age", *p.Age, minAge, maxAge)
 	}
+	if p.Gender != nil {
+		v.oneOf("gender", *p.Gender, genders...)
+	}
+	if p.Nationalize != nil && len(p.Nationalize) == 0 {
+		v.add("nationalize", codeRequired, "nationalize must contain at least one country")
+	}
+	for i, n := range p.Nationalize {
+		v.nationality(fmt.Sprintf("nationalize[%d]", i), n)
+	}
+	return v.err()
+}
+
+type previewEnrichmentInput struct {
+	Name string
+}
+
+func (p *previewEnrichmentInput) Set(r *http.Request) error {
+	p.Name = r.URL.Query().Get("name")
+
+	var v validator
+	v.requiredName("name", p.Name)
+	return v.err()
+}
+
+type mergePersonsInput struct {
+	TargetID int               `json:"-"`
+	SourceID int               `json:"source_id"`
+	Rules    map[string]string `json:"rules,omitempty"`
+}
+
+var mergeFields = []string{"name", "surname", "patronymic", "age", "gender"}
+
+func (p *mergePersonsInput) Set(r *http.Request) error {
+	p.TargetID, _ = strconv.Atoi(mux.Vars(r)["id"])
+	if p.TargetID == 0 {
+		return entity.ErrInvalidQueryId
+	}
+
+	if err := decodeJSON(r, p); err != nil {
+		return err
+	}
+
+	return p.validate()
+}
+
+func (p *mergePersonsInput) validate() error {
+	var v validator
+	switch {
+	case p.SourceID <= 0:
+		v.add("source_id", codeRequired, "source_id must be a positive person ID")
+	case p.SourceID == p.TargetID:
+		v.add("source_id", codeInvalidValue, "source_id must differ from the merge target")
+	}
+
+	for _, field := range sortedKeys(p.Rules) {
+		if !slices.Contains(mergeFields, field) {
+			v.add("rules."+field, codeUnknownField, fmt.Sprintf("%s can't be merged", field))
+			continue
+		}
+		v.oneOf("rules."+field, p.Rules[field],
+			string(service.MergeKeepTarget), string(service.MergeKeepSource), string(service.MergeNewest))
+	}
+	return v.err()
+}
 
-	if p.Name == "" && p.Surname == "" && p.Patronymic == "" {
-		return entity.ErrInvalidInput
+func (p *mergePersonsInput) params() *service.MergeParams {
+	strategy := func(field string) service.MergeStrategy {
+		if rule, ok := p.Rules[field]; ok {
+			return service.MergeStrategy(rule)
+		}
+		return service.MergeKeepTarget
+	}
+
+	return &service.MergeParams{
+		SourceID:   p.SourceID,
+		Name:       strategy("name"),
+		Surname:    strategy("surname"),
+		Patronymic: strategy("patronymic"),
+		Age:        strategy("age"),
+		Gender:     strategy("gender"),
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+type createWebhookInput struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+var eventTypes = []string{
+	string(events.PersonCreated), string(events.PersonUpdated), string(events.PersonEnriched), string(events.PersonDeleted),
+}
+
+func (p *createWebhookInput) Set(r *http.Request) error {
+	if err := decodeJSON(r, p); err != nil {
+		return err
+	}
+
+	return p.validate()
+}
+
+func (p *createWebhookInput) validate() error {
+	var v validator
+	v.webhookURL("url", p.URL)
+	for i, eventType := range p.EventTypes {
+		v.oneOf(fmt.Sprintf("event_types[%d]", i), eventType, eventTypes...)
+	}
+	if p.Secret != "" && len(p.Secret) < minSecretLength {
+		v.add("secret", codeTooShort, fmt.Sprintf("secret must be at least %d characters", minSecretLength))
+	}
+	return v.err()
+}
+
+type getDeliveriesInput struct {
+	WebhookID int
+	Status    string
+	Limit     int
+	Page      int
+}
+
+var deliveryStatuses = []string{entity.DeliveryPending, entity.DeliveryDelivered, entity.DeliveryDead}
+
+func (p *getDeliveriesInput) Set(r *http.Request) error {
+	p.WebhookID, _ = strconv.Atoi(mux.Vars(r)["id"])
+	if p.WebhookID == 0 {
+		return entity.ErrInvalidQueryId
+	}
+
+	query := r.URL.Query()
+
+	var v validator
+	if query.Has("status") {
+		p.Status = query.Get("status")
+		v.oneOf("status", p.Status, deliveryStatuses...)
+	}
+
+	p.Limit = defaultLimit
+	if query.Has("limit") {
+		p.Limit = v.queryInt("limit", query.Get("limit"), minLimit, maxLimit)
+	}
+
+	p.Page = defaultPage
+	if query.Has("page") {
+		p.Page = v.queryInt("page", query.Get("page"), defaultPage, maxPage)
+	}
+
+	return v.err()
+}
+
+type deliveryInput struct {
+	WebhookID  int
+	DeliveryID int64
+}
+
+func (p *deliveryInput) Set(r *http.Request) error {
+	p.WebhookID, _ = strconv.Atoi(mux.Vars(r)["id"])
+	p.DeliveryID, _ = strconv.ParseInt(mux.Vars(r)["delivery_id"], 10, 64)
+	if p.WebhookID == 0 || p.DeliveryID == 0 {
+		return entity.ErrInvalidQueryId
 	}
 	return nil
 }
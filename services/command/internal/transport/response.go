@@ -2,7 +2,9 @@ package transport
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/pintoter/persons/pkg/logger"
 	"github.com/pintoter/persons/services/command/internal/entity"
@@ -16,12 +18,75 @@ type getPersonsResponse struct {
 	Persons []entity.Person `json:"persons"`
 }
 
+type updatedPersonResponse struct {
+	Person     entity.Person `json:"person"`
+	Recomputed []string      `json:"recomputed,omitempty"`
+	Scheduled  []string      `json:"scheduled,omitempty"`
+}
+
+// enrichmentResponse holds what the providers return for a name
+type enrichmentResponse struct {
+	Name        string               `json:"name"`
+	Age         int                  `json:"age"`
+	Gender      string               `json:"gender"`
+	Nationalize []entity.Nationality `json:"nationalize"`
+}
+
 type successResponse struct {
 	Message string `json:"message"`
 }
 
+// createPersonResponse is returned by create for legacy clients
+type createPersonResponse struct {
+	Message             string `json:"message"`
+	PossibleDuplicateOf *int   `json:"possible_duplicate_of,omitempty"`
+}
+
+// updatePersonResponse is returned by update for legacy clients
+type updatePersonResponse struct {
+	Message    string   `json:"message"`
+	Recomputed []string `json:"recomputed,omitempty"`
+	Scheduled  []string `json:"scheduled,omitempty"`
+}
+
+type webhookResponse struct {
+	Webhook entity.Webhook `json:"webhook"`
+}
+
+type webhooksResponse struct {
+	Webhooks []entity.Webhook `json:"webhooks"`
+}
+
+type deliveryResponse struct {
+	Delivery entity.Delivery `json:"delivery"`
+}
+
+type deliveriesResponse struct {
+	Deliveries []entity.Delivery `json:"deliveries"`
+	Limit      int               `json:"limit"`
+	Page       int               `json:"page"`
+}
+
 type errorResponse struct {
-	Err string `json:"error"`
+	Err        string      `json:"error"`
+	Violations []violation `json:"violations,omitempty"`
+	Duplicates []int       `json:"duplicates,omitempty"`
+}
+
+// legacyMediaType selects message-only create and update responses
+// of the first API version
+const legacyMediaType = "application/vnd.persons.v1+json"
+
+func isLegacy(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), legacyMediaType)
+}
+
+func personLocation(id int) string {
+	return fmt.Sprintf("/api/v1/persons/%d", id)
+}
+
+func webhookLocation(id int) string {
+	return fmt.Sprintf("/api/v1/webhooks/%d", id)
 }
 
 func renderJSON(w http.ResponseWriter, r *http.Request, code int, data any) {
@@ -0,0 +1,193 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+const (
+	minNameLength = 2
+	maxNameLength = 64
+
+	minAge = 0
+	maxAge = 150
+
+	maxBodySize = 1 << 16
+
+	minSecretLength = 16
+
+	defaultLimit = 20
+	defaultPage  = 1
+	minLimit     = 1
+	maxLimit     = 100
+	maxPage      = math.MaxInt32
+)
+
+var genders = []string{entity.Male, entity.Female}
+
+const (
+	codeRequired          = "required"
+	codeTooShort          = "too_short"
+	codeTooLong           = "too_long"
+	codeInvalidCharacters = "invalid_characters"
+	codeInvalidType       = "invalid_type"
+	codeInvalidValue      = "invalid_value"
+	codeUnknownField      = "unknown_field"
+	codeMalformedBody     = "malformed_body"
+	codeEmptyUpdate       = "empty_update"
+)
+
+type violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type validationError struct {
+	violations []violation
+}
+
+func (e *validationError) Error() string {
+	return entity.ErrInvalidInput.Error()
+}
+
+func (e *validationError) Is(target error) bool {
+	return target == entity.ErrInvalidInput
+}
+
+type validator struct {
+	violations []violation
+}
+
+func (v *validator) add(field, code, message string) {
+	v.violations = append(v.violations, violation{Field: field, Code: code, Message: message})
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &validationError{violations: v.violations}
+}
+
+func (v *validator) requiredName(field, value string) {
+	if value == "" {
+		v.add(field, codeRequired, fmt.Sprintf("%s is required", field))
+		return
+	}
+	v.name(field, value)
+}
+
+func (v *validator) name(field, value string) {
+	// surrounding spaces don't count towards the minimum, but the as-entered
+	// value is stored, so they count towards the maximum
+	switch {
+	case utf8.RuneCountInString(strings.TrimSpace(value)) < minNameLength:
+		v.add(field, codeTooShort, fmt.Sprintf("%s must be at least %d characters", field, minNameLength))
+		return
+	case utf8.RuneCountInString(value) > maxNameLength:
+		v.add(field, codeTooLong, fmt.Sprintf("%s must be at most %d characters", field, maxNameLength))
+		return
+	}
+
+	for _, r := range value {
+		if !isNameRune(r) {
+			v.add(field, codeInvalidCharacters, fmt.Sprintf("%s may contain only letters, spaces, hyphens and apostrophes", field))
+			return
+		}
+	}
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be one of: %s", field, strings.Join(allowed, ", ")))
+	}
+}
+
+func (v *validator) intRange(field string, value, min, max int) {
+	if value < min || value > max {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be between %d and %d", field, min, max))
+	}
+}
+
+// queryInt parses integer query parameter within range
+func (v *validator) queryInt(field, value string, min, max int) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		v.add(field, codeInvalidType, fmt.Sprintf("%s must be an integer", field))
+		return 0
+	}
+	v.intRange(field, n, min, max)
+	return n
+}
+
+func (v *validator) webhookURL(field, value string) {
+	if value == "" {
+		v.add(field, codeRequired, fmt.Sprintf("%s must not be empty", field))
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be an absolute http or https URL", field))
+	}
+}
+
+func (v *validator) nationality(field string, n entity.Nationality) {
+	if len(n.Country) != 2 || strings.ToUpper(n.Country) != n.Country {
+		v.add(field+".country_id", codeInvalidValue, fmt.Sprintf("%s.country_id must be a two-letter upper-case country code", field))
+	}
+	if math.IsNaN(n.Probability) || n.Probability < 0 || n.Probability > 1 {
+		v.add(field+".probability", codeInvalidValue, fmt.Sprintf("%s.probability must be between 0 and 1", field))
+	}
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) || r == ' ' || r == '-' || r == '\'' || r == '’'
+}
+
+// decodeJSON strictly decodes request body into dst, reporting unknown
+// fields, type mismatches and malformed JSON as violations
+func decodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil {
+		return nil
+	}
+
+	var v validator
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr):
+		v.add(typeErr.Field, codeInvalidType, fmt.Sprintf("%s must be %s", typeErr.Field, typeErr.Type.String()))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		v.add(field, codeUnknownField, fmt.Sprintf("%s is not allowed", field))
+	default:
+		v.add("body", codeMalformedBody, "request body must be a valid JSON object")
+	}
+
+	return v.err()
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	var vErr *validationError
+	if errors.As(err, &vErr) {
+		renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: vErr.Error(), Violations: vErr.violations})
+		return
+	}
+	renderJSON(w, r, code, errorResponse{Err: err.Error()})
+}
@@ -0,0 +1,178 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pintoter/persons/services/command/internal/entity"
+)
+
+// @Summary Create webhook
+// @Description Subscribe URL to person events. Empty event_types subscribes to all events. The secret for
+// @Description X-Webhook-Signature is generated unless given and is returned only in this response
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param input body createWebhookInput true "subscription"
+// @Success 201 {object} webhookResponse
+// @Header 201 {string} Location "/api/v1/webhooks/{id}"
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks [post]
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var input createWebhookInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), entity.Webhook{
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Secret:     input.Secret,
+	})
+	if err != nil {
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		return
+	}
+
+	w.Header().Set("Location", webhookLocation(webhook.ID))
+	renderJSON(w, r, http.StatusCreated, webhookResponse{Webhook: webhook})
+}
+
+// @Summary Get webhooks
+// @Description Get all webhook subscriptions
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} webhooksResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks [get]
+func (h *Handler) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetWebhooks(r.Context())
+	if err != nil {
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, webhooksResponse{Webhooks: webhooks})
+}
+
+// @Summary Delete webhook
+// @Description Delete webhook subscription with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "id"
+// @Success 200 {object} successResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if id == 0 {
+		renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: entity.ErrInvalidQueryId.Error()})
+		return
+	}
+
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		renderWebhookError(w, r, err)
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, successResponse{Message: "webhook deleted successfully"})
+}
+
+// @Summary Get webhook deliveries
+// @Description Get delivery log of webhook, the newest first. status=dead lists deliveries which ran out of attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "id"
+// @Param status query string false "delivery status" Enums(pending, delivered, dead)
+// @Param limit query int false "limit"
+// @Param page query int false "page"
+// @Success 200 {object} deliveriesResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) getDeliveries(w http.ResponseWriter, r *http.Request) {
+	var input getDeliveriesInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	limit := int64(input.Limit)
+	deliveries, err := h.service.GetDeliveries(r.Context(), input.WebhookID, input.Status, limit, (int64(input.Page)-1)*limit)
+	if err != nil {
+		renderWebhookError(w, r, err)
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, deliveriesResponse{Deliveries: deliveries, Limit: input.Limit, Page: input.Page})
+}
+
+// @Summary Get webhook delivery
+// @Description Get delivery with the log of its attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "id"
+// @Param delivery_id path int true "delivery id"
+// @Success 200 {object} deliveryResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id} [get]
+func (h *Handler) getDelivery(w http.ResponseWriter, r *http.Request) {
+	var input deliveryInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(r.Context(), input.WebhookID, input.DeliveryID)
+	if err != nil {
+		renderWebhookError(w, r, err)
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, deliveryResponse{Delivery: delivery})
+}
+
+// @Summary Redeliver webhook delivery
+// @Description Queue delivery again with a fresh set of attempts
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "id"
+// @Param delivery_id path int true "delivery id"
+// @Success 202 {object} deliveryResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *Handler) redeliver(w http.ResponseWriter, r *http.Request) {
+	var input deliveryInput
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), input.WebhookID, input.DeliveryID)
+	if err != nil {
+		renderWebhookError(w, r, err)
+		return
+	}
+
+	renderJSON(w, r, http.StatusAccepted, deliveryResponse{Delivery: delivery})
+}
+
+func renderWebhookError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, entity.ErrWebhookNotExists), errors.Is(err, entity.ErrDeliveryNotExists):
+		renderJSON(w, r, http.StatusNotFound, errorResponse{Err: err.Error()})
+	default:
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+	}
+}
@@ -0,0 +1,249 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/normalize"
+	"github.com/pintoter/persons/services/command/internal/entity"
+	"github.com/pintoter/persons/services/command/internal/service"
+	mock_service "github.com/pintoter/persons/services/command/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookCreatedAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newWebhookHandler(t *testing.T, mockBehavior func(r *mock_service.MockRepository)) http.Handler {
+	c := gomock.NewController(t)
+	t.Cleanup(c.Finish)
+
+	repo := mock_service.NewMockRepository(c)
+	mockBehavior(repo)
+
+	service := service.New(repo, mock_service.NewMockGenerator(c), normalize.New(normalizationConfig{}), duplicatesConfig{policy: "create"}, enrichmentConfig{mode: "inline"})
+	return NewHandler(service)
+}
+
+func Test_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name                 string
+		inputBody            string
+		mockBehavior         func(r *mock_service.MockRepository)
+		expectedStatusCode   int
+		expectedLocation     string
+		expectedResponseBody string
+	}{
+		{
+			name:      "Success",
+			inputBody: `{"url": "http://localhost:9090/hook", "event_types": ["PersonCreated"], "secret": "0123456789abcdef"}`,
+			mockBehavior: func(r *mock_service.MockRepository) {
+				webhook := entity.Webhook{URL: "http://localhost:9090/hook", EventTypes: []string{"PersonCreated"}, Secret: "0123456789abcdef"}
+				created := webhook
+				created.ID, created.CreatedAt = 1, webhookCreatedAt
+				r.EXPECT().CreateWebhook(gomock.Any(), webhook).Return(created, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedLocation:   "/api/v1/webhooks/1",
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(webhookResponse{Webhook: entity.Webhook{
+					ID:         1,
+					URL:        "http://localhost:9090/hook",
+					EventTypes: []string{"PersonCreated"},
+					Secret:     "0123456789abcdef",
+					CreatedAt:  webhookCreatedAt,
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInput",
+			inputBody:          `{"url": "localhost/hook", "event_types": ["PersonArchived"], "secret": "short"}`,
+			mockBehavior:       func(r *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrInvalidInput.Error(), Violations: []violation{
+					{Field: "url", Code: codeInvalidValue, Message: "url must be an absolute http or https URL"},
+					{Field: "event_types[0]", Code: codeInvalidValue, Message: "event_types[0] must be one of: PersonCreated, PersonUpdated, PersonEnriched, PersonDeleted"},
+					{Field: "secret", Code: codeTooShort, Message: "secret must be at least 16 characters"},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newWebhookHandler(t, tt.mockBehavior)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(tt.inputBody))
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func Test_CreateWebhookGeneratesSecret(t *testing.T) {
+	handler := newWebhookHandler(t, func(r *mock_service.MockRepository) {
+		r.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ any, webhook entity.Webhook) (entity.Webhook, error) {
+				webhook.ID = 1
+				return webhook, nil
+			})
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{"url": "https://example.com/hook"}`))
+
+	handler.ServeHTTP(w, r)
+
+	var resp webhookResponse
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Webhook.Secret, 64)
+	assert.Equal(t, []string{}, resp.Webhook.EventTypes)
+}
+
+func Test_GetDeliveries(t *testing.T) {
+	code := http.StatusServiceUnavailable
+	dead := entity.Delivery{
+		ID:             4,
+		SubscriptionID: 1,
+		EventID:        7,
+		EventType:      "PersonCreated",
+		Status:         entity.DeliveryDead,
+		Attempts:       8,
+		LastStatusCode: &code,
+		LastError:      "unexpected status 503",
+		CreatedAt:      webhookCreatedAt,
+	}
+
+	tests := []struct {
+		name                 string
+		url                  string
+		mockBehavior         func(r *mock_service.MockRepository)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "DeadLetters",
+			url:  "/api/v1/webhooks/1/deliveries?status=dead&limit=10&page=2",
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().GetDeliveries(gomock.Any(), 1, entity.DeliveryDead, int64(10), int64(10)).Return([]entity.Delivery{dead}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(deliveriesResponse{Deliveries: []entity.Delivery{dead}, Limit: 10, Page: 2}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "FailedWithNoWebhook",
+			url:  "/api/v1/webhooks/5/deliveries",
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().GetDeliveries(gomock.Any(), 5, "", int64(20), int64(0)).Return(nil, entity.ErrWebhookNotExists)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrWebhookNotExists.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithStatus",
+			url:                "/api/v1/webhooks/1/deliveries?status=lost",
+			mockBehavior:       func(r *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrInvalidInput.Error(), Violations: []violation{
+					{Field: "status", Code: codeInvalidValue, Message: "status must be one of: pending, delivered, dead"},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newWebhookHandler(t, tt.mockBehavior)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func Test_Redeliver(t *testing.T) {
+	nextAttemptAt := webhookCreatedAt.Add(time.Hour)
+	pending := entity.Delivery{
+		ID:             4,
+		SubscriptionID: 1,
+		EventID:        7,
+		EventType:      "PersonCreated",
+		Status:         entity.DeliveryPending,
+		NextAttemptAt:  &nextAttemptAt,
+		CreatedAt:      webhookCreatedAt,
+	}
+
+	tests := []struct {
+		name                 string
+		url                  string
+		mockBehavior         func(r *mock_service.MockRepository)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "Success",
+			url:  "/api/v1/webhooks/1/deliveries/4/redeliver",
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().Redeliver(gomock.Any(), 1, int64(4)).Return(nil)
+				r.EXPECT().GetDelivery(gomock.Any(), 1, int64(4)).Return(pending, nil)
+			},
+			expectedStatusCode: http.StatusAccepted,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(deliveryResponse{Delivery: pending}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "FailedWithNoDelivery",
+			url:  "/api/v1/webhooks/1/deliveries/5/redeliver",
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().Redeliver(gomock.Any(), 1, int64(5)).Return(entity.ErrDeliveryNotExists)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrDeliveryNotExists.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newWebhookHandler(t, tt.mockBehavior)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tt.url, nil)
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pintoter/persons/pkg/logger"
+)
+
+// maxErrorBody limits how much of a failed response is kept in the delivery
+// log
+const maxErrorBody = 512
+
+// Dispatcher sends due deliveries. A delivery is retried with exponential
+// backoff until a 2xx response or until attempts run out, then it's moved
+// to the dead-letter list. Claimed deliveries are hidden from other
+// dispatchers for twice the request timeout: jobs of a batch are sent
+// concurrently, each within the timeout, the rest leaves room to record the
+// outcomes.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	lease       time.Duration
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	now         func() time.Time
+}
+
+func NewDispatcher(store Store, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: cfg.GetTimeout()},
+		lease:       2 * cfg.GetTimeout(),
+		interval:    cfg.GetInterval(),
+		batchSize:   cfg.GetBatchSize(),
+		maxAttempts: cfg.GetMaxAttempts(),
+		backoffBase: cfg.GetBackoffBase(),
+		backoffMax:  cfg.GetBackoffMax(),
+		now:         time.Now,
+	}
+}
+
+// Run sends deliveries until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	layer := "webhooks.Run"
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		processed, err := d.process(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorKV(ctx, "process deliveries", "layer", layer, "err", err)
+		}
+
+		// full batch means there are probably more deliveries due
+		if err == nil && processed == d.batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(d.interval)
+		}
+	}
+}
+
+// process claims due jobs, sends them without holding any database
+// transaction and records the outcomes. It returns the number of jobs.
+func (d *Dispatcher) process(ctx context.Context) (int, error) {
+	jobs, err := d.store.ClaimDeliveries(ctx, d.batchSize, d.lease)
+	if err != nil || len(jobs) == 0 {
+		return 0, err
+	}
+
+	return len(jobs), d.store.RecordOutcomes(ctx, d.deliver(ctx, jobs))
+}
+
+// deliver attempts jobs concurrently, so one slow receiver doesn't hold up
+// the whole batch
+func (d *Dispatcher) deliver(ctx context.Context, jobs []Job) []Outcome {
+	outcomes := make([]Outcome, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job Job) {
+			defer wg.Done()
+			outcomes[i] = d.attempt(ctx, job)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, job Job) Outcome {
+	outcome := Outcome{DeliveryID: job.DeliveryID, LeaseToken: job.LeaseToken}
+
+	start := d.now()
+	outcome.StatusCode, outcome.Error = d.send(ctx, job)
+	outcome.Duration = d.now().Sub(start)
+
+	if outcome.Error == "" {
+		outcome.Delivered = true
+		return outcome
+	}
+
+	logger.DebugKV(ctx, "delivery failed", "layer", "webhooks.attempt", "delivery", job.DeliveryID, "err", outcome.Error)
+	if attempts := job.Attempts + 1; attempts < d.maxAttempts {
+		outcome.NextAttemptAt = d.now().Add(d.backoff(attempts))
+	}
+	return outcome
+}
+
+// send posts the event and returns response status and error description
+func (d *Dispatcher) send(ctx context.Context, job Job) (int, string) {
+	body, err := json.Marshal(job.Event)
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "persons-webhooks/1.0")
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.DeliveryID, 10))
+	req.Header.Set(HeaderEvent, string(job.Event.Type))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, ""
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return resp.StatusCode, fmt.Sprintf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+}
+
+// backoff returns delay before the attempt following the given number of
+// failed attempts: base, 2*base, 4*base and so on up to max
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.backoffBase
+	for i := 1; i < attempts && delay < d.backoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.backoffMax)
+}
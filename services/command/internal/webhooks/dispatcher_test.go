@@ -0,0 +1,171 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pintoter/persons/services/command/internal/events"
+)
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+type config struct {
+	maxAttempts int
+}
+
+func (c config) GetInterval() time.Duration    { return time.Second }
+func (c config) GetBatchSize() int             { return 10 }
+func (c config) GetTimeout() time.Duration     { return time.Second }
+func (c config) GetMaxAttempts() int           { return c.maxAttempts }
+func (c config) GetBackoffBase() time.Duration { return 10 * time.Second }
+func (c config) GetBackoffMax() time.Duration  { return time.Minute }
+
+type fakeStore struct {
+	jobs     []Job
+	lease    time.Duration
+	outcomes []Outcome
+}
+
+func (s *fakeStore) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]Job, error) {
+	s.lease = lease
+	return s.jobs[:min(limit, len(s.jobs))], nil
+}
+
+func (s *fakeStore) RecordOutcomes(_ context.Context, outcomes []Outcome) error {
+	s.outcomes = outcomes
+	return nil
+}
+
+func newDispatcher(store Store, maxAttempts int) *Dispatcher {
+	d := NewDispatcher(store, config{maxAttempts: maxAttempts})
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func Test_SignVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", 1792411200, body)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.True(t, Verify("secret", "1792411200", body, signature))
+	assert.False(t, Verify("other", "1792411200", body, signature))
+	assert.False(t, Verify("secret", "1792411201", body, signature))
+	assert.False(t, Verify("secret", "1792411200", []byte(`{"id":2}`), signature))
+	assert.False(t, Verify("secret", "now", body, signature))
+}
+
+func Test_deliver(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "receiver unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	event := events.Event{ID: 7, Type: events.PersonCreated, PersonID: 5, Payload: []byte(`{"person":{"id":5}}`), OccurredAt: now}
+
+	t.Run("Delivered", func(t *testing.T) {
+		store := &fakeStore{jobs: []Job{{DeliveryID: 1, URL: receiver.URL, Secret: "secret", Event: event, LeaseToken: "token"}}}
+
+		processed, err := newDispatcher(store, 3).process(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		req := <-requests
+		assert.Equal(t, "1", req.header.Get(HeaderDelivery))
+		assert.Equal(t, "PersonCreated", req.header.Get(HeaderEvent))
+		assert.Equal(t, "1792411200", req.header.Get(HeaderTimestamp))
+		assert.True(t, Verify("secret", req.header.Get(HeaderTimestamp), req.body, req.header.Get(HeaderSignature)))
+		assert.JSONEq(t, `{"id":7,"type":"PersonCreated","person_id":5,"payload":{"person":{"id":5}},"occurred_at":"2026-10-19T12:00:00Z"}`, string(req.body))
+
+		assert.Equal(t, []Outcome{{DeliveryID: 1, LeaseToken: "token", Delivered: true, StatusCode: http.StatusNoContent}}, store.outcomes)
+		assert.Equal(t, 2*time.Second, store.lease)
+	})
+
+	t.Run("Retried", func(t *testing.T) {
+		store := &fakeStore{jobs: []Job{{DeliveryID: 2, Attempts: 1, URL: receiver.URL + "/fail", Secret: "secret", Event: event}}}
+
+		processed, err := newDispatcher(store, 3).process(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		<-requests
+
+		assert.Equal(t, []Outcome{{
+			DeliveryID:    2,
+			StatusCode:    http.StatusServiceUnavailable,
+			Error:         "unexpected status 503: receiver unavailable",
+			NextAttemptAt: now.Add(20 * time.Second),
+		}}, store.outcomes)
+	})
+
+	t.Run("Dead", func(t *testing.T) {
+		store := &fakeStore{jobs: []Job{{DeliveryID: 3, Attempts: 2, URL: receiver.URL + "/fail", Secret: "secret", Event: event}}}
+
+		processed, err := newDispatcher(store, 3).process(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		<-requests
+
+		assert.Len(t, store.outcomes, 1)
+		assert.False(t, store.outcomes[0].Delivered)
+		assert.True(t, store.outcomes[0].NextAttemptAt.IsZero())
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		store := &fakeStore{jobs: []Job{{DeliveryID: 4, URL: "http://127.0.0.1:1", Secret: "secret", Event: event}}}
+
+		processed, err := newDispatcher(store, 3).process(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		assert.Len(t, store.outcomes, 1)
+		assert.Zero(t, store.outcomes[0].StatusCode)
+		assert.NotEmpty(t, store.outcomes[0].Error)
+		assert.Equal(t, now.Add(10*time.Second), store.outcomes[0].NextAttemptAt)
+	})
+}
+
+func Test_processNothingDue(t *testing.T) {
+	store := &fakeStore{}
+
+	processed, err := newDispatcher(store, 3).process(context.Background())
+
+	assert.NoError(t, err)
+	assert.Zero(t, processed)
+	assert.Nil(t, store.outcomes)
+}
+
+func Test_backoff(t *testing.T) {
+	d := newDispatcher(&fakeStore{}, 10)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 4, want: time.Minute},
+		{attempts: 40, want: time.Minute},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, d.backoff(tc.attempts))
+	}
+}
@@ -0,0 +1,82 @@
+// Package webhooks delivers person events to subscribed HTTP endpoints
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/pintoter/persons/services/command/internal/events"
+)
+
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns signature of the request body sent at timestamp. The
+// timestamp is signed too, so receivers can reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature of the received request body
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
+
+// Job is a delivery of event to a subscription due to be attempted
+type Job struct {
+	DeliveryID int64
+	Attempts   int
+	URL        string
+	Secret     string
+	Event      events.Event
+	// LeaseToken identifies the claim the job was returned by
+	LeaseToken string
+}
+
+// Outcome is the result of an attempt to deliver a job
+type Outcome struct {
+	DeliveryID int64
+	LeaseToken string
+	Delivered  bool
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	// NextAttemptAt is zero when the delivery ran out of attempts
+	NextAttemptAt time.Time
+}
+
+// Store gives access to due deliveries. ClaimDeliveries returns up to limit
+// due jobs and hides them from other dispatchers for lease, RecordOutcomes
+// stores results of the attempts. Outcomes of jobs claimed again after their
+// lease expired are dropped.
+type Store interface {
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Job, error)
+	RecordOutcomes(ctx context.Context, outcomes []Outcome) error
+}
+
+type Config interface {
+	GetInterval() time.Duration
+	GetBatchSize() int
+	GetTimeout() time.Duration
+	GetMaxAttempts() int
+	GetBackoffBase() time.Duration
+	GetBackoffMax() time.Duration
+}
@@ -0,0 +1,10 @@
+UPDATE person
+SET name = name_original,
+  surname = surname_original,
+  patronymic = patronymic_original
+WHERE name_original IS NOT NULL;
+
+ALTER TABLE person
+  DROP COLUMN IF EXISTS patronymic_original,
+  DROP COLUMN IF EXISTS surname_original,
+  DROP COLUMN IF EXISTS name_original;
@@ -0,0 +1,71 @@
+ALTER TABLE person
+  ADD COLUMN IF NOT EXISTS name_original VARCHAR(80),
+  ADD COLUMN IF NOT EXISTS surname_original VARCHAR(80),
+  ADD COLUMN IF NOT EXISTS patronymic_original VARCHAR(80);
+
+UPDATE person
+SET name_original = name,
+  surname_original = surname,
+  patronymic_original = patronymic
+WHERE name_original IS NULL;
+
+-- normalize_name mirrors pkg/normalize with the default configuration
+-- (enabled, capitalized, English, the particles of configs/main.yml), so
+-- existing persons match the normalized filters of the query service
+CREATE FUNCTION pg_temp.normalize_name(value text) RETURNS text
+LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+  particles text[] := ARRAY['van', 'von', 'der', 'den', 'de', 'da', 'di', 'du', 'la', 'le', 'bin', 'ibn'];
+  words text[];
+  parts text[];
+  word text;
+  part text;
+  first text;
+  i int;
+  j int;
+BEGIN
+  IF value IS NULL OR btrim(value, E' \t\n\r\f\v') = '' THEN
+    RETURN CASE WHEN value IS NULL THEN NULL ELSE '' END;
+  END IF;
+
+  words := regexp_split_to_array(btrim(normalize(value, NFC), E' \t\n\r\f\v'), '\s+');
+  FOR i IN 1 .. array_length(words, 1) LOOP
+    word := words[i];
+    first := left(word, 1);
+
+    IF i > 1 AND lower(word) = ANY (particles) THEN
+      -- particles are lower-cased unless they start the name
+      words[i] := lower(word);
+    ELSIF first <> lower(first) AND word <> upper(word) THEN
+      -- deliberate mixed case such as DeVito is kept
+      words[i] := word;
+    ELSE
+      parts := string_to_array(lower(word), '-');
+      FOR j IN 1 .. array_length(parts, 1) LOOP
+        part := parts[j];
+        IF substr(part, 2, 1) IN ('''', '’') AND substr(part, 1, 1) NOT IN ('''', '’') THEN
+          -- O'Neil, D'Artagnan
+          part := upper(substr(part, 1, 1)) || substr(part, 2, 1) || upper(substr(part, 3, 1)) || substr(part, 4);
+        ELSIF part LIKE 'mc_%' THEN
+          -- McDonald
+          part := 'Mc' || upper(substr(part, 3, 1)) || substr(part, 4);
+        ELSE
+          part := upper(substr(part, 1, 1)) || substr(part, 2);
+        END IF;
+        parts[j] := part;
+      END LOOP;
+      words[i] := array_to_string(parts, '-');
+    END IF;
+  END LOOP;
+
+  RETURN array_to_string(words, ' ');
+END;
+$$;
+
+UPDATE person
+SET name = pg_temp.normalize_name(name),
+  surname = pg_temp.normalize_name(surname),
+  patronymic = pg_temp.normalize_name(patronymic)
+WHERE name IS DISTINCT FROM pg_temp.normalize_name(name)
+  OR surname IS DISTINCT FROM pg_temp.normalize_name(surname)
+  OR patronymic IS DISTINCT FROM pg_temp.normalize_name(patronymic);
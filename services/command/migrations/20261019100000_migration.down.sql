@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_person_full_name;
+
+ALTER TABLE person
+  DROP CONSTRAINT IF EXISTS fk_person_possible_duplicate_of,
+  DROP COLUMN IF EXISTS possible_duplicate_of;
\ No newline at end of file
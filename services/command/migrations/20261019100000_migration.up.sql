@@ -0,0 +1,5 @@
+ALTER TABLE person
+  ADD COLUMN IF NOT EXISTS possible_duplicate_of INT,
+  ADD CONSTRAINT fk_person_possible_duplicate_of FOREIGN KEY (possible_duplicate_of) REFERENCES person(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_person_full_name ON person (lower(surname), lower(name), lower(COALESCE(patronymic, '')));
\ No newline at end of file
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trg_person_updated_at ON person;
+
+DROP FUNCTION IF EXISTS person_set_updated_at();
+
+ALTER TABLE person
+  DROP CONSTRAINT IF EXISTS fk_person_merged_into,
+  DROP COLUMN IF EXISTS merged_into,
+  DROP COLUMN IF EXISTS deleted_at,
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS created_at;
\ No newline at end of file
@@ -0,0 +1,17 @@
+ALTER TABLE person
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS merged_into INT,
+  ADD CONSTRAINT fk_person_merged_into FOREIGN KEY (merged_into) REFERENCES person(id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION person_set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = now();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_person_updated_at
+  BEFORE UPDATE ON person
+  FOR EACH ROW EXECUTE FUNCTION person_set_updated_at();
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS trg_person_audit_append_only ON person_audit;
+
+DROP FUNCTION IF EXISTS person_audit_append_only();
+
+DROP TABLE IF EXISTS person_audit;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS person_audit (
+  id BIGSERIAL PRIMARY KEY,
+  person_id INT NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  actor TEXT NOT NULL,
+  request_id TEXT NOT NULL,
+  changes JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_audit_person_id ON person_audit (person_id, id);
+
+CREATE OR REPLACE FUNCTION person_audit_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'person_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_person_audit_append_only
+  BEFORE UPDATE OR DELETE ON person_audit
+  FOR EACH ROW EXECUTE FUNCTION person_audit_append_only();
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS person_version;
\ No newline at end of file
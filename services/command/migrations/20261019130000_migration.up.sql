@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS person_version (
+  id BIGSERIAL PRIMARY KEY,
+  person_id INT NOT NULL,
+  valid_from TIMESTAMPTZ NOT NULL DEFAULT now(),
+  valid_to TIMESTAMPTZ,
+  name VARCHAR(80) NOT NULL,
+  surname VARCHAR(80) NOT NULL,
+  patronymic VARCHAR(80),
+  age INT NOT NULL,
+  gender gender_type,
+  nationalize JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_version_person_id ON person_version (person_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_person_version_valid ON person_version (valid_from, valid_to);
+
+-- persons without a create audit entry predate the audit, their created_at
+-- is the default set when the column was added, not the creation time, so
+-- they are versioned from the beginning of time
+INSERT INTO person_version (person_id, valid_from, name, surname, patronymic, age, gender, nationalize)
+SELECT p.id,
+  CASE
+    WHEN EXISTS (SELECT 1 FROM person_audit a WHERE a.person_id = p.id AND a.action = 'create') THEN p.created_at
+    ELSE '-infinity'::timestamptz
+  END,
+  p.name, p.surname, p.patronymic, p.age, p.gender,
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('country_id', n.nationalize, 'probability', n.probability) ORDER BY n.probability DESC)
+    FROM person_nationality n
+    WHERE n.person_id = p.id
+  ), '[]'::jsonb)
+FROM person p
+WHERE p.deleted_at IS NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+-- events backfilled for existing persons are a part of the change log and are kept
\ No newline at end of file
@@ -0,0 +1,20 @@
+INSERT INTO outbox (event_type, aggregate_id, payload, created_at, published_at)
+SELECT 'PersonCreated', p.id,
+  jsonb_build_object('person', jsonb_strip_nulls(jsonb_build_object(
+    'id', p.id,
+    'name', p.name,
+    'surname', p.surname,
+    'patronymic', NULLIF(p.patronymic, ''),
+    'age', p.age,
+    'gender', p.gender,
+    'nationalize', COALESCE((
+      SELECT jsonb_agg(jsonb_build_object('country_id', n.nationalize, 'probability', n.probability) ORDER BY n.probability DESC)
+      FROM person_nationality n
+      WHERE n.person_id = p.id
+    ), '[]'::jsonb)
+  ))),
+  p.created_at, now()
+FROM person p
+WHERE p.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM outbox o WHERE o.aggregate_id = p.id)
+ORDER BY p.id;
\ No newline at end of file
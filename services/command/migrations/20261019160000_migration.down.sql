@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trg_outbox_webhook_fan_out ON outbox;
+
+DROP FUNCTION IF EXISTS webhook_fan_out();
+
+DROP TABLE IF EXISTS webhook_attempt;
+DROP TABLE IF EXISTS webhook_delivery;
+DROP TABLE IF EXISTS webhook_subscription;
\ No newline at end of file
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+  id SERIAL PRIMARY KEY,
+  url TEXT NOT NULL,
+  secret VARCHAR(128) NOT NULL,
+  event_types VARCHAR(32)[] NOT NULL DEFAULT '{}',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+  id BIGSERIAL PRIMARY KEY,
+  subscription_id INT NOT NULL REFERENCES webhook_subscription(id) ON DELETE CASCADE,
+  event_id BIGINT NOT NULL REFERENCES outbox(id),
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status_code INT,
+  last_error TEXT,
+  delivered_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_subscription ON webhook_delivery (subscription_id, id);
+
+CREATE TABLE IF NOT EXISTS webhook_attempt (
+  id BIGSERIAL PRIMARY KEY,
+  delivery_id BIGINT NOT NULL REFERENCES webhook_delivery(id) ON DELETE CASCADE,
+  status_code INT,
+  error TEXT,
+  duration_ms INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempt_delivery ON webhook_attempt (delivery_id, id);
+
+-- every event is queued for delivery to matching subscriptions in the
+-- transaction of the change
+CREATE OR REPLACE FUNCTION webhook_fan_out() RETURNS TRIGGER AS $$
+BEGIN
+  INSERT INTO webhook_delivery (subscription_id, event_id)
+  SELECT s.id, NEW.id
+  FROM webhook_subscription s
+  WHERE cardinality(s.event_types) = 0 OR NEW.event_type = ANY(s.event_types);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_outbox_webhook_fan_out
+  AFTER INSERT ON outbox
+  FOR EACH ROW EXECUTE FUNCTION webhook_fan_out();
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_person_version_phonetic;
+
+ALTER TABLE person_version DROP COLUMN IF EXISTS phonetic;
@@ -0,0 +1,5 @@
+-- phonetic keys of name and surname, computed on write for match=phonetic
+-- searches; snapshots written before have none
+ALTER TABLE person_version ADD COLUMN IF NOT EXISTS phonetic JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_person_version_phonetic ON person_version USING GIN (phonetic jsonb_path_ops);
@@ -0,0 +1 @@
+-- pg_trgm is shared with the query service and stays installed
@@ -0,0 +1,4 @@
+-- a claim stamps its deliveries with a token, outcomes are recorded only while
+-- the token still matches, so a dispatcher whose lease expired can't
+-- overwrite the result of the one that claimed the delivery after it
+ALTER TABLE webhook_delivery ADD COLUMN IF NOT EXISTS lease_token VARCHAR(32);
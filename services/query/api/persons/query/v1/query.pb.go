@@ -0,0 +1,1065 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: api/persons/query/v1/query.proto
+
+package queryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Nationality struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 3166-1 alpha-2 country code
+	CountryId     string  `protobuf:"bytes,1,opt,name=country_id,json=countryId,proto3" json:"country_id,omitempty"`
+	Probability   float64 `protobuf:"fixed64,2,opt,name=probability,proto3" json:"probability,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Nationality) Reset() {
+	*x = Nationality{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Nationality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nationality) ProtoMessage() {}
+
+func (x *Nationality) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nationality.ProtoReflect.Descriptor instead.
+func (*Nationality) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Nationality) GetCountryId() string {
+	if x != nil {
+		return x.CountryId
+	}
+	return ""
+}
+
+func (x *Nationality) GetProbability() float64 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
+type Person struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Surname       string                 `protobuf:"bytes,3,opt,name=surname,proto3" json:"surname,omitempty"`
+	Patronymic    string                 `protobuf:"bytes,4,opt,name=patronymic,proto3" json:"patronymic,omitempty"`
+	Age           int32                  `protobuf:"varint,5,opt,name=age,proto3" json:"age,omitempty"`
+	Gender        string                 `protobuf:"bytes,6,opt,name=gender,proto3" json:"gender,omitempty"`
+	Nationalize   []*Nationality         `protobuf:"bytes,7,rep,name=nationalize,proto3" json:"nationalize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Person) Reset() {
+	*x = Person{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Person) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Person) ProtoMessage() {}
+
+func (x *Person) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Person.ProtoReflect.Descriptor instead.
+func (*Person) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Person) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Person) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Person) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *Person) GetPatronymic() string {
+	if x != nil {
+		return x.Patronymic
+	}
+	return ""
+}
+
+func (x *Person) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Person) GetGender() string {
+	if x != nil {
+		return x.Gender
+	}
+	return ""
+}
+
+func (x *Person) GetNationalize() []*Nationality {
+	if x != nil {
+		return x.Nationalize
+	}
+	return nil
+}
+
+// PersonFilter matches persons like the query parameters of
+// GET /api/v1/persons, unset fields match any value
+type PersonFilter struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    *string                `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Surname *string                `protobuf:"bytes,2,opt,name=surname,proto3,oneof" json:"surname,omitempty"`
+	// empty selects persons without patronymic
+	Patronymic *string `protobuf:"bytes,3,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	Age        *int32  `protobuf:"varint,4,opt,name=age,proto3,oneof" json:"age,omitempty"`
+	// comma-separated genders
+	Gender *string `protobuf:"bytes,5,opt,name=gender,proto3,oneof" json:"gender,omitempty"`
+	// comma-separated ISO 3166-1 alpha-2 country codes, any of them
+	Nationalize *string `protobuf:"bytes,6,opt,name=nationalize,proto3,oneof" json:"nationalize,omitempty"`
+	// minimal age, inclusive
+	AgeMin *int32 `protobuf:"varint,7,opt,name=age_min,json=ageMin,proto3,oneof" json:"age_min,omitempty"`
+	// maximal age, inclusive
+	AgeMax *int32 `protobuf:"varint,8,opt,name=age_max,json=ageMax,proto3,oneof" json:"age_max,omitempty"`
+	// comma-separated country codes, the most probable nationality is any of
+	// them
+	PrimaryNationality *string `protobuf:"bytes,9,opt,name=primary_nationality,json=primaryNationality,proto3,oneof" json:"primary_nationality,omitempty"`
+	// least probability of the countries nationalize and primary_nationality
+	// match, 0 to 1
+	NationalizeMinProbability *float64          `protobuf:"fixed64,10,opt,name=nationalize_min_probability,json=nationalizeMinProbability,proto3,oneof" json:"nationalize_min_probability,omitempty"`
+	Exclude                   *PersonExclusions `protobuf:"bytes,11,opt,name=exclude,proto3" json:"exclude,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *PersonFilter) Reset() {
+	*x = PersonFilter{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PersonFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonFilter) ProtoMessage() {}
+
+func (x *PersonFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonFilter.ProtoReflect.Descriptor instead.
+func (*PersonFilter) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PersonFilter) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetSurname() string {
+	if x != nil && x.Surname != nil {
+		return *x.Surname
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetAge() int32 {
+	if x != nil && x.Age != nil {
+		return *x.Age
+	}
+	return 0
+}
+
+func (x *PersonFilter) GetGender() string {
+	if x != nil && x.Gender != nil {
+		return *x.Gender
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetNationalize() string {
+	if x != nil && x.Nationalize != nil {
+		return *x.Nationalize
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetAgeMin() int32 {
+	if x != nil && x.AgeMin != nil {
+		return *x.AgeMin
+	}
+	return 0
+}
+
+func (x *PersonFilter) GetAgeMax() int32 {
+	if x != nil && x.AgeMax != nil {
+		return *x.AgeMax
+	}
+	return 0
+}
+
+func (x *PersonFilter) GetPrimaryNationality() string {
+	if x != nil && x.PrimaryNationality != nil {
+		return *x.PrimaryNationality
+	}
+	return ""
+}
+
+func (x *PersonFilter) GetNationalizeMinProbability() float64 {
+	if x != nil && x.NationalizeMinProbability != nil {
+		return *x.NationalizeMinProbability
+	}
+	return 0
+}
+
+func (x *PersonFilter) GetExclude() *PersonExclusions {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+// PersonExclusions exclude persons with any of the comma-separated values,
+// like the query parameters ending with !
+type PersonExclusions struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    *string                `protobuf:"bytes,1,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Surname *string                `protobuf:"bytes,2,opt,name=surname,proto3,oneof" json:"surname,omitempty"`
+	// empty excludes persons without patronymic
+	Patronymic    *string `protobuf:"bytes,3,opt,name=patronymic,proto3,oneof" json:"patronymic,omitempty"`
+	Gender        *string `protobuf:"bytes,4,opt,name=gender,proto3,oneof" json:"gender,omitempty"`
+	Nationalize   *string `protobuf:"bytes,5,opt,name=nationalize,proto3,oneof" json:"nationalize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PersonExclusions) Reset() {
+	*x = PersonExclusions{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PersonExclusions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonExclusions) ProtoMessage() {}
+
+func (x *PersonExclusions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonExclusions.ProtoReflect.Descriptor instead.
+func (*PersonExclusions) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PersonExclusions) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *PersonExclusions) GetSurname() string {
+	if x != nil && x.Surname != nil {
+		return *x.Surname
+	}
+	return ""
+}
+
+func (x *PersonExclusions) GetPatronymic() string {
+	if x != nil && x.Patronymic != nil {
+		return *x.Patronymic
+	}
+	return ""
+}
+
+func (x *PersonExclusions) GetGender() string {
+	if x != nil && x.Gender != nil {
+		return *x.Gender
+	}
+	return ""
+}
+
+func (x *PersonExclusions) GetNationalize() string {
+	if x != nil && x.Nationalize != nil {
+		return *x.Nationalize
+	}
+	return ""
+}
+
+type GetPersonRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// returns the person as it was at this time
+	AsOf *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	// nationalities returned: top1, top3 or all (default)
+	Nationalities *string `protobuf:"bytes,3,opt,name=nationalities,proto3,oneof" json:"nationalities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonRequest) Reset() {
+	*x = GetPersonRequest{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonRequest) ProtoMessage() {}
+
+func (x *GetPersonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPersonRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetPersonRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+func (x *GetPersonRequest) GetNationalities() string {
+	if x != nil && x.Nationalities != nil {
+		return *x.Nationalities
+	}
+	return ""
+}
+
+type GetPersonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Person        *Person                `protobuf:"bytes,1,opt,name=person,proto3" json:"person,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonResponse) Reset() {
+	*x = GetPersonResponse{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonResponse) ProtoMessage() {}
+
+func (x *GetPersonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonResponse.ProtoReflect.Descriptor instead.
+func (*GetPersonResponse) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPersonResponse) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+type GetPersonsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Filter *PersonFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// defaults to 5
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// defaults to 1
+	Page int32 `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	// returns persons as they were at this time
+	AsOf *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	// search by name parts starting with it or similar to it, results are
+	// ranked by score
+	Q *string `protobuf:"bytes,5,opt,name=q,proto3,oneof" json:"q,omitempty"`
+	// how q matches: fuzzy (default) or phonetic
+	Match *string `protobuf:"bytes,6,opt,name=match,proto3,oneof" json:"match,omitempty"`
+	// comma-separated fields to sort by, descending ones prefixed with a minus
+	Sort *string `protobuf:"bytes,7,opt,name=sort,proto3,oneof" json:"sort,omitempty"`
+	// nationalities returned: top1, top3 or all (default)
+	Nationalities *string `protobuf:"bytes,8,opt,name=nationalities,proto3,oneof" json:"nationalities,omitempty"`
+	// next_cursor or prev_cursor of the previous response, can't be combined
+	// with page
+	Cursor        *string `protobuf:"bytes,9,opt,name=cursor,proto3,oneof" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonsRequest) Reset() {
+	*x = GetPersonsRequest{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonsRequest) ProtoMessage() {}
+
+func (x *GetPersonsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonsRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonsRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPersonsRequest) GetFilter() *PersonFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *GetPersonsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPersonsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetPersonsRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+func (x *GetPersonsRequest) GetQ() string {
+	if x != nil && x.Q != nil {
+		return *x.Q
+	}
+	return ""
+}
+
+func (x *GetPersonsRequest) GetMatch() string {
+	if x != nil && x.Match != nil {
+		return *x.Match
+	}
+	return ""
+}
+
+func (x *GetPersonsRequest) GetSort() string {
+	if x != nil && x.Sort != nil {
+		return *x.Sort
+	}
+	return ""
+}
+
+func (x *GetPersonsRequest) GetNationalities() string {
+	if x != nil && x.Nationalities != nil {
+		return *x.Nationalities
+	}
+	return ""
+}
+
+func (x *GetPersonsRequest) GetCursor() string {
+	if x != nil && x.Cursor != nil {
+		return *x.Cursor
+	}
+	return ""
+}
+
+type GetPersonsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Persons []*Person              `protobuf:"bytes,1,rep,name=persons,proto3" json:"persons,omitempty"`
+	// number of persons matching the filter on all pages
+	Total   int64 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	HasMore bool  `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	// cursors of the pages next to this one, empty when there are no persons
+	// there
+	NextCursor    string `protobuf:"bytes,4,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	PrevCursor    string `protobuf:"bytes,5,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonsResponse) Reset() {
+	*x = GetPersonsResponse{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonsResponse) ProtoMessage() {}
+
+func (x *GetPersonsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonsResponse.ProtoReflect.Descriptor instead.
+func (*GetPersonsResponse) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPersonsResponse) GetPersons() []*Person {
+	if x != nil {
+		return x.Persons
+	}
+	return nil
+}
+
+func (x *GetPersonsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetPersonsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *GetPersonsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GetPersonsResponse) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
+type ListPersonsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *PersonFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPersonsRequest) Reset() {
+	*x = ListPersonsRequest{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPersonsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPersonsRequest) ProtoMessage() {}
+
+func (x *ListPersonsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPersonsRequest.ProtoReflect.Descriptor instead.
+func (*ListPersonsRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPersonsRequest) GetFilter() *PersonFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type WatchChangesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Filter *PersonFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// resumes after this event, without it only new changes are streamed
+	LastEventId   *int64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3,oneof" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchChangesRequest) Reset() {
+	*x = WatchChangesRequest{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchChangesRequest) ProtoMessage() {}
+
+func (x *WatchChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchChangesRequest.ProtoReflect.Descriptor instead.
+func (*WatchChangesRequest) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchChangesRequest) GetFilter() *PersonFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *WatchChangesRequest) GetLastEventId() int64 {
+	if x != nil && x.LastEventId != nil {
+		return *x.LastEventId
+	}
+	return 0
+}
+
+type PersonEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// PersonCreated, PersonUpdated, PersonEnriched or PersonDeleted
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	PersonId      int64                  `protobuf:"varint,3,opt,name=person_id,json=personId,proto3" json:"person_id,omitempty"`
+	Person        *Person                `protobuf:"bytes,4,opt,name=person,proto3" json:"person,omitempty"`
+	MergedInto    *int64                 `protobuf:"varint,5,opt,name=merged_into,json=mergedInto,proto3,oneof" json:"merged_into,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PersonEvent) Reset() {
+	*x = PersonEvent{}
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PersonEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonEvent) ProtoMessage() {}
+
+func (x *PersonEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_persons_query_v1_query_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonEvent.ProtoReflect.Descriptor instead.
+func (*PersonEvent) Descriptor() ([]byte, []int) {
+	return file_api_persons_query_v1_query_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PersonEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PersonEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PersonEvent) GetPersonId() int64 {
+	if x != nil {
+		return x.PersonId
+	}
+	return 0
+}
+
+func (x *PersonEvent) GetPerson() *Person {
+	if x != nil {
+		return x.Person
+	}
+	return nil
+}
+
+func (x *PersonEvent) GetMergedInto() int64 {
+	if x != nil && x.MergedInto != nil {
+		return *x.MergedInto
+	}
+	return 0
+}
+
+func (x *PersonEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+var File_api_persons_query_v1_query_proto protoreflect.FileDescriptor
+
+const file_api_persons_query_v1_query_proto_rawDesc = "" +
+	"\n" +
+	" api/persons/query/v1/query.proto\x12\x10persons.query.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"N\n" +
+	"\vNationality\x12\x1d\n" +
+	"\n" +
+	"country_id\x18\x01 \x01(\tR\tcountryId\x12 \n" +
+	"\vprobability\x18\x02 \x01(\x01R\vprobability\"\xd1\x01\n" +
+	"\x06Person\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\asurname\x18\x03 \x01(\tR\asurname\x12\x1e\n" +
+	"\n" +
+	"patronymic\x18\x04 \x01(\tR\n" +
+	"patronymic\x12\x10\n" +
+	"\x03age\x18\x05 \x01(\x05R\x03age\x12\x16\n" +
+	"\x06gender\x18\x06 \x01(\tR\x06gender\x12?\n" +
+	"\vnationalize\x18\a \x03(\v2\x1d.persons.query.v1.NationalityR\vnationalize\"\xd2\x04\n" +
+	"\fPersonFilter\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x1d\n" +
+	"\asurname\x18\x02 \x01(\tH\x01R\asurname\x88\x01\x01\x12#\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tH\x02R\n" +
+	"patronymic\x88\x01\x01\x12\x15\n" +
+	"\x03age\x18\x04 \x01(\x05H\x03R\x03age\x88\x01\x01\x12\x1b\n" +
+	"\x06gender\x18\x05 \x01(\tH\x04R\x06gender\x88\x01\x01\x12%\n" +
+	"\vnationalize\x18\x06 \x01(\tH\x05R\vnationalize\x88\x01\x01\x12\x1c\n" +
+	"\aage_min\x18\a \x01(\x05H\x06R\x06ageMin\x88\x01\x01\x12\x1c\n" +
+	"\aage_max\x18\b \x01(\x05H\aR\x06ageMax\x88\x01\x01\x124\n" +
+	"\x13primary_nationality\x18\t \x01(\tH\bR\x12primaryNationality\x88\x01\x01\x12C\n" +
+	"\x1bnationalize_min_probability\x18\n" +
+	" \x01(\x01H\tR\x19nationalizeMinProbability\x88\x01\x01\x12<\n" +
+	"\aexclude\x18\v \x01(\v2\".persons.query.v1.PersonExclusionsR\aexcludeB\a\n" +
+	"\x05_nameB\n" +
+	"\n" +
+	"\b_surnameB\r\n" +
+	"\v_patronymicB\x06\n" +
+	"\x04_ageB\t\n" +
+	"\a_genderB\x0e\n" +
+	"\f_nationalizeB\n" +
+	"\n" +
+	"\b_age_minB\n" +
+	"\n" +
+	"\b_age_maxB\x16\n" +
+	"\x14_primary_nationalityB\x1e\n" +
+	"\x1c_nationalize_min_probability\"\xf2\x01\n" +
+	"\x10PersonExclusions\x12\x17\n" +
+	"\x04name\x18\x01 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x1d\n" +
+	"\asurname\x18\x02 \x01(\tH\x01R\asurname\x88\x01\x01\x12#\n" +
+	"\n" +
+	"patronymic\x18\x03 \x01(\tH\x02R\n" +
+	"patronymic\x88\x01\x01\x12\x1b\n" +
+	"\x06gender\x18\x04 \x01(\tH\x03R\x06gender\x88\x01\x01\x12%\n" +
+	"\vnationalize\x18\x05 \x01(\tH\x04R\vnationalize\x88\x01\x01B\a\n" +
+	"\x05_nameB\n" +
+	"\n" +
+	"\b_surnameB\r\n" +
+	"\v_patronymicB\t\n" +
+	"\a_genderB\x0e\n" +
+	"\f_nationalize\"\x90\x01\n" +
+	"\x10GetPersonRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\x12)\n" +
+	"\rnationalities\x18\x03 \x01(\tH\x00R\rnationalities\x88\x01\x01B\x10\n" +
+	"\x0e_nationalities\"E\n" +
+	"\x11GetPersonResponse\x120\n" +
+	"\x06person\x18\x01 \x01(\v2\x18.persons.query.v1.PersonR\x06person\"\xeb\x02\n" +
+	"\x11GetPersonsRequest\x126\n" +
+	"\x06filter\x18\x01 \x01(\v2\x1e.persons.query.v1.PersonFilterR\x06filter\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12/\n" +
+	"\x05as_of\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\x12\x11\n" +
+	"\x01q\x18\x05 \x01(\tH\x00R\x01q\x88\x01\x01\x12\x19\n" +
+	"\x05match\x18\x06 \x01(\tH\x01R\x05match\x88\x01\x01\x12\x17\n" +
+	"\x04sort\x18\a \x01(\tH\x02R\x04sort\x88\x01\x01\x12)\n" +
+	"\rnationalities\x18\b \x01(\tH\x03R\rnationalities\x88\x01\x01\x12\x1b\n" +
+	"\x06cursor\x18\t \x01(\tH\x04R\x06cursor\x88\x01\x01B\x04\n" +
+	"\x02_qB\b\n" +
+	"\x06_matchB\a\n" +
+	"\x05_sortB\x10\n" +
+	"\x0e_nationalitiesB\t\n" +
+	"\a_cursor\"\xbb\x01\n" +
+	"\x12GetPersonsResponse\x122\n" +
+	"\apersons\x18\x01 \x03(\v2\x18.persons.query.v1.PersonR\apersons\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\x12\x1f\n" +
+	"\vnext_cursor\x18\x04 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x05 \x01(\tR\n" +
+	"prevCursor\"L\n" +
+	"\x12ListPersonsRequest\x126\n" +
+	"\x06filter\x18\x01 \x01(\v2\x1e.persons.query.v1.PersonFilterR\x06filter\"\x88\x01\n" +
+	"\x13WatchChangesRequest\x126\n" +
+	"\x06filter\x18\x01 \x01(\v2\x1e.persons.query.v1.PersonFilterR\x06filter\x12'\n" +
+	"\rlast_event_id\x18\x02 \x01(\x03H\x00R\vlastEventId\x88\x01\x01B\x10\n" +
+	"\x0e_last_event_id\"\xf3\x01\n" +
+	"\vPersonEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1b\n" +
+	"\tperson_id\x18\x03 \x01(\x03R\bpersonId\x120\n" +
+	"\x06person\x18\x04 \x01(\v2\x18.persons.query.v1.PersonR\x06person\x12$\n" +
+	"\vmerged_into\x18\x05 \x01(\x03H\x00R\n" +
+	"mergedInto\x88\x01\x01\x12;\n" +
+	"\voccurred_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAtB\x0e\n" +
+	"\f_merged_into2\xec\x02\n" +
+	"\x12PersonQueryService\x12T\n" +
+	"\tGetPerson\x12\".persons.query.v1.GetPersonRequest\x1a#.persons.query.v1.GetPersonResponse\x12W\n" +
+	"\n" +
+	"GetPersons\x12#.persons.query.v1.GetPersonsRequest\x1a$.persons.query.v1.GetPersonsResponse\x12O\n" +
+	"\vListPersons\x12$.persons.query.v1.ListPersonsRequest\x1a\x18.persons.query.v1.Person0\x01\x12V\n" +
+	"\fWatchChanges\x12%.persons.query.v1.WatchChangesRequest\x1a\x1d.persons.query.v1.PersonEvent0\x01BIZGgithub.com/pintoter/persons/services/query/api/persons/query/v1;queryv1b\x06proto3"
+
+var (
+	file_api_persons_query_v1_query_proto_rawDescOnce sync.Once
+	file_api_persons_query_v1_query_proto_rawDescData []byte
+)
+
+func file_api_persons_query_v1_query_proto_rawDescGZIP() []byte {
+	file_api_persons_query_v1_query_proto_rawDescOnce.Do(func() {
+		file_api_persons_query_v1_query_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_persons_query_v1_query_proto_rawDesc), len(file_api_persons_query_v1_query_proto_rawDesc)))
+	})
+	return file_api_persons_query_v1_query_proto_rawDescData
+}
+
+var file_api_persons_query_v1_query_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_persons_query_v1_query_proto_goTypes = []any{
+	(*Nationality)(nil),           // 0: persons.query.v1.Nationality
+	(*Person)(nil),                // 1: persons.query.v1.Person
+	(*PersonFilter)(nil),          // 2: persons.query.v1.PersonFilter
+	(*PersonExclusions)(nil),      // 3: persons.query.v1.PersonExclusions
+	(*GetPersonRequest)(nil),      // 4: persons.query.v1.GetPersonRequest
+	(*GetPersonResponse)(nil),     // 5: persons.query.v1.GetPersonResponse
+	(*GetPersonsRequest)(nil),     // 6: persons.query.v1.GetPersonsRequest
+	(*GetPersonsResponse)(nil),    // 7: persons.query.v1.GetPersonsResponse
+	(*ListPersonsRequest)(nil),    // 8: persons.query.v1.ListPersonsRequest
+	(*WatchChangesRequest)(nil),   // 9: persons.query.v1.WatchChangesRequest
+	(*PersonEvent)(nil),           // 10: persons.query.v1.PersonEvent
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_api_persons_query_v1_query_proto_depIdxs = []int32{
+	0,  // 0: persons.query.v1.Person.nationalize:type_name -> persons.query.v1.Nationality
+	3,  // 1: persons.query.v1.PersonFilter.exclude:type_name -> persons.query.v1.PersonExclusions
+	11, // 2: persons.query.v1.GetPersonRequest.as_of:type_name -> google.protobuf.Timestamp
+	1,  // 3: persons.query.v1.GetPersonResponse.person:type_name -> persons.query.v1.Person
+	2,  // 4: persons.query.v1.GetPersonsRequest.filter:type_name -> persons.query.v1.PersonFilter
+	11, // 5: persons.query.v1.GetPersonsRequest.as_of:type_name -> google.protobuf.Timestamp
+	1,  // 6: persons.query.v1.GetPersonsResponse.persons:type_name -> persons.query.v1.Person
+	2,  // 7: persons.query.v1.ListPersonsRequest.filter:type_name -> persons.query.v1.PersonFilter
+	2,  // 8: persons.query.v1.WatchChangesRequest.filter:type_name -> persons.query.v1.PersonFilter
+	1,  // 9: persons.query.v1.PersonEvent.person:type_name -> persons.query.v1.Person
+	11, // 10: persons.query.v1.PersonEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	4,  // 11: persons.query.v1.PersonQueryService.GetPerson:input_type -> persons.query.v1.GetPersonRequest
+	6,  // 12: persons.query.v1.PersonQueryService.GetPersons:input_type -> persons.query.v1.GetPersonsRequest
+	8,  // 13: persons.query.v1.PersonQueryService.ListPersons:input_type -> persons.query.v1.ListPersonsRequest
+	9,  // 14: persons.query.v1.PersonQueryService.WatchChanges:input_type -> persons.query.v1.WatchChangesRequest
+	5,  // 15: persons.query.v1.PersonQueryService.GetPerson:output_type -> persons.query.v1.GetPersonResponse
+	7,  // 16: persons.query.v1.PersonQueryService.GetPersons:output_type -> persons.query.v1.GetPersonsResponse
+	1,  // 17: persons.query.v1.PersonQueryService.ListPersons:output_type -> persons.query.v1.Person
+	10, // 18: persons.query.v1.PersonQueryService.WatchChanges:output_type -> persons.query.v1.PersonEvent
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_api_persons_query_v1_query_proto_init() }
+func file_api_persons_query_v1_query_proto_init() {
+	if File_api_persons_query_v1_query_proto != nil {
+		return
+	}
+	file_api_persons_query_v1_query_proto_msgTypes[2].OneofWrappers = []any{}
+	file_api_persons_query_v1_query_proto_msgTypes[3].OneofWrappers = []any{}
+	file_api_persons_query_v1_query_proto_msgTypes[4].OneofWrappers = []any{}
+	file_api_persons_query_v1_query_proto_msgTypes[6].OneofWrappers = []any{}
+	file_api_persons_query_v1_query_proto_msgTypes[9].OneofWrappers = []any{}
+	file_api_persons_query_v1_query_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_persons_query_v1_query_proto_rawDesc), len(file_api_persons_query_v1_query_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_persons_query_v1_query_proto_goTypes,
+		DependencyIndexes: file_api_persons_query_v1_query_proto_depIdxs,
+		MessageInfos:      file_api_persons_query_v1_query_proto_msgTypes,
+	}.Build()
+	File_api_persons_query_v1_query_proto = out.File
+	file_api_persons_query_v1_query_proto_goTypes = nil
+	file_api_persons_query_v1_query_proto_depIdxs = nil
+}
@@ -0,0 +1,260 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/persons/query/v1/query.proto
+
+package queryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PersonQueryService_GetPerson_FullMethodName    = "/persons.query.v1.PersonQueryService/GetPerson"
+	PersonQueryService_GetPersons_FullMethodName   = "/persons.query.v1.PersonQueryService/GetPersons"
+	PersonQueryService_ListPersons_FullMethodName  = "/persons.query.v1.PersonQueryService/ListPersons"
+	PersonQueryService_WatchChanges_FullMethodName = "/persons.query.v1.PersonQueryService/WatchChanges"
+)
+
+// PersonQueryServiceClient is the client API for PersonQueryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PersonQueryService reads persons from the read model. Persons are changed
+// with persons.command.v1.PersonCommandService of the command service.
+type PersonQueryServiceClient interface {
+	// GetPerson returns a person by id. A merged person fails with NOT_FOUND
+	// and MERGED ErrorInfo with the survivor id in merged_into metadata.
+	GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*GetPersonResponse, error)
+	// GetPersons returns a page of persons matching the filter.
+	GetPersons(ctx context.Context, in *GetPersonsRequest, opts ...grpc.CallOption) (*GetPersonsResponse, error)
+	// ListPersons streams all persons matching the filter.
+	ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error)
+	// WatchChanges streams person change events after they are applied to the
+	// read model, like GET /api/v1/persons/events.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PersonEvent], error)
+}
+
+type personQueryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPersonQueryServiceClient(cc grpc.ClientConnInterface) PersonQueryServiceClient {
+	return &personQueryServiceClient{cc}
+}
+
+func (c *personQueryServiceClient) GetPerson(ctx context.Context, in *GetPersonRequest, opts ...grpc.CallOption) (*GetPersonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPersonResponse)
+	err := c.cc.Invoke(ctx, PersonQueryService_GetPerson_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personQueryServiceClient) GetPersons(ctx context.Context, in *GetPersonsRequest, opts ...grpc.CallOption) (*GetPersonsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPersonsResponse)
+	err := c.cc.Invoke(ctx, PersonQueryService_GetPersons_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *personQueryServiceClient) ListPersons(ctx context.Context, in *ListPersonsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Person], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PersonQueryService_ServiceDesc.Streams[0], PersonQueryService_ListPersons_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListPersonsRequest, Person]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonQueryService_ListPersonsClient = grpc.ServerStreamingClient[Person]
+
+func (c *personQueryServiceClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PersonEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PersonQueryService_ServiceDesc.Streams[1], PersonQueryService_WatchChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchChangesRequest, PersonEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonQueryService_WatchChangesClient = grpc.ServerStreamingClient[PersonEvent]
+
+// PersonQueryServiceServer is the server API for PersonQueryService service.
+// All implementations must embed UnimplementedPersonQueryServiceServer
+// for forward compatibility.
+//
+// PersonQueryService reads persons from the read model. Persons are changed
+// with persons.command.v1.PersonCommandService of the command service.
+type PersonQueryServiceServer interface {
+	// GetPerson returns a person by id. A merged person fails with NOT_FOUND
+	// and MERGED ErrorInfo with the survivor id in merged_into metadata.
+	GetPerson(context.Context, *GetPersonRequest) (*GetPersonResponse, error)
+	// GetPersons returns a page of persons matching the filter.
+	GetPersons(context.Context, *GetPersonsRequest) (*GetPersonsResponse, error)
+	// ListPersons streams all persons matching the filter.
+	ListPersons(*ListPersonsRequest, grpc.ServerStreamingServer[Person]) error
+	// WatchChanges streams person change events after they are applied to the
+	// read model, like GET /api/v1/persons/events.
+	WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[PersonEvent]) error
+	mustEmbedUnimplementedPersonQueryServiceServer()
+}
+
+// UnimplementedPersonQueryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPersonQueryServiceServer struct{}
+
+func (UnimplementedPersonQueryServiceServer) GetPerson(context.Context, *GetPersonRequest) (*GetPersonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerson not implemented")
+}
+func (UnimplementedPersonQueryServiceServer) GetPersons(context.Context, *GetPersonsRequest) (*GetPersonsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPersons not implemented")
+}
+func (UnimplementedPersonQueryServiceServer) ListPersons(*ListPersonsRequest, grpc.ServerStreamingServer[Person]) error {
+	return status.Errorf(codes.Unimplemented, "method ListPersons not implemented")
+}
+func (UnimplementedPersonQueryServiceServer) WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[PersonEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchChanges not implemented")
+}
+func (UnimplementedPersonQueryServiceServer) mustEmbedUnimplementedPersonQueryServiceServer() {}
+func (UnimplementedPersonQueryServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafePersonQueryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PersonQueryServiceServer will
+// result in compilation errors.
+type UnsafePersonQueryServiceServer interface {
+	mustEmbedUnimplementedPersonQueryServiceServer()
+}
+
+func RegisterPersonQueryServiceServer(s grpc.ServiceRegistrar, srv PersonQueryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPersonQueryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PersonQueryService_ServiceDesc, srv)
+}
+
+func _PersonQueryService_GetPerson_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonQueryServiceServer).GetPerson(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonQueryService_GetPerson_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonQueryServiceServer).GetPerson(ctx, req.(*GetPersonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonQueryService_GetPersons_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPersonsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PersonQueryServiceServer).GetPersons(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PersonQueryService_GetPersons_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PersonQueryServiceServer).GetPersons(ctx, req.(*GetPersonsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PersonQueryService_ListPersons_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPersonsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PersonQueryServiceServer).ListPersons(m, &grpc.GenericServerStream[ListPersonsRequest, Person]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonQueryService_ListPersonsServer = grpc.ServerStreamingServer[Person]
+
+func _PersonQueryService_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PersonQueryServiceServer).WatchChanges(m, &grpc.GenericServerStream[WatchChangesRequest, PersonEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PersonQueryService_WatchChangesServer = grpc.ServerStreamingServer[PersonEvent]
+
+// PersonQueryService_ServiceDesc is the grpc.ServiceDesc for PersonQueryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PersonQueryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "persons.query.v1.PersonQueryService",
+	HandlerType: (*PersonQueryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPerson",
+			Handler:    _PersonQueryService_GetPerson_Handler,
+		},
+		{
+			MethodName: "GetPersons",
+			Handler:    _PersonQueryService_GetPersons_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListPersons",
+			Handler:       _PersonQueryService_ListPersons_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _PersonQueryService_WatchChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/persons/query/v1/query.proto",
+}
@@ -1,7 +1,15 @@
 package main
 
-import "github.com/pintoter/persons/services/query/internal/app"
+import (
+	"os"
+
+	"github.com/pintoter/persons/services/query/internal/app"
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "rebuild" {
+		app.Rebuild()
+		return
+	}
 	app.Run()
 }
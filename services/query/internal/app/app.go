@@ -8,11 +8,15 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 
 	_ "github.com/pintoter/persons/docs"
 	"github.com/pintoter/persons/pkg/database/postgres"
 	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/pkg/normalize"
 	"github.com/pintoter/persons/services/query/internal/config"
+	migrations "github.com/pintoter/persons/services/query/internal/database"
+	"github.com/pintoter/persons/services/query/internal/projection"
 	dbrepo "github.com/pintoter/persons/services/query/internal/repository/db"
 	"github.com/pintoter/persons/services/query/internal/server"
 	"github.com/pintoter/persons/services/query/internal/service"
@@ -37,6 +41,11 @@ func Run() {
 	syncLogger := initLogger(ctx, cfg)
 	defer syncLogger()
 
+	err := migrations.Do(&cfg.DB)
+	if err != nil {
+		logger.FatalKV(ctx, "Failed init migrations", "err", err)
+	}
+
 	db, err := postgres.New(&cfg.DB)
 	if err != nil {
 		logger.FatalKV(ctx, "Failed connect database", "err", err)
@@ -44,11 +53,26 @@ func Run() {
 
 	repo := dbrepo.New(db)
 
-	service := service.New(repo)
-	handler := transport.NewHandler(service)
+	projectorCtx, stopProjector := context.WithCancel(ctx)
+	projectorDone := make(chan struct{})
+	go func() {
+		projection.New(repo, &cfg.Projection).Run(projectorCtx)
+		close(projectorDone)
+	}()
+
+	normalizer := normalize.New(&cfg.Normalization)
+
+	service := service.New(repo, normalizer)
+	handler := transport.NewHandler(service, &cfg.Feed)
+	grpcHandler := transport.NewGRPCHandler(handler)
+	grpcServer := server.NewGRPC(&cfg.GRPC, grpcHandler.Register,
+		grpc.ChainUnaryInterceptor(transport.RecoveryInterceptor),
+		grpc.ChainStreamInterceptor(transport.StreamRecoveryInterceptor),
+	)
 	server := server.New(handler, &cfg.HTTP)
 
 	server.Run()
+	grpcServer.Run()
 	logger.InfoKV(ctx, "Starting server")
 
 	quit := make(chan os.Signal, 1)
@@ -59,11 +83,45 @@ func Run() {
 		logger.InfoKV(ctx, "Starting gracefully shutdown")
 	case err = <-server.Notify():
 		logger.FatalKV(ctx, "Failed starting server", "err", err.Error())
+	case err = <-grpcServer.Notify():
+		logger.FatalKV(ctx, "Failed starting grpc server", "err", err.Error())
 	}
 
+	handler.Close()
+	grpcServer.Shutdown()
 	if err := server.Shutdown(); err != nil {
 		logger.FatalKV(ctx, "Failed shutdown server", "err", err.Error())
 	}
+
+	stopProjector()
+	<-projectorDone
+}
+
+// Rebuild replays the whole change log into the read model and exits
+func Rebuild() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGQUIT, os.Interrupt)
+	defer stop()
+
+	cfg := config.Get()
+
+	syncLogger := initLogger(ctx, cfg)
+	defer syncLogger()
+
+	err := migrations.Do(&cfg.DB)
+	if err != nil {
+		logger.FatalKV(ctx, "Failed init migrations", "err", err)
+	}
+
+	db, err := postgres.New(&cfg.DB)
+	if err != nil {
+		logger.FatalKV(ctx, "Failed connect database", "err", err)
+	}
+
+	logger.InfoKV(ctx, "Starting rebuild of read model")
+	if err = projection.New(dbrepo.New(db), &cfg.Projection).Rebuild(ctx); err != nil {
+		logger.FatalKV(ctx, "Failed rebuild read model", "err", err)
+	}
+	logger.InfoKV(ctx, "Read model is rebuilt")
 }
 
 func initLogger(ctx context.Context, cfg *config.Config) (syncFn func()) {
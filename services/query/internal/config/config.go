@@ -8,6 +8,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pintoter/persons/pkg/normalize"
 	"github.com/spf13/viper"
 )
 
@@ -45,6 +46,20 @@ func (h *HTTP) GetShutdownTimeout() time.Duration {
 	return h.ShutdownTimeout
 }
 
+type GRPC struct {
+	Host            string
+	Port            string
+	ShutdownTimeout time.Duration
+}
+
+func (g *GRPC) GetAddr() string {
+	return fmt.Sprintf("%s:%s", g.Host, g.Port)
+}
+
+func (g *GRPC) GetShutdownTimeout() time.Duration {
+	return g.ShutdownTimeout
+}
+
 type DB struct {
 	User            string
 	Password        string
@@ -96,11 +111,49 @@ func (c *Client) GetTimeout() time.Duration {
 	return c.Timeout
 }
 
+// Normalization is shared with the other service
+type Normalization = normalize.Settings
+
+type Projection struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+func (p *Projection) GetInterval() time.Duration {
+	return p.Interval
+}
+
+func (p *Projection) GetBatchSize() int {
+	return p.BatchSize
+}
+
+type Feed struct {
+	PollInterval time.Duration
+	Heartbeat    time.Duration
+	BatchSize    int
+}
+
+func (f *Feed) GetPollInterval() time.Duration {
+	return f.PollInterval
+}
+
+func (f *Feed) GetHeartbeat() time.Duration {
+	return f.Heartbeat
+}
+
+func (f *Feed) GetBatchSize() int {
+	return f.BatchSize
+}
+
 type Config struct {
-	HTTP    HTTP
-	DB      DB
-	Project Project
-	Client  Client
+	HTTP          HTTP
+	GRPC          GRPC
+	DB            DB
+	Project       Project
+	Client        Client
+	Normalization Normalization
+	Projection    Projection
+	Feed          Feed
 }
 
 var config = new(Config)
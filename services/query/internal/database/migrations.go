@@ -10,6 +10,9 @@ import (
 
 const (
 	sourceURL = "file://migrations"
+	// migrationsTable keeps versions apart from the command service migrations
+	// applied to the same database
+	migrationsTable = "query_schema_migrations"
 )
 
 type Config interface {
@@ -17,7 +20,7 @@ type Config interface {
 }
 
 func Do(cfg Config) error {
-	m, err := migrate.New(sourceURL, cfg.GetDSN())
+	m, err := migrate.New(sourceURL, cfg.GetDSN()+"&x-migrations-table="+migrationsTable)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+const (
+	ChangeUpsert    = "upsert"
+	ChangeTombstone = "tombstone"
+)
+
+// Change is the latest state of a person changed since a sync token. Deleted
+// persons are reported as tombstones without person data.
+type Change struct {
+	Type       string    `json:"type"`
+	PersonID   int       `json:"person_id"`
+	Person     *Person   `json:"person,omitempty"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+	EventID    int64     `json:"event_id"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// ChangeSet is a page of changes. NextToken continues after the last change.
+type ChangeSet struct {
+	Changes   []Change
+	NextToken int64
+	HasMore   bool
+}
@@ -7,4 +7,18 @@ var (
 	ErrInternalService = errors.New("unexpected server error")
 	ErrInvalidInput    = errors.New("invalid input parameters")
 	ErrInvalidQueryId  = errors.New("invalid ID")
+	ErrPersonMerged    = errors.New("person was merged into another person")
 )
+
+// MergedError is returned for persons merged into the survivor with ID Into
+type MergedError struct {
+	Into int
+}
+
+func (e *MergedError) Error() string {
+	return ErrPersonMerged.Error()
+}
+
+func (e *MergedError) Is(target error) bool {
+	return target == ErrPersonMerged
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// PersonEvent is a change of person delivered by the change feed
+type PersonEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	PersonID   int       `json:"person_id"`
+	Person     *Person   `json:"person,omitempty"`
+	MergedInto *int      `json:"merged_into,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HistoryEntry is a recorded change of a person
+type HistoryEntry struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
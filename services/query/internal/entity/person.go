@@ -18,4 +18,15 @@ type Person struct {
 	Age         int           `json:"age"`
 	Gender      string        `json:"gender"`
 	Nationalize []Nationality `json:"nationalize"`
+	// Score is the similarity to the search query, set by searches only
+	Score float64 `json:"score,omitempty"`
+	// Match is the spelling of the full name matched by the search query,
+	// set by searches only
+	Match *Match `json:"match,omitempty"`
+}
+
+// Match is a spelling of a full name: the original one or a transliteration
+type Match struct {
+	Scheme string `json:"scheme"`
+	Text   string `json:"text"`
 }
@@ -0,0 +1,63 @@
+// Package projection maintains the person_view read model from the change
+// events of the command service
+package projection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/pintoter/persons/services/query/internal/entity"
+)
+
+// Name identifies the person_view projection checkpoint
+const Name = "person_view"
+
+const (
+	PersonCreated  = "PersonCreated"
+	PersonUpdated  = "PersonUpdated"
+	PersonDeleted  = "PersonDeleted"
+	PersonEnriched = "PersonEnriched"
+)
+
+// ErrCheckpointMoved is returned when the checkpoint was changed by another
+// projector or a rebuild while events were applied
+var ErrCheckpointMoved = errors.New("projection checkpoint moved")
+
+// Event is a person change read from the outbox of the command service
+type Event struct {
+	ID         int64
+	Type       string
+	PersonID   int
+	Payload    json.RawMessage
+	OccurredAt time.Time
+}
+
+// Payload is the part of event body the projection needs
+type Payload struct {
+	Person     *entity.Person `json:"person"`
+	MergedInto *int           `json:"merged_into"`
+	// Phonetic holds phonetic keys of the person name and surname computed
+	// by the command service, events written before have none
+	Phonetic []string `json:"phonetic"`
+}
+
+// Snapshot bounds the transactions of the change log database: those below
+// Xmin are finished, Xmax is the next transaction id to be assigned
+type Snapshot struct {
+	Xmin uint64
+	Xmax uint64
+}
+
+// Store keeps the projection and gives access to the change log
+type Store interface {
+	Checkpoint(ctx context.Context, name string) (int64, error)
+	HeadEvent(ctx context.Context) (int64, error)
+	Snapshot(ctx context.Context) (Snapshot, error)
+	EventsAfter(ctx context.Context, id int64, limit int) ([]Event, error)
+	// ApplyEvents projects events and moves the checkpoint from the given
+	// value to the last event in one transaction
+	ApplyEvents(ctx context.Context, name string, checkpoint int64, events []Event) error
+	ResetProjection(ctx context.Context, name string) error
+}
@@ -0,0 +1,202 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/pintoter/persons/pkg/logger"
+)
+
+var (
+	lagEvents     = expvar.NewInt("projection_lag_events")
+	lagSeconds    = expvar.NewFloat("projection_lag_seconds")
+	skippedEvents = expvar.NewInt("projection_skipped_events")
+)
+
+type Config interface {
+	GetInterval() time.Duration
+	GetBatchSize() int
+}
+
+// Projector applies change events to the projection in the order of the
+// change log. Event ids come from a sequence, so a transaction committed
+// later can fill a gap below the checkpoint. When a gap is seen, the next
+// transaction id is remembered as its horizon: events are written after the
+// change itself, so the transactions that took the missing ids already had
+// transaction ids below it. The gap is skipped as rolled back only after
+// every transaction below the horizon has finished and the ids are still
+// missing.
+type Projector struct {
+	store     Store
+	interval  time.Duration
+	batchSize int
+	gap       *gap
+	now       func() time.Time
+}
+
+// gap is the first missing event id the projector waits for
+type gap struct {
+	from    int64
+	horizon uint64
+}
+
+func New(store Store, cfg Config) *Projector {
+	return &Projector{
+		store:     store,
+		interval:  cfg.GetInterval(),
+		batchSize: cfg.GetBatchSize(),
+		now:       time.Now,
+	}
+}
+
+// Run keeps the projection up to date until ctx is done
+func (p *Projector) Run(ctx context.Context) {
+	layer := "projection.Run"
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		applied, _, err := p.step(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorKV(ctx, "project events", "layer", layer, "err", err)
+		}
+
+		// full batch means there are probably more events waiting
+		if err == nil && applied == p.batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(p.interval)
+		}
+	}
+}
+
+// Rebuild drops the projection and replays the whole change log
+func (p *Projector) Rebuild(ctx context.Context) error {
+	if err := p.store.ResetProjection(ctx, Name); err != nil {
+		return err
+	}
+	return p.CatchUp(ctx)
+}
+
+// CatchUp applies events until the projection reaches the head of the
+// change log
+func (p *Projector) CatchUp(ctx context.Context) error {
+	for {
+		applied, behind, err := p.step(ctx)
+		if err != nil {
+			return err
+		}
+		if behind == 0 {
+			return nil
+		}
+		if applied == 0 {
+			// waiting for a gap to be filled or expired
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(p.interval):
+			}
+		}
+	}
+}
+
+// step applies the next batch of events. It returns how many events were
+// applied and how far the projection is behind the head of the change log.
+func (p *Projector) step(ctx context.Context) (applied int, behind int64, err error) {
+	checkpoint, err := p.store.Checkpoint(ctx, Name)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// taken before the events are read, so transactions finished by then
+	// have their events in the batch
+	snapshot, err := p.store.Snapshot(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	events, err := p.store.EventsAfter(ctx, checkpoint, p.batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ready, err := p.contiguous(ctx, checkpoint, events, snapshot)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(ready) > 0 {
+		err = p.store.ApplyEvents(ctx, Name, checkpoint, ready)
+		switch {
+		case errors.Is(err, ErrCheckpointMoved):
+			// someone else projected the events, start over from the new checkpoint
+			ready = nil
+		case err != nil:
+			return 0, 0, err
+		default:
+			checkpoint = ready[len(ready)-1].ID
+		}
+	}
+
+	behind, err = p.updateLag(ctx, checkpoint, events[len(ready):])
+	return len(ready), behind, err
+}
+
+// contiguous returns the prefix of events without gaps that may still be
+// filled
+func (p *Projector) contiguous(ctx context.Context, checkpoint int64, events []Event, snapshot Snapshot) ([]Event, error) {
+	layer := "projection.contiguous"
+
+	next := checkpoint + 1
+	for i, event := range events {
+		if event.ID != next {
+			if p.gap == nil || p.gap.from != next {
+				// the horizon is taken after the events are read, when the
+				// missing ids had been taken already
+				after, err := p.store.Snapshot(ctx)
+				if err != nil {
+					return nil, err
+				}
+				p.gap = &gap{from: next, horizon: after.Xmax}
+				return events[:i], nil
+			}
+			if snapshot.Xmin < p.gap.horizon {
+				return events[:i], nil
+			}
+
+			for id := next; id < event.ID; id++ {
+				logger.WarnKV(ctx, "skip missing event", "layer", layer, "id", id)
+				skippedEvents.Add(1)
+			}
+			p.gap = nil
+		}
+		next = event.ID + 1
+	}
+	return events, nil
+}
+
+// updateLag publishes the lag metrics. Lag in events counts ids, so ids of
+// rolled back transactions are included.
+func (p *Projector) updateLag(ctx context.Context, checkpoint int64, pending []Event) (int64, error) {
+	head, err := p.store.HeadEvent(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	behind := max(head-checkpoint, 0)
+	lagEvents.Set(behind)
+	if len(pending) > 0 {
+		lagSeconds.Set(p.now().Sub(pending[0].OccurredAt).Seconds())
+	} else {
+		lagSeconds.Set(0)
+	}
+	return behind, nil
+}
@@ -0,0 +1,180 @@
+package projection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	checkpoint int64
+	log        []Event
+	applied    []int64
+	moved      bool
+	snapshot   Snapshot
+}
+
+func (s *fakeStore) Checkpoint(_ context.Context, _ string) (int64, error) {
+	return s.checkpoint, nil
+}
+
+func (s *fakeStore) HeadEvent(_ context.Context) (int64, error) {
+	if len(s.log) == 0 {
+		return 0, nil
+	}
+	return s.log[len(s.log)-1].ID, nil
+}
+
+func (s *fakeStore) Snapshot(_ context.Context) (Snapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s *fakeStore) EventsAfter(_ context.Context, id int64, limit int) ([]Event, error) {
+	var events []Event
+	for _, event := range s.log {
+		if event.ID > id && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *fakeStore) ApplyEvents(_ context.Context, _ string, checkpoint int64, events []Event) error {
+	if s.moved || checkpoint != s.checkpoint {
+		s.moved = false
+		return ErrCheckpointMoved
+	}
+	for _, event := range events {
+		s.applied = append(s.applied, event.ID)
+	}
+	s.checkpoint = events[len(events)-1].ID
+	return nil
+}
+
+func (s *fakeStore) ResetProjection(_ context.Context, _ string) error {
+	s.checkpoint = 0
+	s.applied = nil
+	return nil
+}
+
+type config struct{}
+
+func (config) GetInterval() time.Duration { return time.Millisecond }
+func (config) GetBatchSize() int          { return 2 }
+
+var now = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func newProjector(store Store) *Projector {
+	p := New(store, config{})
+	p.now = func() time.Time { return now }
+	return p
+}
+
+func Test_step(t *testing.T) {
+	tests := []struct {
+		name        string
+		store       *fakeStore
+		wantApplied []int64
+		gap         *gap
+		wantBehind  int64
+		wantLag     float64
+		wantGap     *gap
+		wantSkipped int64
+	}{
+		{
+			name: "Batch",
+			store: &fakeStore{log: []Event{
+				{ID: 1, OccurredAt: now}, {ID: 2, OccurredAt: now}, {ID: 3, OccurredAt: now},
+			}},
+			wantApplied: []int64{1, 2},
+			wantBehind:  1,
+		},
+		{
+			name: "WaitsForNewGap",
+			store: &fakeStore{checkpoint: 1, snapshot: Snapshot{Xmin: 10, Xmax: 10}, log: []Event{
+				{ID: 3, OccurredAt: now.Add(-time.Second)}, {ID: 4, OccurredAt: now},
+			}},
+			wantBehind: 3,
+			wantLag:    1,
+			wantGap:    &gap{from: 2, horizon: 10},
+		},
+		{
+			name: "WaitsForRunningTransactions",
+			store: &fakeStore{checkpoint: 1, snapshot: Snapshot{Xmin: 9, Xmax: 12}, log: []Event{
+				{ID: 3, OccurredAt: now.Add(-time.Minute)}, {ID: 4, OccurredAt: now},
+			}},
+			gap:        &gap{from: 2, horizon: 10},
+			wantBehind: 3,
+			wantLag:    60,
+			wantGap:    &gap{from: 2, horizon: 10},
+		},
+		{
+			name: "SkipsGapOfFinishedTransactions",
+			store: &fakeStore{checkpoint: 1, snapshot: Snapshot{Xmin: 10, Xmax: 12}, log: []Event{
+				{ID: 3, OccurredAt: now}, {ID: 4, OccurredAt: now},
+			}},
+			gap:         &gap{from: 2, horizon: 10},
+			wantApplied: []int64{3, 4},
+			wantSkipped: 1,
+		},
+		{
+			name: "WaitsForGapFilledMeanwhile",
+			store: &fakeStore{checkpoint: 1, snapshot: Snapshot{Xmin: 10, Xmax: 12}, log: []Event{
+				{ID: 2, OccurredAt: now}, {ID: 4, OccurredAt: now},
+			}},
+			gap:         &gap{from: 2, horizon: 10},
+			wantApplied: []int64{2},
+			wantBehind:  2,
+			wantGap:     &gap{from: 3, horizon: 12},
+		},
+		{
+			name: "CheckpointMoved",
+			store: &fakeStore{moved: true, log: []Event{
+				{ID: 1, OccurredAt: now},
+			}},
+			wantBehind: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProjector(tt.store)
+			p.gap = tt.gap
+			skipped := skippedEvents.Value()
+
+			applied, behind, err := p.step(context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.wantApplied), applied)
+			assert.Equal(t, tt.wantApplied, tt.store.applied)
+			assert.Equal(t, tt.wantBehind, behind)
+			assert.Equal(t, tt.wantBehind, lagEvents.Value())
+			assert.Equal(t, tt.wantLag, lagSeconds.Value())
+			assert.Equal(t, tt.wantGap, p.gap)
+			assert.Equal(t, tt.wantSkipped, skippedEvents.Value()-skipped)
+		})
+	}
+}
+
+func Test_Rebuild(t *testing.T) {
+	store := &fakeStore{
+		checkpoint: 5,
+		snapshot:   Snapshot{Xmin: 7, Xmax: 7},
+		applied:    []int64{1, 2, 4, 5},
+		log: []Event{
+			{ID: 1, OccurredAt: now.Add(-time.Hour)},
+			{ID: 2, OccurredAt: now.Add(-time.Hour)},
+			{ID: 4, OccurredAt: now.Add(-time.Hour)},
+			{ID: 5, OccurredAt: now.Add(-time.Hour)},
+			{ID: 6, OccurredAt: now},
+		},
+	}
+
+	err := newProjector(store).Rebuild(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 4, 5, 6}, store.applied)
+	assert.Equal(t, int64(6), store.checkpoint)
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+)
+
+func getChangesBuilder(since, until int64, limit int) (string, []interface{}, error) {
+	builder := sq.Select(viewColumns...).
+		Columns("deleted", "merged_into", "last_event_id", "updated_at").
+		From(viewTable).
+		Where(sq.Gt{"last_event_id": since}).
+		Where(sq.LtOrEq{"last_event_id": until}).
+		PlaceholderFormat(sq.Dollar)
+
+	// a client syncing from scratch has nothing to delete
+	if since == 0 {
+		builder = builder.Where(sq.Eq{"deleted": false})
+	}
+
+	return builder.
+		OrderBy("last_event_id").
+		Limit(uint64(limit)).
+		ToSql()
+}
+
+// GetChanges returns persons whose last applied event is in (since, until],
+// in the order of the events. Every person appears once with its latest
+// state.
+func (r *DBRepo) GetChanges(ctx context.Context, since, until int64, limit int) ([]entity.Change, error) {
+	logMethod := "repository.GetChanges"
+
+	query, args, err := getChangesBuilder(since, until, limit)
+	logger.DebugKV(ctx, "get changes builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []entity.Change{}
+	for rows.Next() {
+		var deleted bool
+		var mergedInto sql.NullInt64
+		var eventID int64
+		var changedAt time.Time
+		person, err := scanPerson(rows, &deleted, &mergedInto, &eventID, &changedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		change := entity.Change{Type: entity.ChangeUpsert, PersonID: person.ID, Person: &person, EventID: eventID, ChangedAt: changedAt}
+		if deleted {
+			change.Type, change.Person = entity.ChangeTombstone, nil
+			if mergedInto.Valid {
+				into := int(mergedInto.Int64)
+				change.MergedInto = &into
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
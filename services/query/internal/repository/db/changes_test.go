@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getChangesBuilder(t *testing.T) {
+	query, args, err := getChangesBuilder(0, 9, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize, deleted, merged_into, last_event_id, updated_at "+
+		"FROM person_view WHERE last_event_id > $1 AND last_event_id <= $2 AND deleted = $3 ORDER BY last_event_id LIMIT 11", query)
+	assert.Equal(t, []interface{}{int64(0), int64(9), false}, args)
+
+	query, args, err = getChangesBuilder(4, 9, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize, deleted, merged_into, last_event_id, updated_at "+
+		"FROM person_view WHERE last_event_id > $1 AND last_event_id <= $2 ORDER BY last_event_id LIMIT 11", query)
+	assert.Equal(t, []interface{}{int64(4), int64(9)}, args)
+}
+
+func Test_GetChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	columns := []string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize", "deleted", "merged_into", "last_event_id", "updated_at"}
+	changedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mergedInto := 2
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT person_id, name, surname, patronymic, age, gender, nationalize, deleted, merged_into, last_event_id, updated_at FROM person_view")).
+		WithArgs(int64(4), int64(9)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(2, "Anna", "Petrova", "", 25, "female", []byte(`[{"country_id":"RU","probability":0.9}]`), false, nil, 5, changedAt).
+			AddRow(1, "Ivan", "Ivanov", "", 30, "male", []byte(`[]`), true, mergedInto, 7, changedAt))
+
+	changes, err := r.GetChanges(context.Background(), 4, 9, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Change{
+		{
+			Type:     entity.ChangeUpsert,
+			PersonID: 2,
+			Person: &entity.Person{ID: 2, Name: "Anna", Surname: "Petrova", Age: 25, Gender: "female",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}}},
+			EventID:   5,
+			ChangedAt: changedAt,
+		},
+		{Type: entity.ChangeTombstone, PersonID: 1, MergedInto: &mergedInto, EventID: 7, ChangedAt: changedAt},
+	}, changes)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+// versionsAsOf selects person snapshots valid at the given moment
+func versionsAsOf(asOf time.Time) sq.SelectBuilder {
+	return sq.Select("person_id", "name", "surname", "COALESCE(patronymic, '') AS patronymic", "age", "gender", "nationalize").
+		From(versionTable).
+		Where(sq.LtOrEq{"valid_from": asOf}).
+		Where(sq.Or{sq.Eq{"valid_to": nil}, sq.Gt{"valid_to": asOf}}).
+		PlaceholderFormat(sq.Dollar)
+}
+
+func getPersonAsOfBuilder(id int, asOf time.Time) (string, []interface{}, error) {
+	return versionsAsOf(asOf).
+		Where(sq.Eq{"person_id": id}).
+		ToSql()
+}
+
+func getPersonsAsOfBuilder(data *service.GetFilters) (string, []interface{}, error) {
+	builder, err := filterPersons(versionsAsOf(*data.AsOf), data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if data.Query != nil {
+		if builder, err = searchPersons(builder, data, versionKeys); err != nil {
+			return "", nil, err
+		}
+		builder = rankPersons(builder)
+	}
+	return pagePersons(builder, data).ToSql()
+}
+
+// GetPersonAsOf returns person as it was at the given moment
+func (r *DBRepo) GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (entity.Person, error) {
+	logMethod := "repository.GetPersonAsOf"
+
+	query, args, err := getPersonAsOfBuilder(id, asOf)
+	logger.DebugKV(ctx, "get as of builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return entity.Person{}, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return entity.Person{}, err
+		}
+		return entity.Person{}, entity.ErrPersonNotExists
+	}
+
+	return scanPerson(rows)
+}
+
+func (r *DBRepo) getPersonsAsOf(ctx context.Context, data *service.GetFilters) ([]entity.Person, error) {
+	logMethod := "repository.getPersonsAsOf"
+
+	query, args, err := getPersonsAsOfBuilder(data)
+	logger.DebugKV(ctx, "get persons as of builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	persons, err := scanPersons(rows, data)
+	if err != nil {
+		logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+		return nil, err
+	}
+
+	if len(persons) == 0 {
+		return nil, entity.ErrPersonNotExists
+	}
+
+	if data.Cursor != nil && data.Cursor.Backward {
+		slices.Reverse(persons)
+	}
+	return persons, nil
+}
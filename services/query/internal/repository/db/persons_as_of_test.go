@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetPersonAsOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	expectedQuery := "SELECT person_id, name, surname, COALESCE(patronymic, '') AS patronymic, age, gender, nationalize FROM person_version " +
+		"WHERE valid_from <= $1 AND (valid_to IS NULL OR valid_to > $2) AND person_id = $3"
+	columns := []string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}
+
+	tests := []struct {
+		name         string
+		id           int
+		mockBehavior func(id int)
+		want         entity.Person
+		wantErr      error
+	}{
+		{
+			name: "Success",
+			id:   1,
+			mockBehavior: func(id int) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "Ivan", "Ivanov", "", 18, "male", []byte(`[{"country_id":"RU","probability":0.2}]`))
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(asOf, asOf, id).WillReturnRows(rows)
+			},
+			want: entity.Person{
+				ID:          1,
+				Name:        "Ivan",
+				Surname:     "Ivanov",
+				Age:         18,
+				Gender:      "male",
+				Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.2}},
+			},
+		},
+		{
+			name: "NotExisted",
+			id:   2,
+			mockBehavior: func(id int) {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(asOf, asOf, id).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: entity.ErrPersonNotExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior(tt.id)
+
+			got, err := r.GetPersonAsOf(context.Background(), tt.id, asOf)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_getPersonsAsOfBuilder(t *testing.T) {
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	query, args, err := getPersonsAsOfBuilder(&service.GetFilters{
+		Surname:     GetAddress("Ivanov"),
+		Nationalize: []string{"RU"},
+		AsOf:        &asOf,
+		Limit:       5,
+		Offset:      10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, COALESCE(patronymic, '') AS patronymic, age, gender, nationalize FROM person_version "+
+		"WHERE valid_from <= $1 AND (valid_to IS NULL OR valid_to > $2) AND surname = $3 AND nationalize @> $4::jsonb "+
+		"ORDER BY person_id LIMIT 5 OFFSET 10", query)
+	assert.Equal(t, []interface{}{asOf, asOf, "Ivanov", `[{"country_id":"RU"}]`}, args)
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+// countPersonsBuilder counts persons matching the filters, the cursor and
+// the page are ignored
+func countPersonsBuilder(data *service.GetFilters) (string, []interface{}, error) {
+	builder := sq.Select("COUNT(*)").
+		From(viewTable).
+		Where(sq.Eq{"deleted": false}).
+		PlaceholderFormat(sq.Dollar)
+	keys := viewKeys
+	if data.AsOf != nil {
+		builder = versionsAsOf(*data.AsOf).RemoveColumns().Column("COUNT(*)")
+		keys = versionKeys
+	}
+
+	builder, err := filterPersons(builder, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if data.Query != nil {
+		if builder, err = searchPersons(builder, data, keys); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return builder.ToSql()
+}
+
+// CountPersons returns the number of persons matching the filters, as they
+// were at the filters moment if it's set
+func (r *DBRepo) CountPersons(ctx context.Context, data *service.GetFilters) (int64, error) {
+	logMethod := "repository.CountPersons"
+
+	query, args, err := countPersonsBuilder(data)
+	logger.DebugKV(ctx, "count persons builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err = r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		logger.DebugKV(ctx, "scan count", "layer", logMethod, "err", err)
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_countPersonsBuilder(t *testing.T) {
+	query, args, err := countPersonsBuilder(&service.GetFilters{
+		Surname: GetAddress("Ivanov"),
+		Cursor:  &service.Cursor{},
+		Limit:   5,
+		Offset:  10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM person_view WHERE deleted = $1 AND surname = $2", query)
+	assert.Equal(t, []interface{}{false, "Ivanov"}, args)
+
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	query, args, err = countPersonsBuilder(&service.GetFilters{
+		Nationalize: []string{"RU"},
+		AsOf:        &asOf,
+		Limit:       5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM person_version "+
+		"WHERE valid_from <= $1 AND (valid_to IS NULL OR valid_to > $2) AND nationalize @> $3::jsonb", query)
+	assert.Equal(t, []interface{}{asOf, asOf, `[{"country_id":"RU"}]`}, args)
+}
+
+func Test_CountPersons(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM person_view WHERE deleted = $1 AND gender = $2")).
+		WithArgs(false, "male").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	total, err := r.CountPersons(context.Background(), &service.GetFilters{Gender: []string{"male"}, Limit: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM person_view WHERE deleted = $1")).
+		WithArgs(false).
+		WillReturnError(context.DeadlineExceeded)
+
+	_, err = r.CountPersons(context.Background(), &service.GetFilters{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pintoter/persons/pkg/logger"
@@ -10,164 +13,371 @@ import (
 	"github.com/pintoter/persons/services/query/internal/service"
 )
 
-func getPersonBuilder(id int) (string, []interface{}, error) {
-	builder := sq.Select("person.id", "person.name", "person.surname", "person.patronymic", "person.age", "person.gender", "n.nationalize, n.probability").
-		From(personTable).
-		Join("person_nationality n ON n.person_id = person.id").
-		Where(sq.Eq{"person.id": id}).
-		PlaceholderFormat(sq.Dollar)
+var viewColumns = []string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}
 
-	return builder.ToSql()
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (r *DBRepo) GetPerson(ctx context.Context, id int) (entity.Person, error) {
-	logMethod := "repository.GetPerson"
-
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelReadCommitted,
-		ReadOnly:  false,
-	})
-	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
-	if err != nil {
+// scanPerson scans person columns with nationalities as JSONB, followed by
+// extra columns
+func scanPerson(row rowScanner, extra ...any) (entity.Person, error) {
+	var person entity.Person
+	var nationalize []byte
+	dest := append([]any{&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender, &nationalize}, extra...)
+	if err := row.Scan(dest...); err != nil {
 		return entity.Person{}, err
 	}
-	defer func() { _ = tx.Rollback() }()
 
-	query, args, err := getPersonBuilder(id)
-	logger.DebugKV(ctx, "get builder", "layer", logMethod, "query", query, "args", args, "err", err)
-	if err != nil {
+	if err := json.Unmarshal(nationalize, &person.Nationalize); err != nil {
 		return entity.Person{}, err
 	}
+	return person, nil
+}
 
-	rows, err := tx.QueryContext(ctx, query, args...)
-	logger.DebugKV(ctx, "query to person_nationality", "layer", logMethod, "err", err)
-	if err != nil || rows == nil {
-		return entity.Person{}, err
+// patronymicColumn treats missing patronymic of snapshots as empty
+const patronymicColumn = "COALESCE(patronymic, '')"
+
+// anyOf compares a column with the single value, or with the set otherwise
+func anyOf(values []string) any {
+	if len(values) == 1 {
+		return values[0]
 	}
-	defer rows.Close()
+	return values
+}
 
-	areRowsExist := false
-	var person entity.Person
+// nationalityContains matches nationalities containing the country
+func nationalityContains(country string) (string, error) {
+	value, err := json.Marshal([]map[string]string{{"country_id": country}})
+	return string(value), err
+}
+
+// primaryNationality selects the most probable nationality, ties are broken
+// by the country code
+const primaryNationality = "SELECT n->>'country_id' AS country_id, (n->>'probability')::float8 AS probability FROM jsonb_array_elements(nationalize) AS n ORDER BY 2 DESC, 1 LIMIT 1"
+
+// scanPersons scans persons of a page, with the matched spelling and the
+// score for searches
+func scanPersons(rows *sql.Rows, data *service.GetFilters) ([]entity.Person, error) {
+	var persons []entity.Person
 	for rows.Next() {
-		var nationalize string
-		var probability float64
-		err = rows.Scan(&person.ID, &person.Name, &person.Surname, &person.Patronymic, &person.Age, &person.Gender, &nationalize, &probability)
+		var person entity.Person
+		var err error
+		if data.Query != nil {
+			var match entity.Match
+			var score float64
+			if person, err = scanPerson(rows, &match.Scheme, &match.Text, &score); err == nil {
+				person.Match = &match
+				person.Score = score
+			}
+		} else {
+			person, err = scanPerson(rows)
+		}
 		if err != nil {
-			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
-			return entity.Person{}, err
+			return nil, err
 		}
-		person.Nationalize = append(person.Nationalize, entity.Nationality{Country: nationalize, Probability: probability})
-		areRowsExist = true
+		persons = append(persons, person)
 	}
+	return persons, rows.Err()
+}
 
-	if !areRowsExist {
-		return entity.Person{}, entity.ErrPersonNotExists
+// filterPersons applies filters shared by the read model and snapshots
+func filterPersons(builder sq.SelectBuilder, data *service.GetFilters) (sq.SelectBuilder, error) {
+	if data.Name != nil {
+		builder = builder.Where(sq.Eq{"name": *data.Name})
 	}
-
-	if err = rows.Err(); err != nil {
-		logger.DebugKV(ctx, "rows.Err()", "layer", logMethod, "err", err)
-		return entity.Person{}, err
+	if data.Surname != nil {
+		builder = builder.Where(sq.Eq{"surname": *data.Surname})
+	}
+	if data.Patronymic != nil && *data.Patronymic == "" {
+		builder = builder.Where(sq.Eq{patronymicColumn: ""})
+	} else if data.Patronymic != nil {
+		builder = builder.Where(sq.Eq{"patronymic": *data.Patronymic})
+	}
+	if data.Age != nil {
+		builder = builder.Where(sq.Eq{"age": *data.Age})
+	}
+	if data.AgeMin != nil {
+		builder = builder.Where(sq.GtOrEq{"age": *data.AgeMin})
+	}
+	if data.AgeMax != nil {
+		builder = builder.Where(sq.LtOrEq{"age": *data.AgeMax})
+	}
+	if data.Gender != nil {
+		builder = builder.Where(sq.Eq{"gender": anyOf(data.Gender)})
+	}
+	if data.Nationalize != nil && data.NationalizeMinProbability != nil {
+		cond := sq.And{
+			sq.Eq{"n->>'country_id'": anyOf(data.Nationalize)},
+			sq.GtOrEq{"(n->>'probability')::float8": *data.NationalizeMinProbability},
+		}
+		builder = builder.Where(sq.Expr("EXISTS (SELECT 1 FROM jsonb_array_elements(nationalize) AS n WHERE ?)", cond))
+	} else if data.Nationalize != nil {
+		var cond sq.Or
+		for _, country := range data.Nationalize {
+			value, err := nationalityContains(country)
+			if err != nil {
+				return builder, err
+			}
+			cond = append(cond, sq.Expr("nationalize @> ?::jsonb", value))
+		}
+		if len(cond) == 1 {
+			builder = builder.Where(cond[0])
+		} else {
+			builder = builder.Where(cond)
+		}
+	}
+	if data.PrimaryNationality != nil {
+		cond := sq.And{sq.Eq{"top.country_id": anyOf(data.PrimaryNationality)}}
+		if data.NationalizeMinProbability != nil {
+			cond = append(cond, sq.GtOrEq{"top.probability": *data.NationalizeMinProbability})
+		}
+		builder = builder.Where(sq.Expr(fmt.Sprintf("EXISTS (SELECT 1 FROM (%s) AS top WHERE ?)", primaryNationality), cond))
 	}
-	logger.DebugKV(ctx, "get builder", "layer", logMethod, "person", person)
 
-	return person, tx.Commit()
+	return excludePersons(builder, data.Exclude)
 }
 
-func getPersonsBuilder(data *service.GetFilters) (string, []interface{}, error) {
-	builder := sq.Select("person.id", "person.name", "person.surname", "person.patronymic", "person.age", "person.gender", "n.nationalize", "n.probability").
-		From(personTable).
-		Join("person_nationality n ON n.person_id = person.id").
-		PlaceholderFormat(sq.Dollar)
+// excludePersons skips persons with the excluded values
+func excludePersons(builder sq.SelectBuilder, exclude service.Exclusions) (sq.SelectBuilder, error) {
+	columns := []struct {
+		name   string
+		values []string
+	}{
+		{"name", exclude.Name},
+		{"surname", exclude.Surname},
+		{patronymicColumn, exclude.Patronymic},
+		{"gender", exclude.Gender},
+	}
+	for _, column := range columns {
+		if len(column.values) > 0 {
+			builder = builder.Where(sq.NotEq{column.name: anyOf(column.values)})
+		}
+	}
 
-	if data.Name != nil {
-		builder = builder.Where(sq.Eq{"person.name": *data.Name})
+	for _, country := range exclude.Nationalize {
+		value, err := nationalityContains(country)
+		if err != nil {
+			return builder, err
+		}
+		builder = builder.Where(sq.Expr("NOT nationalize @> ?::jsonb", value))
 	}
-	if data.Surname != nil {
-		builder = builder.Where(sq.Eq{"person.surname": *data.Surname})
+	return builder, nil
+}
+
+var sortColumns = map[string]string{
+	service.SortID:         "person_id",
+	service.SortName:       "name",
+	service.SortSurname:    "surname",
+	service.SortPatronymic: "patronymic",
+	service.SortAge:        "age",
+	service.SortGender:     "gender",
+	service.SortScore:      "score",
+}
+
+// orderPersons applies the keyset order
+func orderPersons(builder sq.SelectBuilder, order []service.SortField) sq.SelectBuilder {
+	for _, field := range order {
+		column := sortColumns[field.Field]
+		if field.Desc {
+			column += " DESC"
+		}
+		builder = builder.OrderBy(column)
 	}
-	if data.Patronymic != nil {
-		builder = builder.Where(sq.Eq{"person.patronymic": *data.Patronymic})
+	return builder
+}
+
+// keyValue returns the field of the cursor key
+func keyValue(key entity.Person, field string) any {
+	switch field {
+	case service.SortName:
+		return key.Name
+	case service.SortSurname:
+		return key.Surname
+	case service.SortPatronymic:
+		return key.Patronymic
+	case service.SortAge:
+		return key.Age
+	case service.SortGender:
+		return key.Gender
+	case service.SortScore:
+		return key.Score
+	default:
+		return key.ID
 	}
-	if data.Age != nil {
-		builder = builder.Where(sq.Eq{"person.age": *data.Age})
+}
+
+// afterKey selects persons after the cursor key in the order, or before it
+// for backward cursors. The key is compared field by field, the first field
+// that differs decides.
+func afterKey(order []service.SortField, cursor *service.Cursor) sq.Or {
+	var cond sq.Or
+	for i, field := range order {
+		and := sq.And{}
+		for _, prev := range order[:i] {
+			and = append(and, sq.Eq{sortColumns[prev.Field]: keyValue(cursor.Key, prev.Field)})
+		}
+
+		column, value := sortColumns[field.Field], keyValue(cursor.Key, field.Field)
+		if field.Desc == cursor.Backward {
+			and = append(and, sq.Gt{column: value})
+		} else {
+			and = append(and, sq.Lt{column: value})
+		}
+		cond = append(cond, and)
 	}
-	if data.Gender != nil {
-		builder = builder.Where(sq.Eq{"person.gender": *data.Gender})
+	return cond
+}
+
+// pagePersons orders persons and selects the page by the cursor or by the
+// offset. Persons before a backward cursor are selected in reverse order.
+func pagePersons(builder sq.SelectBuilder, data *service.GetFilters) sq.SelectBuilder {
+	order := service.KeysetOrder(data.Sort)
+	if data.Cursor == nil {
+		return orderPersons(builder, order).
+			Limit(uint64(data.Limit)).
+			Offset(uint64(data.Offset))
 	}
-	if data.Nationalize != nil {
-		builder = builder.Where(sq.Eq{"n.nationalize": *data.Nationalize})
+
+	builder = builder.Where(afterKey(order, data.Cursor))
+	if data.Cursor.Backward {
+		reversed := make([]service.SortField, len(order))
+		for i, field := range order {
+			reversed[i] = service.SortField{Field: field.Field, Desc: !field.Desc}
+		}
+		order = reversed
 	}
-	builder = builder.Limit(uint64(data.Limit)).Offset(uint64(data.Offset))
+	return orderPersons(builder, order).Limit(uint64(data.Limit))
+}
+
+func getPersonBuilder(id int) (string, []interface{}, error) {
+	builder := sq.Select(viewColumns...).
+		Columns("deleted", "merged_into").
+		From(viewTable).
+		Where(sq.Eq{"person_id": id}).
+		PlaceholderFormat(sq.Dollar)
+
 	return builder.ToSql()
 }
 
+func (r *DBRepo) GetPerson(ctx context.Context, id int) (entity.Person, error) {
+	logMethod := "repository.GetPerson"
+
+	query, args, err := getPersonBuilder(id)
+	logger.DebugKV(ctx, "get builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return entity.Person{}, err
+	}
+
+	var deleted bool
+	var mergedInto sql.NullInt64
+	person, err := scanPerson(r.db.QueryRowContext(ctx, query, args...), &deleted, &mergedInto)
+	if err != nil {
+		logger.DebugKV(ctx, "scan person", "layer", logMethod, "err", err)
+		return entity.Person{}, err
+	}
+
+	if deleted {
+		logger.DebugKV(ctx, "person is deleted", "layer", logMethod, "merged_into", mergedInto)
+		if mergedInto.Valid {
+			return entity.Person{}, &entity.MergedError{Into: int(mergedInto.Int64)}
+		}
+		return entity.Person{}, entity.ErrPersonNotExists
+	}
+	logger.DebugKV(ctx, "get person", "layer", logMethod, "person", person)
+
+	return person, nil
+}
+
+func getPersonsBuilder(data *service.GetFilters) (string, []interface{}, error) {
+	builder := sq.Select(viewColumns...).
+		From(viewTable).
+		Where(sq.Eq{"deleted": false}).
+		PlaceholderFormat(sq.Dollar)
+
+	builder, err := filterPersons(builder, data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if data.Query != nil {
+		if builder, err = searchPersons(builder, data, viewKeys); err != nil {
+			return "", nil, err
+		}
+		builder = rankPersons(builder)
+	}
+	return pagePersons(builder, data).ToSql()
+}
+
 func (r *DBRepo) GetPersons(ctx context.Context, data *service.GetFilters) ([]entity.Person, error) {
 	logMethod := "repository.GetPersons"
 
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelReadCommitted,
-		ReadOnly:  false,
-	})
-	if err != nil {
-		return nil, err
+	if data.AsOf != nil {
+		return r.getPersonsAsOf(ctx, data)
 	}
-	defer func() { _ = tx.Rollback() }()
 
 	query, args, err := getPersonsBuilder(data)
+	logger.DebugKV(ctx, "get persons builder", "layer", logMethod, "query", query, "args", args, "err", err)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := tx.QueryContext(ctx, query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	areRowsExist := false
-	isIdScanned := make(map[int]struct{})
-	var persons []entity.Person
-	var count int
-	for rows.Next() {
-		var id, age int
-		var name, surname, patronymic, gender string
-		var nationalize string
-		var probability float64
-
-		err = rows.Scan(&id, &name, &surname, &patronymic, &age, &gender, &nationalize, &probability)
-		if err != nil {
-			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
-			return nil, err
-		}
-
-		if id == 0 {
-			return nil, entity.ErrPersonNotExists
-		}
-
-		if _, ok := isIdScanned[id]; !ok {
-			isIdScanned[id] = struct{}{}
-			count++
-			persons = append(persons, entity.Person{
-				ID:         id,
-				Name:       name,
-				Surname:    surname,
-				Patronymic: patronymic,
-				Gender:     gender,
-				Age:        age,
-			})
-			areRowsExist = true
-		}
-		persons[count-1].Nationalize = append(persons[count-1].Nationalize, entity.Nationality{Country: nationalize, Probability: probability})
+	persons, err := scanPersons(rows, data)
+	if err != nil {
+		logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+		return nil, err
 	}
 	logger.DebugKV(ctx, "result of repo.GetPersons", "layer", logMethod, "persons", persons)
 
-	if !areRowsExist {
+	if len(persons) == 0 {
 		return nil, entity.ErrPersonNotExists
 	}
 
-	if err := rows.Err(); err != nil {
+	if data.Cursor != nil && data.Cursor.Backward {
+		slices.Reverse(persons)
+	}
+	return persons, nil
+}
+
+func getPersonsByIDsBuilder(ids []int) (string, []interface{}, error) {
+	return sq.Select(viewColumns...).
+		From(viewTable).
+		Where(sq.Eq{"person_id": ids, "deleted": false}).
+		OrderBy("person_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+}
+
+func (r *DBRepo) GetPersonsByIDs(ctx context.Context, ids []int) ([]entity.Person, error) {
+	logMethod := "repository.GetPersonsByIDs"
+
+	query, args, err := getPersonsByIDsBuilder(ids)
+	logger.DebugKV(ctx, "get persons by ids builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var persons []entity.Person
+	for rows.Next() {
+		person, err := scanPerson(rows)
+		if err != nil {
+			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+			return nil, err
+		}
+		persons = append(persons, person)
+	}
 
-	return persons, tx.Commit()
+	return persons, rows.Err()
 }
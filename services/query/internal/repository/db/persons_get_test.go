@@ -46,6 +46,10 @@ func Test_GetPerson(t *testing.T) {
 		},
 	}
 
+	viewColumns := []string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize", "deleted", "merged_into"}
+	expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize, deleted, merged_into
+	FROM person_view WHERE person_id = $1`
+
 	tests := []struct {
 		name         string
 		mockBehavior mockBehavior
@@ -56,9 +60,7 @@ func Test_GetPerson(t *testing.T) {
 		{
 			name: "Success",
 			mockBehavior: func(args args) {
-				mock.ExpectBegin()
-
-				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationalize", "probability"}).
+				rows := sqlmock.NewRows(viewColumns).
 					AddRow(
 						persons[0].ID,
 						persons[0].Name,
@@ -66,15 +68,12 @@ func Test_GetPerson(t *testing.T) {
 						persons[0].Patronymic,
 						persons[0].Age,
 						persons[0].Gender,
-						persons[0].Nationalize[0].Country,
-						persons[0].Nationalize[0].Probability,
+						[]byte(`[{"country_id":"RU","probability":0.1337}]`),
+						false,
+						nil,
 					)
 
-				expectedQuery := `SELECT person.id, person.name, person.surname, person.patronymic, person.age,
-				person.gender, n.nationalize, n.probability FROM person JOIN person_nationality n ON n.person_id = person.id WHERE person.id = $1`
 				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
-
-				mock.ExpectCommit()
 			},
 			args:       args{id: id},
 			wantPerson: persons[0],
@@ -82,20 +81,42 @@ func Test_GetPerson(t *testing.T) {
 		{
 			name: "Failed_NotFound",
 			mockBehavior: func(args args) {
-				mock.ExpectBegin()
-
-				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationalize", "probability"})
-
-				expectedQuery := `SELECT person.id, person.name, person.surname, person.patronymic, person.age,
-				person.gender, n.nationalize, n.probability FROM person JOIN person_nationality n ON n.person_id = person.id WHERE person.id = $1`
-				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnError(errors.New("some error")).WillReturnRows(rows)
-
-				mock.ExpectRollback()
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(sqlmock.NewRows(viewColumns))
 			},
 			args:       args{id: id},
 			wantPerson: entity.Person{},
 			wantErr:    true,
 		},
+		{
+			name: "Failed_Deleted",
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(viewColumns).
+					AddRow(args.id, "name", "surname", "patronymic", 18, "male", []byte(`[]`), true, nil)
+
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			args:    args{id: id},
+			wantErr: true,
+		},
+		{
+			name: "Failed_Merged",
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(viewColumns).
+					AddRow(args.id, "name", "surname", "patronymic", 18, "male", []byte(`[]`), true, 7)
+
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			args:    args{id: id},
+			wantErr: true,
+		},
+		{
+			name: "Failed",
+			mockBehavior: func(args args) {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnError(errors.New("some error"))
+			},
+			args:    args{id: id},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -185,52 +206,59 @@ func Test_GetPersons(t *testing.T) {
 			name: "Success",
 			args: args{
 				filters: &service.GetFilters{
-					Nationalize: GetAddress[string]("RU"),
+					Nationalize: []string{"RU"},
 					Limit:       5,
 					Offset:      0,
 				},
 			},
 			mockBehavior: func(args args) {
-				mock.ExpectBegin()
+				rows := sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"})
+				for _, person := range persons {
+					rows.AddRow(person.ID, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender,
+						[]byte(`[{"country_id":"RU","probability":0.1337}]`))
+				}
 
-				rows := sqlmock.NewRows([]string{"id", "name", "surname", "patronymic", "age", "gender", "nationalize", "probability"}).
-					AddRow(
-						persons[0].ID,
-						persons[0].Name,
-						persons[0].Surname,
-						persons[0].Patronymic,
-						persons[0].Age,
-						persons[0].Gender,
-						persons[0].Nationalize[0].Country,
-						persons[0].Nationalize[0].Probability,
-					).AddRow(
-					persons[1].ID,
-					persons[1].Name,
-					persons[1].Surname,
-					persons[1].Patronymic,
-					persons[1].Age,
-					persons[1].Gender,
-					persons[1].Nationalize[0].Country,
-					persons[1].Nationalize[0].Probability,
-				).AddRow(
-					persons[2].ID,
-					persons[2].Name,
-					persons[2].Surname,
-					persons[2].Patronymic,
-					persons[2].Age,
-					persons[2].Gender,
-					persons[2].Nationalize[0].Country,
-					persons[2].Nationalize[0].Probability,
-				)
-
-				expectedQuery := `SELECT person.id, person.name, person.surname, person.patronymic, person.age, person.gender, n.nationalize, n.probability 
-				FROM person JOIN person_nationality n ON n.person_id = person.id WHERE n.nationalize = $1 LIMIT 5 OFFSET 0`
-				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.filters.Nationalize).WillReturnRows(rows)
-
-				mock.ExpectCommit()
+				expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view
+				WHERE deleted = $1 AND nationalize @> $2::jsonb ORDER BY person_id LIMIT 5 OFFSET 0`
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, `[{"country_id":"RU"}]`).WillReturnRows(rows)
 			},
 			wantPersons: []entity.Person{persons[0], persons[1], persons[2]},
 		},
+		{
+			name: "SuccessWithoutNationalities",
+			args: args{
+				filters: &service.GetFilters{
+					Name:   GetAddress[string]("name"),
+					Limit:  5,
+					Offset: 5,
+				},
+			},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}).
+					AddRow(4, "name", "surname", "", 30, "female", []byte(`[]`))
+
+				expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view
+				WHERE deleted = $1 AND name = $2 ORDER BY person_id LIMIT 5 OFFSET 5`
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, "name").WillReturnRows(rows)
+			},
+			wantPersons: []entity.Person{{ID: 4, Name: "name", Surname: "surname", Age: 30, Gender: "female", Nationalize: []entity.Nationality{}}},
+		},
+		{
+			name: "FailedNotFound",
+			args: args{
+				filters: &service.GetFilters{
+					Age:   GetAddress[int](99),
+					Limit: 5,
+				},
+			},
+			mockBehavior: func(args args) {
+				expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view
+				WHERE deleted = $1 AND age = $2 ORDER BY person_id LIMIT 5 OFFSET 0`
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, 99).
+					WillReturnRows(sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}))
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -248,3 +276,177 @@ func Test_GetPersons(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPersonsBuilderFilters(t *testing.T) {
+	query, args, err := getPersonsBuilder(&service.GetFilters{
+		Patronymic:  GetAddress(""),
+		AgeMin:      GetAddress(20),
+		AgeMax:      GetAddress(30),
+		Gender:      []string{"male", "female"},
+		Nationalize: []string{"RU", "KZ"},
+		Exclude: service.Exclusions{
+			Surname:     []string{"Ivanov"},
+			Patronymic:  []string{"Ivanovich", "Petrovich"},
+			Nationalize: []string{"UA"},
+		},
+		Limit: 5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 AND COALESCE(patronymic, '') = $2 AND age >= $3 AND age <= $4 AND gender IN ($5,$6) "+
+		"AND (nationalize @> $7::jsonb OR nationalize @> $8::jsonb) AND surname <> $9 "+
+		"AND COALESCE(patronymic, '') NOT IN ($10,$11) AND NOT nationalize @> $12::jsonb "+
+		"ORDER BY person_id LIMIT 5 OFFSET 0", query)
+	assert.Equal(t, []interface{}{
+		false, "", 20, 30, "male", "female", `[{"country_id":"RU"}]`, `[{"country_id":"KZ"}]`,
+		"Ivanov", "Ivanovich", "Petrovich", `[{"country_id":"UA"}]`,
+	}, args)
+
+	query, args, err = getPersonsBuilder(&service.GetFilters{
+		Gender:  []string{"male"},
+		Exclude: service.Exclusions{Patronymic: []string{""}},
+		Limit:   5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 AND gender = $2 AND COALESCE(patronymic, '') <> $3 ORDER BY person_id LIMIT 5 OFFSET 0", query)
+	assert.Equal(t, []interface{}{false, "male", ""}, args)
+}
+
+func Test_getPersonsBuilderNationality(t *testing.T) {
+	query, args, err := getPersonsBuilder(&service.GetFilters{
+		Nationalize:               []string{"RU", "KZ"},
+		PrimaryNationality:        []string{"RU"},
+		NationalizeMinProbability: GetAddress(0.5),
+		Limit:                     5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 "+
+		"AND EXISTS (SELECT 1 FROM jsonb_array_elements(nationalize) AS n "+
+		"WHERE (n->>'country_id' IN ($2,$3) AND (n->>'probability')::float8 >= $4)) "+
+		"AND EXISTS (SELECT 1 FROM (SELECT n->>'country_id' AS country_id, (n->>'probability')::float8 AS probability "+
+		"FROM jsonb_array_elements(nationalize) AS n ORDER BY 2 DESC, 1 LIMIT 1) AS top "+
+		"WHERE (top.country_id = $5 AND top.probability >= $6)) "+
+		"ORDER BY person_id LIMIT 5 OFFSET 0", query)
+	assert.Equal(t, []interface{}{false, "RU", "KZ", 0.5, "RU", 0.5}, args)
+
+	query, args, err = getPersonsBuilder(&service.GetFilters{
+		PrimaryNationality: []string{"RU", "KZ"},
+		Limit:              5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 "+
+		"AND EXISTS (SELECT 1 FROM (SELECT n->>'country_id' AS country_id, (n->>'probability')::float8 AS probability "+
+		"FROM jsonb_array_elements(nationalize) AS n ORDER BY 2 DESC, 1 LIMIT 1) AS top "+
+		"WHERE (top.country_id IN ($2,$3))) "+
+		"ORDER BY person_id LIMIT 5 OFFSET 0", query)
+	assert.Equal(t, []interface{}{false, "RU", "KZ"}, args)
+}
+
+func Test_getPersonsBuilderSort(t *testing.T) {
+	query, _, err := getPersonsBuilder(&service.GetFilters{
+		Sort:  []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}},
+		Limit: 5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 ORDER BY age DESC, surname, person_id LIMIT 5 OFFSET 0", query)
+
+	query, _, err = getPersonsBuilder(&service.GetFilters{
+		Sort:  []service.SortField{{Field: service.SortID, Desc: true}},
+		Limit: 5,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 ORDER BY person_id DESC LIMIT 5 OFFSET 0", query)
+}
+
+func Test_getPersonsBuilderCursor(t *testing.T) {
+	key := entity.Person{ID: 7, Surname: "Ivanov", Age: 30}
+
+	query, args, err := getPersonsBuilder(&service.GetFilters{
+		Sort:   []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}},
+		Cursor: &service.Cursor{Key: key},
+		Limit:  6,
+		Offset: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 AND ((age < $2) OR (age = $3 AND surname > $4) OR (age = $5 AND surname = $6 AND person_id > $7)) "+
+		"ORDER BY age DESC, surname, person_id LIMIT 6", query)
+	assert.Equal(t, []interface{}{false, 30, 30, "Ivanov", 30, "Ivanov", 7}, args)
+
+	query, args, err = getPersonsBuilder(&service.GetFilters{
+		Cursor: &service.Cursor{Key: key, Backward: true},
+		Limit:  6,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view "+
+		"WHERE deleted = $1 AND ((person_id < $2)) ORDER BY person_id DESC LIMIT 6", query)
+	assert.Equal(t, []interface{}{false, 7}, args)
+}
+
+func Test_GetPersonsBackward(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view
+	WHERE deleted = $1 AND ((person_id < $2)) ORDER BY person_id DESC LIMIT 2`
+
+	rows := sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}).
+		AddRow(6, "Anna", "Petrova", "", 25, "female", []byte(`[]`)).
+		AddRow(5, "Ivan", "Ivanov", "", 30, "male", []byte(`[]`))
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, 7).WillReturnRows(rows)
+
+	persons, err := r.GetPersons(context.Background(), &service.GetFilters{
+		Cursor: &service.Cursor{Key: entity.Person{ID: 7}, Backward: true},
+		Limit:  2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Person{
+		{ID: 5, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male", Nationalize: []entity.Nationality{}},
+		{ID: 6, Name: "Anna", Surname: "Petrova", Age: 25, Gender: "female", Nationalize: []entity.Nationality{}},
+	}, persons)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_GetPersonsByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	expectedQuery := `SELECT person_id, name, surname, patronymic, age, gender, nationalize FROM person_view
+	WHERE deleted = $1 AND person_id IN ($2,$3) ORDER BY person_id`
+
+	rows := sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize"}).
+		AddRow(1, "Ivan", "Ivanov", "", 30, "male", []byte(`[{"country_id":"RU","probability":0.9}]`))
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, 1, 2).WillReturnRows(rows)
+
+	persons, err := r.GetPersonsByIDs(context.Background(), []int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Person{{
+		ID:          1,
+		Name:        "Ivan",
+		Surname:     "Ivanov",
+		Age:         30,
+		Gender:      "male",
+		Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}},
+	}}, persons)
+
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(false, 3, 4).WillReturnError(errors.New("some error"))
+
+	_, err = r.GetPersonsByIDs(context.Background(), []int{3, 4})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
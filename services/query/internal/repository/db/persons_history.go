@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+)
+
+func getHistoryBuilder(id int, limit, offset int64) (string, []interface{}, error) {
+	builder := sq.Select("id", "action", "actor", "request_id", "changes", "created_at").
+		From(auditTable).
+		Where(sq.Eq{"person_id": id}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// GetHistory returns changes of person, the newest first
+func (r *DBRepo) GetHistory(ctx context.Context, id int, limit, offset int64) ([]entity.HistoryEntry, error) {
+	logMethod := "repository.GetHistory"
+
+	query, args, err := getHistoryBuilder(id, limit, offset)
+	logger.DebugKV(ctx, "get history builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []entity.HistoryEntry{}
+	for rows.Next() {
+		var entry entity.HistoryEntry
+		var changes []byte
+		err = rows.Scan(&entry.ID, &entry.Action, &entry.Actor, &entry.RequestID, &changes, &entry.CreatedAt)
+		if err != nil {
+			logger.DebugKV(ctx, "rows.Scan", "layer", logMethod, "err", err)
+			return nil, err
+		}
+		entry.Changes = changes
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	type args struct {
+		id     int
+		limit  int64
+		offset int64
+	}
+
+	type mockBehavior func(args args)
+
+	expectedQuery := "SELECT id, action, actor, request_id, changes, created_at FROM person_audit WHERE person_id = $1 ORDER BY id DESC LIMIT 10 OFFSET 10"
+	columns := []string{"id", "action", "actor", "request_id", "changes", "created_at"}
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		args         args
+		mockBehavior mockBehavior
+		want         []entity.HistoryEntry
+		wantErr      bool
+	}{
+		{
+			name: "Success",
+			args: args{id: 1, limit: 10, offset: 10},
+			mockBehavior: func(args args) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, "update", "admin", "42", []byte(`{"surname":{"before":"Ivanov","after":"Petrov"}}`), createdAt).
+					AddRow(1, "create", "anonymous", "41", []byte(`{"name":{"before":null,"after":"Ivan"}}`), createdAt)
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(rows)
+			},
+			want: []entity.HistoryEntry{
+				{ID: 2, Action: "update", Actor: "admin", RequestID: "42", Changes: json.RawMessage(`{"surname":{"before":"Ivanov","after":"Petrov"}}`), CreatedAt: createdAt},
+				{ID: 1, Action: "create", Actor: "anonymous", RequestID: "41", Changes: json.RawMessage(`{"name":{"before":null,"after":"Ivan"}}`), CreatedAt: createdAt},
+			},
+		},
+		{
+			name: "SuccessEmpty",
+			args: args{id: 1, limit: 10, offset: 10},
+			mockBehavior: func(args args) {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want: []entity.HistoryEntry{},
+		},
+		{
+			name: "Failed",
+			args: args{id: 1, limit: 10, offset: 10},
+			mockBehavior: func(args args) {
+				mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(args.id).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior(tt.args)
+
+			got, err := r.GetHistory(context.Background(), tt.args.id, tt.args.limit, tt.args.offset)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
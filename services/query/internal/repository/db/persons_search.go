@@ -0,0 +1,119 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/phonetic"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"github.com/pintoter/persons/services/query/internal/translit"
+)
+
+// searchKeys are spellings of full names searches compare with: source lists
+// them as (scheme, text) rows, text is all of them in one indexed column for
+// prefiltering, empty when there is no such column
+type searchKeys struct {
+	source string
+	text   string
+}
+
+// The read model stores transliterations of full names, snapshots compute the
+// original spelling only
+var (
+	viewKeys = searchKeys{
+		source: "jsonb_to_recordset(search_keys) AS k(scheme text, text text)",
+		text:   "search_text",
+	}
+	versionKeys = searchKeys{
+		source: "(VALUES ('original', lower(name || ' ' || surname || ' ' || COALESCE(patronymic, '')))) AS k(scheme, text)",
+	}
+)
+
+// matchJoin joins the spelling of a person full name best matching a spelling
+// of the query, no row is joined for persons not matching any
+const matchJoin = "CROSS JOIN LATERAL (SELECT k.scheme, k.text, word_similarity(q.text, k.text) AS score " +
+	"FROM %s, (VALUES %s) AS q(text, pattern) " +
+	"WHERE k.text ~ q.pattern OR q.text <%% k.text ORDER BY score DESC LIMIT 1) AS m"
+
+// searchPersons selects persons matching the search query of the filters and
+// joins the match as m
+func searchPersons(builder sq.SelectBuilder, data *service.GetFilters, keys searchKeys) (sq.SelectBuilder, error) {
+	if data.Match == service.MatchPhonetic {
+		return searchPhonetic(builder, *data.Query)
+	}
+	return searchSpellings(builder, *data.Query, keys), nil
+}
+
+// searchSpellings selects persons with a spelling of the full name having a
+// part starting with a spelling of the query or similar to it. The best
+// matching spelling is joined as m
+func searchSpellings(builder sq.SelectBuilder, query string, keys searchKeys) sq.SelectBuilder {
+	variants := translit.Texts(query)
+
+	// \m matches the start of a word, queries hold letters, spaces, hyphens
+	// and apostrophes only, none of them is special in patterns
+	values := make([]string, len(variants))
+	args := make([]any, 0, 2*len(variants))
+	prefilter := sq.Or{}
+	for i, variant := range variants {
+		values[i] = "(?, ?)"
+		args = append(args, variant, `\m`+variant)
+		prefilter = append(prefilter, sq.Expr(fmt.Sprintf("%[1]s ~ ? OR ? <%% %[1]s", keys.text), `\m`+variant, variant))
+	}
+
+	builder = builder.JoinClause(fmt.Sprintf(matchJoin, keys.source, strings.Join(values, ", ")), args...)
+	if keys.text != "" {
+		builder = builder.Where(prefilter)
+	}
+	return builder
+}
+
+// phoneticName is the text phonetic matches are reported and ranked by, keys
+// are computed from the name and surname only
+const phoneticName = "lower(name || ' ' || surname)"
+
+// searchPhonetic selects persons having a phonetic key of every query word
+// among keys of their name and surname. The match is the name and surname
+// under the phonetic scheme
+func searchPhonetic(builder sq.SelectBuilder, query string) (sq.SelectBuilder, error) {
+	words := sq.And{}
+	for _, word := range strings.Fields(query) {
+		keys := sq.Or{}
+		for _, key := range phonetic.Encode(word) {
+			value, err := phoneticContains(key)
+			if err != nil {
+				return builder, err
+			}
+			keys = append(keys, sq.Expr("phonetic @> ?::jsonb", value))
+		}
+		if len(keys) == 0 {
+			// a word without letters sounds like nothing
+			keys = append(keys, sq.Expr("FALSE"))
+		}
+		words = append(words, keys)
+	}
+
+	return builder.
+		JoinClause(fmt.Sprintf("CROSS JOIN LATERAL (SELECT '%s' AS scheme, %[2]s AS text, word_similarity(?, %[2]s) AS score) AS m",
+			service.MatchPhonetic, phoneticName), query).
+		Where(words), nil
+}
+
+// phoneticContains returns the JSON array phonetic keys containing the key
+// are matched with
+func phoneticContains(key string) (string, error) {
+	value, err := json.Marshal([]string{key})
+	return string(value), err
+}
+
+// rankPersons adds the matched spelling and its similarity to the query as
+// match_scheme, match_text and score columns, persons are selected from a
+// subquery so the order and cursors can use them
+func rankPersons(builder sq.SelectBuilder) sq.SelectBuilder {
+	builder = builder.Columns("m.scheme AS match_scheme", "m.text AS match_text", "m.score AS score")
+	return sq.Select("*").
+		FromSelect(builder, "persons").
+		PlaceholderFormat(sq.Dollar)
+}
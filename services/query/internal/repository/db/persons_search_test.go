@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getPersonsBuilderSearch(t *testing.T) {
+	relevance := []service.SortField{{Field: service.SortScore, Desc: true}}
+	viewMatch := "CROSS JOIN LATERAL (SELECT k.scheme, k.text, word_similarity(q.text, k.text) AS score " +
+		"FROM jsonb_to_recordset(search_keys) AS k(scheme text, text text), (VALUES %s) AS q(text, pattern) " +
+		"WHERE k.text ~ q.pattern OR q.text <% k.text ORDER BY score DESC LIMIT 1) AS m"
+
+	query, args, err := getPersonsBuilder(&service.GetFilters{
+		Query:  GetAddress("ivan iv"),
+		Gender: []string{"male"},
+		Sort:   relevance,
+		Limit:  6,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT person_id, name, surname, patronymic, age, gender, nationalize, "+
+		"m.scheme AS match_scheme, m.text AS match_text, m.score AS score FROM person_view "+
+		strings.Replace(viewMatch, "%s", "($1, $2)", 1)+
+		" WHERE deleted = $3 AND gender = $4 AND (search_text ~ $5 OR $6 <% search_text)) AS persons "+
+		"ORDER BY score DESC, person_id LIMIT 6 OFFSET 0", query)
+	assert.Equal(t, []interface{}{"ivan iv", `\mivan iv`, false, "male", `\mivan iv`, "ivan iv"}, args)
+
+	query, args, err = getPersonsBuilder(&service.GetFilters{
+		Query:  GetAddress("юрий"),
+		Sort:   relevance,
+		Cursor: &service.Cursor{Key: entity.Person{ID: 7, Score: 0.5}},
+		Limit:  6,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT person_id, name, surname, patronymic, age, gender, nationalize, "+
+		"m.scheme AS match_scheme, m.text AS match_text, m.score AS score FROM person_view "+
+		strings.Replace(viewMatch, "%s", "($1, $2), ($3, $4), ($5, $6), ($7, $8), ($9, $10)", 1)+
+		" WHERE deleted = $11 AND (search_text ~ $12 OR $13 <% search_text OR search_text ~ $14 OR $15 <% search_text "+
+		"OR search_text ~ $16 OR $17 <% search_text OR search_text ~ $18 OR $19 <% search_text "+
+		"OR search_text ~ $20 OR $21 <% search_text)) AS persons "+
+		"WHERE ((score < $22) OR (score = $23 AND person_id > $24)) ORDER BY score DESC, person_id LIMIT 6", query)
+	assert.Equal(t, []interface{}{"юрий", `\mюрий`, "iurii", `\miurii`, "yurij", `\myurij`, "yuriy", `\myuriy`, "yury", `\myury`,
+		false, `\mюрий`, "юрий", `\miurii`, "iurii", `\myurij`, "yurij", `\myuriy`, "yuriy", `\myury`, "yury",
+		0.5, 0.5, 7}, args)
+
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	query, _, err = getPersonsAsOfBuilder(&service.GetFilters{
+		Query: GetAddress("iva"),
+		AsOf:  &asOf,
+		Limit: 6,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT person_id, name, surname, COALESCE(patronymic, '') AS patronymic, age, gender, nationalize, "+
+		"m.scheme AS match_scheme, m.text AS match_text, m.score AS score FROM person_version "+
+		"CROSS JOIN LATERAL (SELECT k.scheme, k.text, word_similarity(q.text, k.text) AS score "+
+		"FROM (VALUES ('original', lower(name || ' ' || surname || ' ' || COALESCE(patronymic, '')))) AS k(scheme, text), "+
+		"(VALUES ($1, $2)) AS q(text, pattern) "+
+		"WHERE k.text ~ q.pattern OR q.text <% k.text ORDER BY score DESC LIMIT 1) AS m "+
+		"WHERE valid_from <= $3 AND (valid_to IS NULL OR valid_to > $4)) AS persons "+
+		"ORDER BY person_id LIMIT 6 OFFSET 0", query)
+
+	query, args, err = countPersonsBuilder(&service.GetFilters{Query: GetAddress("iva"), Limit: 6})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM person_view "+strings.Replace(viewMatch, "%s", "($1, $2)", 1)+
+		" WHERE deleted = $3 AND (search_text ~ $4 OR $5 <% search_text)", query)
+	assert.Equal(t, []interface{}{"iva", `\miva`, false, `\miva`, "iva"}, args)
+}
+
+func Test_getPersonsBuilderPhonetic(t *testing.T) {
+	query, args, err := getPersonsBuilder(&service.GetFilters{
+		Query: GetAddress("jon shmidt"),
+		Match: service.MatchPhonetic,
+		Sort:  []service.SortField{{Field: service.SortScore, Desc: true}},
+		Limit: 6,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT person_id, name, surname, patronymic, age, gender, nationalize, "+
+		"m.scheme AS match_scheme, m.text AS match_text, m.score AS score FROM person_view "+
+		"CROSS JOIN LATERAL (SELECT 'phonetic' AS scheme, lower(name || ' ' || surname) AS text, "+
+		"word_similarity($1, lower(name || ' ' || surname)) AS score) AS m "+
+		"WHERE deleted = $2 AND ((phonetic @> $3::jsonb OR phonetic @> $4::jsonb) AND (phonetic @> $5::jsonb))) AS persons "+
+		"ORDER BY score DESC, person_id LIMIT 6 OFFSET 0", query)
+	assert.Equal(t, []interface{}{"jon shmidt", false, `["JN"]`, `["AN"]`, `["XMT"]`}, args)
+
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	query, args, err = countPersonsBuilder(&service.GetFilters{
+		Query: GetAddress("шмидт"),
+		Match: service.MatchPhonetic,
+		AsOf:  &asOf,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM person_version "+
+		"CROSS JOIN LATERAL (SELECT 'phonetic' AS scheme, lower(name || ' ' || surname) AS text, "+
+		"word_similarity($1, lower(name || ' ' || surname)) AS score) AS m "+
+		"WHERE valid_from <= $2 AND (valid_to IS NULL OR valid_to > $3) AND ((phonetic @> $4::jsonb))", query)
+	assert.Equal(t, []interface{}{"шмидт", asOf, asOf, `["шмит"]`}, args)
+}
+
+func Test_GetPersonsSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	rows := sqlmock.NewRows([]string{"person_id", "name", "surname", "patronymic", "age", "gender", "nationalize",
+		"match_scheme", "match_text", "score"}).
+		AddRow(1, "Иван", "Иванов", "", 30, "male", []byte(`[]`), "icao", "ivan ivanov", 0.75).
+		AddRow(5, "Petr", "Ivashin", "", 41, "male", []byte(`[]`), "original", "petr ivashin", 0.5)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM (SELECT person_id")).
+		WithArgs("iva", `\miva`, false, `\miva`, "iva").
+		WillReturnRows(rows)
+
+	persons, err := r.GetPersons(context.Background(), &service.GetFilters{
+		Query: GetAddress("iva"),
+		Sort:  []service.SortField{{Field: service.SortScore, Desc: true}},
+		Limit: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Person{
+		{ID: 1, Name: "Иван", Surname: "Иванов", Age: 30, Gender: "male", Nationalize: []entity.Nationality{}, Score: 0.75,
+			Match: &entity.Match{Scheme: "icao", Text: "ivan ivanov"}},
+		{ID: 5, Name: "Petr", Surname: "Ivashin", Age: 41, Gender: "male", Nationalize: []entity.Nationality{}, Score: 0.5,
+			Match: &entity.Match{Scheme: "original", Text: "petr ivashin"}},
+	}, persons)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
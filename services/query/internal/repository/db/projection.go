@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/pkg/phonetic"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	"github.com/pintoter/persons/services/query/internal/translit"
+)
+
+func eventsAfterBuilder(id int64, limit int) (string, []interface{}, error) {
+	builder := sq.Select("id", "event_type", "aggregate_id", "payload", "created_at").
+		From(outboxTable).
+		Where(sq.Gt{"id": id}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+func upsertViewBuilder(event projection.Event, payload projection.Payload) (string, []interface{}, error) {
+	person := payload.Person
+	nationalize := []byte("[]")
+	if len(person.Nationalize) > 0 {
+		var err error
+		if nationalize, err = json.Marshal(person.Nationalize); err != nil {
+			return "", nil, err
+		}
+	}
+
+	searchKeys, searchText, err := personSearchKeys(person)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// events written before the command service computed phonetic keys get
+	// them here, so rebuilds index every person
+	keys := payload.Phonetic
+	if keys == nil {
+		keys = phonetic.Keys(person.Name + " " + person.Surname)
+	}
+	phoneticKeys, err := json.Marshal(keys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	builder := sq.Insert(viewTable).
+		Columns("person_id", "name", "surname", "patronymic", "age", "gender", "nationalize", "deleted", "merged_into",
+			"search_keys", "search_text", "phonetic", "last_event_id").
+		Values(event.PersonID, person.Name, person.Surname, person.Patronymic, person.Age, person.Gender, nationalize,
+			event.Type == projection.PersonDeleted, payload.MergedInto, searchKeys, searchText, phoneticKeys, event.ID).
+		Suffix(`ON CONFLICT (person_id) DO UPDATE SET name = EXCLUDED.name, surname = EXCLUDED.surname,
+			patronymic = EXCLUDED.patronymic, age = EXCLUDED.age, gender = EXCLUDED.gender,
+			nationalize = EXCLUDED.nationalize, deleted = EXCLUDED.deleted, merged_into = EXCLUDED.merged_into,
+			search_keys = EXCLUDED.search_keys, search_text = EXCLUDED.search_text, phonetic = EXCLUDED.phonetic,
+			last_event_id = EXCLUDED.last_event_id, updated_at = now()
+			WHERE person_view.last_event_id < EXCLUDED.last_event_id`).
+		PlaceholderFormat(sq.Dollar)
+
+	return builder.ToSql()
+}
+
+// personSearchKeys returns spellings of the person full name searches match,
+// as JSON and joined for the trigram index
+func personSearchKeys(person *entity.Person) ([]byte, string, error) {
+	variants := translit.Variants(strings.Join(strings.Fields(person.Name+" "+person.Surname+" "+person.Patronymic), " "))
+
+	keys, err := json.Marshal(variants)
+	if err != nil {
+		return nil, "", err
+	}
+
+	texts := make([]string, len(variants))
+	for i, variant := range variants {
+		texts[i] = variant.Text
+	}
+	return keys, strings.Join(texts, " "), nil
+}
+
+// Checkpoint returns id of the last event applied to the projection
+func (r *DBRepo) Checkpoint(ctx context.Context, name string) (int64, error) {
+	var checkpoint int64
+	err := r.db.QueryRowContext(ctx, "SELECT last_event_id FROM "+checkpointTable+" WHERE name = $1", name).Scan(&checkpoint)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return checkpoint, err
+}
+
+// HeadEvent returns id of the last event in the change log
+func (r *DBRepo) HeadEvent(ctx context.Context) (int64, error) {
+	var head int64
+	err := r.db.QueryRowContext(ctx, "SELECT COALESCE(max(id), 0) FROM "+outboxTable).Scan(&head)
+	return head, err
+}
+
+// Snapshot returns the transaction bounds of the current snapshot
+func (r *DBRepo) Snapshot(ctx context.Context) (projection.Snapshot, error) {
+	var snapshot projection.Snapshot
+	err := r.db.QueryRowContext(ctx,
+		"SELECT pg_snapshot_xmin(s)::text, pg_snapshot_xmax(s)::text FROM pg_current_snapshot() AS s",
+	).Scan(&snapshot.Xmin, &snapshot.Xmax)
+	return snapshot, err
+}
+
+// EventsAfter returns the oldest events following the given one
+func (r *DBRepo) EventsAfter(ctx context.Context, id int64, limit int) ([]projection.Event, error) {
+	logMethod := "repository.EventsAfter"
+
+	query, args, err := eventsAfterBuilder(id, limit)
+	logger.DebugKV(ctx, "events after builder", "layer", logMethod, "query", query, "args", args, "err", err)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []projection.Event
+	for rows.Next() {
+		var event projection.Event
+		var payload []byte
+		if err = rows.Scan(&event.ID, &event.Type, &event.PersonID, &payload, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ApplyEvents projects events to person_view. Events older than the state
+// of a person are ignored, so replaying events is safe.
+func (r *DBRepo) ApplyEvents(ctx context.Context, name string, checkpoint int64, events []projection.Event) error {
+	logMethod := "repository.ApplyEvents"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var current int64
+	err = tx.QueryRowContext(ctx, "SELECT last_event_id FROM "+checkpointTable+" WHERE name = $1 FOR UPDATE", name).Scan(&current)
+	if err != nil {
+		return err
+	}
+	if current != checkpoint {
+		return projection.ErrCheckpointMoved
+	}
+
+	for _, event := range events {
+		if err = applyEvent(ctx, tx, event); err != nil {
+			return fmt.Errorf("apply event %d: %w", event.ID, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE "+checkpointTable+" SET last_event_id = $1, updated_at = now() WHERE name = $2",
+		events[len(events)-1].ID, name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func applyEvent(ctx context.Context, tx *sql.Tx, event projection.Event) error {
+	logMethod := "repository.applyEvent"
+
+	switch event.Type {
+	case projection.PersonCreated, projection.PersonUpdated, projection.PersonEnriched, projection.PersonDeleted:
+	default:
+		logger.DebugKV(ctx, "skip event", "layer", logMethod, "id", event.ID, "type", event.Type)
+		return nil
+	}
+
+	var payload projection.Payload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+	if payload.Person == nil {
+		return errors.New("event has no person")
+	}
+
+	query, args, err := upsertViewBuilder(event, payload)
+	logger.DebugKV(ctx, "upsert view builder", "layer", logMethod, "query", query, "err", err)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// ResetProjection empties person_view and rewinds its checkpoint to the start
+// of the change log
+func (r *DBRepo) ResetProjection(ctx context.Context, name string) error {
+	logMethod := "repository.ResetProjection"
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  false,
+	})
+	logger.DebugKV(ctx, "begin tx", "layer", logMethod, "err", err)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, "UPDATE "+checkpointTable+" SET last_event_id = 0, updated_at = now() WHERE name = $1", name)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM "+viewTable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
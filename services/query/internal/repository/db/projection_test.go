@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_upsertViewBuilder(t *testing.T) {
+	event := projection.Event{ID: 7, Type: projection.PersonDeleted, PersonID: 2}
+	mergedInto := 1
+
+	query, args, err := upsertViewBuilder(event, projection.Payload{
+		Person: &entity.Person{
+			ID:          2,
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			Age:         30,
+			Gender:      "male",
+			Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}},
+		},
+		MergedInto: &mergedInto,
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, query, "INSERT INTO person_view (person_id,name,surname,patronymic,age,gender,nationalize,deleted,merged_into,search_keys,search_text,phonetic,last_event_id) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)")
+	assert.Contains(t, query, "WHERE person_view.last_event_id < EXCLUDED.last_event_id")
+	assert.Equal(t, []interface{}{2, "Ivan", "Ivanov", "", 30, "male", []byte(`[{"country_id":"RU","probability":0.9}]`), true, &mergedInto,
+		[]byte(`[{"scheme":"original","text":"ivan ivanov"}]`), "ivan ivanov", []byte(`["AFN","AFNF"]`), int64(7)}, args)
+
+	_, args, err = upsertViewBuilder(projection.Event{ID: 8, Type: projection.PersonUpdated, PersonID: 3}, projection.Payload{
+		Person:   &entity.Person{ID: 3, Name: "Юрий", Surname: "Щукин", Patronymic: "Ильич", Age: 40, Gender: "male"},
+		Phonetic: []string{"ур7", "щук8"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`[{"scheme":"original","text":"юрий щукин ильич"},{"scheme":"icao","text":"iurii shchukin ilich"},`+
+		`{"scheme":"gost","text":"yurij shhukin ilich"},{"scheme":"bgn","text":"yuriy shchukin ilich"},`+
+		`{"scheme":"informal","text":"yury schukin ilich"}]`), args[9])
+	assert.Equal(t, "юрий щукин ильич iurii shchukin ilich yurij shhukin ilich yuriy shchukin ilich yury schukin ilich", args[10])
+	assert.Equal(t, []byte(`["ур7","щук8"]`), args[11])
+}
+
+func Test_ApplyEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	created := projection.Event{
+		ID:         3,
+		Type:       projection.PersonCreated,
+		PersonID:   1,
+		Payload:    []byte(`{"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[]}}`),
+		OccurredAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	}
+	unknown := projection.Event{ID: 4, Type: "PersonArchived", PersonID: 1, Payload: []byte(`{}`)}
+
+	lockQuery := regexp.QuoteMeta("SELECT last_event_id FROM projection_checkpoint WHERE name = $1 FOR UPDATE")
+	upsertQuery := regexp.QuoteMeta("INSERT INTO person_view")
+	checkpointQuery := regexp.QuoteMeta("UPDATE projection_checkpoint SET last_event_id = $1, updated_at = now() WHERE name = $2")
+
+	tests := []struct {
+		name         string
+		checkpoint   int64
+		events       []projection.Event
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:       "Success",
+			checkpoint: 2,
+			events:     []projection.Event{created, unknown},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(projection.Name).
+					WillReturnRows(sqlmock.NewRows([]string{"last_event_id"}).AddRow(2))
+				mock.ExpectExec(upsertQuery).
+					WithArgs(1, "Ivan", "Ivanov", "", 30, "male", []byte(`[]`), false, nil,
+						[]byte(`[{"scheme":"original","text":"ivan ivanov"}]`), "ivan ivanov", []byte(`["AFN","AFNF"]`), int64(3)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(checkpointQuery).WithArgs(int64(4), projection.Name).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:       "CheckpointMoved",
+			checkpoint: 2,
+			events:     []projection.Event{created},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(projection.Name).
+					WillReturnRows(sqlmock.NewRows([]string{"last_event_id"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			wantErr: projection.ErrCheckpointMoved,
+		},
+		{
+			name:       "FailedEventWithoutPerson",
+			checkpoint: 2,
+			events:     []projection.Event{{ID: 3, Type: projection.PersonDeleted, PersonID: 1, Payload: []byte(`{"merged_into":5}`)}},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(lockQuery).WithArgs(projection.Name).
+					WillReturnRows(sqlmock.NewRows([]string{"last_event_id"}).AddRow(2))
+				mock.ExpectRollback()
+			},
+			wantErr: errors.New("apply event 3: event has no person"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			err := r.ApplyEvents(context.Background(), projection.Name, tt.checkpoint, tt.events)
+			switch {
+			case tt.wantErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.wantErr, projection.ErrCheckpointMoved):
+				assert.ErrorIs(t, err, projection.ErrCheckpointMoved)
+			default:
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_EventsAfter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+	occurredAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "event_type", "aggregate_id", "payload", "created_at"}).
+		AddRow(3, projection.PersonCreated, 1, []byte(`{"person":{"id":1}}`), occurredAt).
+		AddRow(5, projection.PersonDeleted, 1, []byte(`{"person":{"id":1}}`), occurredAt)
+	expectedQuery := "SELECT id, event_type, aggregate_id, payload, created_at FROM outbox WHERE id > $1 ORDER BY id LIMIT 100"
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).WithArgs(int64(2)).WillReturnRows(rows)
+
+	events, err := r.EventsAfter(context.Background(), 2, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []projection.Event{
+		{ID: 3, Type: projection.PersonCreated, PersonID: 1, Payload: []byte(`{"person":{"id":1}}`), OccurredAt: occurredAt},
+		{ID: 5, Type: projection.PersonDeleted, PersonID: 1, Payload: []byte(`{"person":{"id":1}}`), OccurredAt: occurredAt},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_ResetProjection(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE projection_checkpoint SET last_event_id = 0, updated_at = now() WHERE name = $1")).
+		WithArgs(projection.Name).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM person_view")).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ResetProjection(context.Background(), projection.Name))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_Snapshot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	r := New(db)
+
+	expectedQuery := "SELECT pg_snapshot_xmin(s)::text, pg_snapshot_xmax(s)::text FROM pg_current_snapshot() AS s"
+	mock.ExpectQuery(regexp.QuoteMeta(expectedQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"xmin", "xmax"}).AddRow("748", "751"))
+
+	snapshot, err := r.Snapshot(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, projection.Snapshot{Xmin: 748, Xmax: 751}, snapshot)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,8 +5,11 @@ import (
 )
 
 const (
-	personTable      = "person"
-	nationalityTable = "person_nationality"
+	auditTable      = "person_audit"
+	versionTable    = "person_version"
+	outboxTable     = "outbox"
+	viewTable       = "person_view"
+	checkpointTable = "projection_checkpoint"
 )
 
 type DBRepo struct {
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+)
+
+// GetChanges returns up to limit persons changed after the since event, in
+// commit order. Only changes already applied to the read model are returned,
+// so the next token never skips a change that is still being projected.
+func (s *Service) GetChanges(ctx context.Context, since int64, limit int) (entity.ChangeSet, error) {
+	layer := "service.GetChanges"
+
+	checkpoint, err := s.repo.Checkpoint(ctx, projection.Name)
+	if err != nil {
+		logger.ErrorKV(ctx, "get checkpoint", "layer", layer, "err", err)
+		return entity.ChangeSet{}, entity.ErrInternalService
+	}
+
+	changes, err := s.repo.GetChanges(ctx, since, checkpoint, limit+1)
+	if err != nil {
+		logger.ErrorKV(ctx, "get changes", "layer", layer, "err", err)
+		return entity.ChangeSet{}, entity.ErrInternalService
+	}
+
+	set := entity.ChangeSet{Changes: changes, NextToken: since}
+	if len(changes) > limit {
+		set.Changes, set.HasMore = changes[:limit], true
+		set.NextToken = changes[limit-1].EventID
+		return set, nil
+	}
+
+	// everything up to the checkpoint has been read; the checkpoint is lower
+	// than since only while the read model is being rebuilt
+	set.NextToken = max(since, checkpoint)
+	return set, nil
+}
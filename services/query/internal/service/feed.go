@@ -0,0 +1,148 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+)
+
+// Feed reads the change log for one subscriber
+type Feed struct {
+	service  *Service
+	filters  *GetFilters
+	position int64
+}
+
+// OpenFeed starts a feed after lastEventID. Without it the feed starts from
+// the last event visible in the read model.
+func (s *Service) OpenFeed(ctx context.Context, filters *GetFilters, lastEventID *int64) (*Feed, error) {
+	layer := "service.OpenFeed"
+
+	s.normalizeFilters(filters)
+
+	feed := &Feed{service: s, filters: filters}
+	if lastEventID != nil {
+		feed.position = *lastEventID
+		return feed, nil
+	}
+
+	position, err := s.repo.Checkpoint(ctx, projection.Name)
+	if err != nil {
+		logger.ErrorKV(ctx, "get checkpoint", "layer", layer, "err", err)
+		return nil, entity.ErrInternalService
+	}
+	feed.position = position
+	return feed, nil
+}
+
+// Next returns up to limit following events matching filters. Only events
+// already applied to the read model are returned, so GET requests made after
+// an event is received reflect it.
+func (f *Feed) Next(ctx context.Context, limit int) ([]entity.PersonEvent, error) {
+	layer := "service.Feed.Next"
+	repo := f.service.repo
+
+	checkpoint, err := repo.Checkpoint(ctx, projection.Name)
+	if err != nil {
+		logger.ErrorKV(ctx, "get checkpoint", "layer", layer, "err", err)
+		return nil, entity.ErrInternalService
+	}
+
+	var result []entity.PersonEvent
+	// batches filtered out completely are skipped without waiting for the
+	// next poll
+	for len(result) == 0 && f.position < checkpoint {
+		events, err := repo.EventsAfter(ctx, f.position, limit)
+		if err != nil {
+			logger.ErrorKV(ctx, "get events", "layer", layer, "err", err)
+			return nil, entity.ErrInternalService
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		for _, event := range events {
+			if event.ID > checkpoint {
+				return result, nil
+			}
+			f.position = event.ID
+
+			var payload projection.Payload
+			if err = json.Unmarshal(event.Payload, &payload); err != nil {
+				logger.ErrorKV(ctx, "decode event", "layer", layer, "id", event.ID, "err", err)
+				continue
+			}
+			if payload.Person == nil || !matches(payload.Person, f.filters) {
+				continue
+			}
+
+			result = append(result, entity.PersonEvent{
+				ID:         event.ID,
+				Type:       event.Type,
+				PersonID:   event.PersonID,
+				Person:     payload.Person,
+				MergedInto: payload.MergedInto,
+				OccurredAt: event.OccurredAt,
+			})
+		}
+
+		if len(events) < limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// matches checks person against filters the same way the read model does
+func matches(person *entity.Person, filters *GetFilters) bool {
+	switch {
+	case filters.Name != nil && person.Name != *filters.Name,
+		filters.Surname != nil && person.Surname != *filters.Surname,
+		filters.Patronymic != nil && person.Patronymic != *filters.Patronymic,
+		filters.Age != nil && person.Age != *filters.Age,
+		filters.AgeMin != nil && person.Age < *filters.AgeMin,
+		filters.AgeMax != nil && person.Age > *filters.AgeMax,
+		filters.Gender != nil && !slices.Contains(filters.Gender, person.Gender),
+		filters.Nationalize != nil && !hasNationality(person, filters.Nationalize, filters.NationalizeMinProbability),
+		filters.PrimaryNationality != nil && !hasPrimaryNationality(person, filters.PrimaryNationality, filters.NationalizeMinProbability):
+		return false
+	}
+
+	exclude := filters.Exclude
+	switch {
+	case slices.Contains(exclude.Name, person.Name),
+		slices.Contains(exclude.Surname, person.Surname),
+		slices.Contains(exclude.Patronymic, person.Patronymic),
+		slices.Contains(exclude.Gender, person.Gender),
+		hasNationality(person, exclude.Nationalize, nil):
+		return false
+	}
+	return true
+}
+
+// hasNationality checks whether person has any of the countries among
+// nationalities, with at least minProbability if it's set
+func hasNationality(person *entity.Person, countries []string, minProbability *float64) bool {
+	return slices.ContainsFunc(person.Nationalize, func(n entity.Nationality) bool {
+		return slices.Contains(countries, n.Country) && (minProbability == nil || n.Probability >= *minProbability)
+	})
+}
+
+// hasPrimaryNationality checks whether the most probable nationality of
+// person is any of the countries, with at least minProbability if it's set.
+// Ties are broken by the country code, as the read model does.
+func hasPrimaryNationality(person *entity.Person, countries []string, minProbability *float64) bool {
+	if len(person.Nationalize) == 0 {
+		return false
+	}
+	primary := slices.MinFunc(person.Nationalize, func(a, b entity.Nationality) int {
+		return cmp.Or(cmp.Compare(b.Probability, a.Probability), cmp.Compare(a.Country, b.Country))
+	})
+	return slices.Contains(countries, primary.Country) && (minProbability == nil || primary.Probability >= *minProbability)
+}
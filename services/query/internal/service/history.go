@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+)
+
+// GetHistory returns a page of person changes, the newest first. Persons
+// created before the audit was introduced may have no recorded change, so an
+// empty history is reported as not existing only if the person isn't in the
+// read model either.
+func (s *Service) GetHistory(ctx context.Context, id int, limit, offset int64) ([]entity.HistoryEntry, error) {
+	layer := "service.GetHistory"
+
+	history, err := s.repo.GetHistory(ctx, id, limit, offset)
+	logger.DebugKV(ctx, "result get history", "layer", layer, "entries", len(history), "err", err)
+	if err != nil {
+		return nil, entity.ErrInternalService
+	}
+
+	if len(history) == 0 && offset == 0 {
+		_, err = s.repo.GetPerson(ctx, id)
+		logger.DebugKV(ctx, "check person exists", "layer", layer, "err", err)
+		switch {
+		case err == nil, errors.Is(err, entity.ErrPersonMerged):
+			// the person exists, there is just nothing recorded for it
+		case errors.Is(err, sql.ErrNoRows), errors.Is(err, entity.ErrPersonNotExists):
+			return nil, entity.ErrPersonNotExists
+		default:
+			return nil, entity.ErrInternalService
+		}
+	}
+
+	return history, nil
+}
@@ -7,9 +7,11 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/pintoter/persons/services/query/internal/entity"
+	projection "github.com/pintoter/persons/services/query/internal/projection"
 	service "github.com/pintoter/persons/services/query/internal/service"
 )
 
@@ -36,6 +38,81 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Checkpoint mocks base method.
+func (m *MockRepository) Checkpoint(ctx context.Context, name string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint", ctx, name)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockRepositoryMockRecorder) Checkpoint(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockRepository)(nil).Checkpoint), ctx, name)
+}
+
+// CountPersons mocks base method.
+func (m *MockRepository) CountPersons(ctx context.Context, filters *service.GetFilters) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPersons", ctx, filters)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPersons indicates an expected call of CountPersons.
+func (mr *MockRepositoryMockRecorder) CountPersons(ctx, filters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPersons", reflect.TypeOf((*MockRepository)(nil).CountPersons), ctx, filters)
+}
+
+// EventsAfter mocks base method.
+func (m *MockRepository) EventsAfter(ctx context.Context, id int64, limit int) ([]projection.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EventsAfter", ctx, id, limit)
+	ret0, _ := ret[0].([]projection.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EventsAfter indicates an expected call of EventsAfter.
+func (mr *MockRepositoryMockRecorder) EventsAfter(ctx, id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EventsAfter", reflect.TypeOf((*MockRepository)(nil).EventsAfter), ctx, id, limit)
+}
+
+// GetChanges mocks base method.
+func (m *MockRepository) GetChanges(ctx context.Context, since, until int64, limit int) ([]entity.Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChanges", ctx, since, until, limit)
+	ret0, _ := ret[0].([]entity.Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChanges indicates an expected call of GetChanges.
+func (mr *MockRepositoryMockRecorder) GetChanges(ctx, since, until, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChanges", reflect.TypeOf((*MockRepository)(nil).GetChanges), ctx, since, until, limit)
+}
+
+// GetHistory mocks base method.
+func (m *MockRepository) GetHistory(ctx context.Context, id int, limit, offset int64) ([]entity.HistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, id, limit, offset)
+	ret0, _ := ret[0].([]entity.HistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockRepositoryMockRecorder) GetHistory(ctx, id, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockRepository)(nil).GetHistory), ctx, id, limit, offset)
+}
+
 // GetPerson mocks base method.
 func (m *MockRepository) GetPerson(ctx context.Context, id int) (entity.Person, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +128,21 @@ func (mr *MockRepositoryMockRecorder) GetPerson(ctx, id interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockRepository)(nil).GetPerson), ctx, id)
 }
 
+// GetPersonAsOf mocks base method.
+func (m *MockRepository) GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonAsOf", ctx, id, asOf)
+	ret0, _ := ret[0].(entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonAsOf indicates an expected call of GetPersonAsOf.
+func (mr *MockRepositoryMockRecorder) GetPersonAsOf(ctx, id, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonAsOf", reflect.TypeOf((*MockRepository)(nil).GetPersonAsOf), ctx, id, asOf)
+}
+
 // GetPersons mocks base method.
 func (m *MockRepository) GetPersons(ctx context.Context, filters *service.GetFilters) ([]entity.Person, error) {
 	m.ctrl.T.Helper()
@@ -65,3 +157,55 @@ func (mr *MockRepositoryMockRecorder) GetPersons(ctx, filters interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersons", reflect.TypeOf((*MockRepository)(nil).GetPersons), ctx, filters)
 }
+
+// GetPersonsByIDs mocks base method.
+func (m *MockRepository) GetPersonsByIDs(ctx context.Context, ids []int) ([]entity.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonsByIDs indicates an expected call of GetPersonsByIDs.
+func (mr *MockRepositoryMockRecorder) GetPersonsByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonsByIDs", reflect.TypeOf((*MockRepository)(nil).GetPersonsByIDs), ctx, ids)
+}
+
+// MockNormalizer is a mock of Normalizer interface.
+type MockNormalizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockNormalizerMockRecorder
+}
+
+// MockNormalizerMockRecorder is the mock recorder for MockNormalizer.
+type MockNormalizerMockRecorder struct {
+	mock *MockNormalizer
+}
+
+// NewMockNormalizer creates a new mock instance.
+func NewMockNormalizer(ctrl *gomock.Controller) *MockNormalizer {
+	mock := &MockNormalizer{ctrl: ctrl}
+	mock.recorder = &MockNormalizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNormalizer) EXPECT() *MockNormalizerMockRecorder {
+	return m.recorder
+}
+
+// Normalize mocks base method.
+func (m *MockNormalizer) Normalize(value string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Normalize", value)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Normalize indicates an expected call of Normalize.
+func (mr *MockNormalizerMockRecorder) Normalize(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Normalize", reflect.TypeOf((*MockNormalizer)(nil).Normalize), value)
+}
@@ -1,49 +1,299 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/pintoter/persons/pkg/logger"
 	"github.com/pintoter/persons/services/query/internal/entity"
 )
 
-func (s *Service) GetPerson(ctx context.Context, id int) (entity.Person, error) {
+// GetPerson returns person by id, as it was at asOf if it's set. The person
+// keeps nationalities most probable nationalities, all of them when it's 0.
+func (s *Service) GetPerson(ctx context.Context, id int, asOf *time.Time, nationalities int) (entity.Person, error) {
 	layer := "service.GetPerson"
 
-	person, err := s.repo.GetPerson(ctx, id)
+	var person entity.Person
+	var err error
+	if asOf != nil {
+		person, err = s.repo.GetPersonAsOf(ctx, id, *asOf)
+	} else {
+		person, err = s.repo.GetPerson(ctx, id)
+	}
 	logger.DebugKV(ctx, "result get person", "layer", layer, "person", person, "err", err)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, entity.ErrPersonNotExists) {
+		switch {
+		case errors.Is(err, sql.ErrNoRows) || errors.Is(err, entity.ErrPersonNotExists):
 			return entity.Person{}, entity.ErrPersonNotExists
-		} else {
+		case errors.Is(err, entity.ErrPersonMerged):
+			return entity.Person{}, err
+		default:
 			return entity.Person{}, entity.ErrInternalService
 		}
 	}
 
-	return person, nil
+	persons := []entity.Person{person}
+	trimNationalities(persons, nationalities)
+	return persons[0], nil
+}
+
+// Fields persons can be sorted by
+const (
+	SortID         = "id"
+	SortName       = "name"
+	SortSurname    = "surname"
+	SortPatronymic = "patronymic"
+	SortAge        = "age"
+	SortGender     = "gender"
+	// SortScore orders by similarity to the search query
+	SortScore = "score"
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// KeysetOrder returns the sort fields persons are ordered by: the known ones
+// followed by ID, which breaks ties
+func KeysetOrder(sort []SortField) []SortField {
+	order := make([]SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		switch field.Field {
+		case SortID:
+			return append(order, field)
+		case SortName, SortSurname, SortPatronymic, SortAge, SortGender, SortScore:
+			order = append(order, field)
+		}
+	}
+	return append(order, SortField{Field: SortID})
 }
 
+// Cursor is a position in the keyset order of persons. Key holds the ID and
+// sort fields of the person the position is next to.
+type Cursor struct {
+	Key entity.Person
+	// Backward selects persons before the key, otherwise persons after it
+	Backward bool
+}
+
+// How search queries match names
+const (
+	// MatchFuzzy matches name parts starting with the query or similar to it,
+	// in any spelling, it's the default
+	MatchFuzzy = "fuzzy"
+	// MatchPhonetic matches names and surnames sounding like the query words
+	MatchPhonetic = "phonetic"
+)
+
 type GetFilters struct {
-	Name        *string
-	Surname     *string
-	Patronymic  *string
-	Age         *int
-	Gender      *string
-	Nationalize *string
-	Limit       int64
-	Offset      int64
+	// Query searches persons with a name part starting with it or similar to
+	// it, the similarity is returned as Score
+	Query *string
+	// Match is how Query matches, MatchFuzzy when it's empty
+	Match   string
+	Name    *string
+	Surname *string
+	// Patronymic is empty to select persons without patronymic
+	Patronymic *string
+	Age        *int
+	// AgeMin and AgeMax bound age, both inclusive
+	AgeMin *int
+	AgeMax *int
+	// Gender and Nationalize select persons with any of the values
+	Gender      []string
+	Nationalize []string
+	// PrimaryNationality selects persons whose most probable nationality is
+	// any of the countries
+	PrimaryNationality []string
+	// NationalizeMinProbability is the least probability of the countries
+	// Nationalize and PrimaryNationality match
+	NationalizeMinProbability *float64
+	// Nationalities keeps that many most probable nationalities of returned
+	// persons, all of them when it's 0
+	Nationalities int
+	// Exclude skips persons with the values
+	Exclude Exclusions
+	AsOf    *time.Time
+	// Sort orders persons by the fields in turn, then by ID
+	Sort []SortField
+	// Cursor selects persons next to it in the sort order, Offset is ignored
+	// when it's set
+	Cursor *Cursor
+	Limit  int64
+	Offset int64
+}
+
+// Exclusions are negated filters, persons with any of the values of a field
+// are skipped. An empty patronymic skips persons without patronymic.
+type Exclusions struct {
+	Name        []string
+	Surname     []string
+	Patronymic  []string
+	Gender      []string
+	Nationalize []string
 }
 
 func (s *Service) GetPersons(ctx context.Context, filters *GetFilters) ([]entity.Person, error) {
 	layer := "service.GetPersons"
 
+	s.normalizeFilters(filters)
+
 	persons, err := s.repo.GetPersons(ctx, filters)
 	logger.DebugKV(ctx, "get persons request", "layer", layer, "persons", persons)
+	if errors.Is(err, entity.ErrPersonNotExists) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, entity.ErrInternalService
 	}
 
+	trimNationalities(persons, filters.Nationalities)
 	return persons, nil
 }
+
+// trimNationalities keeps n most probable nationalities of persons, all of
+// them when n is 0. Ties are broken by the country code, as for the primary
+// nationality.
+func trimNationalities(persons []entity.Person, n int) {
+	if n == 0 {
+		return
+	}
+	for i := range persons {
+		nationalize := slices.Clone(persons[i].Nationalize)
+		slices.SortFunc(nationalize, func(a, b entity.Nationality) int {
+			return cmp.Or(cmp.Compare(b.Probability, a.Probability), cmp.Compare(a.Country, b.Country))
+		})
+		persons[i].Nationalize = nationalize[:min(n, len(nationalize))]
+	}
+}
+
+// PersonsPage is a page of persons with cursors of the pages next to it, nil
+// when there are no persons there
+type PersonsPage struct {
+	Persons []entity.Person
+	Next    *Cursor
+	Prev    *Cursor
+	// Total is the number of persons matching the filters on all pages
+	Total int64
+	// HasMore tells whether there are persons after the page
+	HasMore bool
+}
+
+// GetPersonsPage returns a page of persons after or before the filters
+// cursor, or at the offset without it
+func (s *Service) GetPersonsPage(ctx context.Context, filters *GetFilters) (PersonsPage, error) {
+	layer := "service.GetPersonsPage"
+
+	limit := int(filters.Limit)
+	backward := filters.Cursor != nil && filters.Cursor.Backward
+
+	// one more person tells whether there's a page after this one
+	filters.Limit++
+	persons, err := s.GetPersons(ctx, filters)
+	filters.Limit--
+	// nothing after the cursor or offset is an empty page
+	if errors.Is(err, entity.ErrPersonNotExists) {
+		err = nil
+	}
+	if err != nil {
+		return PersonsPage{}, err
+	}
+
+	more := len(persons) > limit
+	if more && backward {
+		persons = persons[1:]
+	} else if more {
+		persons = persons[:limit]
+	}
+
+	total, err := s.repo.CountPersons(ctx, filters)
+	if err != nil {
+		logger.ErrorKV(ctx, "count persons", "layer", layer, "err", err)
+		return PersonsPage{}, entity.ErrInternalService
+	}
+
+	page := PersonsPage{Persons: persons, Total: total, HasMore: more || (backward && len(persons) > 0)}
+	if len(persons) == 0 {
+		return page, nil
+	}
+
+	if more || backward {
+		page.Next = &Cursor{Key: persons[len(persons)-1]}
+	}
+	if (backward && more) || (!backward && (filters.Cursor != nil || filters.Offset > 0)) {
+		page.Prev = &Cursor{Key: persons[0], Backward: true}
+	}
+	return page, nil
+}
+
+// GetPersonsByIDs returns existing persons with the given IDs, deleted and
+// unknown IDs are skipped
+func (s *Service) GetPersonsByIDs(ctx context.Context, ids []int) ([]entity.Person, error) {
+	layer := "service.GetPersonsByIDs"
+
+	persons, err := s.repo.GetPersonsByIDs(ctx, ids)
+	logger.DebugKV(ctx, "get persons by ids", "layer", layer, "ids", ids, "persons", persons, "err", err)
+	if err != nil {
+		return nil, entity.ErrInternalService
+	}
+
+	return persons, nil
+}
+
+// ListPersons calls fn for every person matching filters, reading them in
+// pages of pageSize
+func (s *Service) ListPersons(ctx context.Context, filters *GetFilters, pageSize int64, fn func(person entity.Person) error) error {
+	layer := "service.ListPersons"
+
+	s.normalizeFilters(filters)
+
+	filters.Limit = pageSize
+	for filters.Offset = 0; ; filters.Offset += pageSize {
+		persons, err := s.repo.GetPersons(ctx, filters)
+		if errors.Is(err, entity.ErrPersonNotExists) {
+			return nil
+		}
+		if err != nil {
+			logger.ErrorKV(ctx, "get persons", "layer", layer, "err", err)
+			return entity.ErrInternalService
+		}
+
+		trimNationalities(persons, filters.Nationalities)
+		for _, person := range persons {
+			if err = fn(person); err != nil {
+				return err
+			}
+		}
+		if int64(len(persons)) < pageSize {
+			return nil
+		}
+	}
+}
+
+// normalizeFilters brings name filters to the form names are stored in
+func (s *Service) normalizeFilters(filters *GetFilters) {
+	// the search query is compared with lower-cased full names
+	if filters.Query != nil {
+		*filters.Query = strings.Join(strings.Fields(strings.ToLower(*filters.Query)), " ")
+	}
+
+	for _, value := range []*string{filters.Name, filters.Surname, filters.Patronymic} {
+		if value != nil && *value != "" {
+			*value = s.norm.Normalize(*value)
+		}
+	}
+
+	for _, values := range [][]string{filters.Exclude.Name, filters.Exclude.Surname, filters.Exclude.Patronymic} {
+		for i, value := range values {
+			if value != "" {
+				values[i] = s.norm.Normalize(value)
+			}
+		}
+	}
+}
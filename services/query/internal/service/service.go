@@ -2,23 +2,38 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Repository interface {
 	GetPerson(ctx context.Context, id int) (entity.Person, error)
+	GetPersonAsOf(ctx context.Context, id int, asOf time.Time) (entity.Person, error)
 	GetPersons(ctx context.Context, filters *GetFilters) ([]entity.Person, error)
+	CountPersons(ctx context.Context, filters *GetFilters) (int64, error)
+	GetPersonsByIDs(ctx context.Context, ids []int) ([]entity.Person, error)
+	GetHistory(ctx context.Context, id int, limit, offset int64) ([]entity.HistoryEntry, error)
+	Checkpoint(ctx context.Context, name string) (int64, error)
+	EventsAfter(ctx context.Context, id int64, limit int) ([]projection.Event, error)
+	GetChanges(ctx context.Context, since, until int64, limit int) ([]entity.Change, error)
+}
+
+type Normalizer interface {
+	Normalize(value string) string
 }
 
 type Service struct {
 	repo Repository
+	norm Normalizer
 }
 
-func New(repo Repository) *Service {
+func New(repo Repository, norm Normalizer) *Service {
 	return &Service{
 		repo: repo,
+		norm: norm,
 	}
 }
@@ -0,0 +1,191 @@
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Schemes of spellings returned by Variants
+const (
+	// Original is the text as it's written
+	Original = "original"
+	// ICAO is ICAO Doc 9303, used in Russian passports since 2014
+	ICAO = "icao"
+	// GOST is GOST 7.79-2000 System B without apostrophes and backticks
+	GOST = "gost"
+	// BGN is BGN/PCGN romanization, common in English texts
+	BGN = "bgn"
+	// Informal is the spelling people often choose themselves: Dmitry,
+	// Mihail, Alexandr
+	Informal = "informal"
+)
+
+// Variant is a spelling of a text in a scheme
+type Variant struct {
+	Scheme string `json:"scheme"`
+	Text   string `json:"text"`
+}
+
+// scheme transliterates Cyrillic letters, special overrides letters by
+// their neighbours in the word
+type scheme struct {
+	name    string
+	letters map[rune]string
+	special func(prev, r, next rune) (string, bool)
+}
+
+var common = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh", 'з': "z", 'и': "i", 'к': "k",
+	'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'ч': "ch", 'ш': "sh", 'ъ': "", 'ь': "",
+	// Ukrainian and Belarusian letters
+	'і': "i", 'ў': "u",
+}
+
+func with(letters map[rune]string) map[rune]string {
+	result := make(map[rune]string, len(common)+len(letters))
+	for r, s := range common {
+		result[r] = s
+	}
+	for r, s := range letters {
+		result[r] = s
+	}
+	return result
+}
+
+var schemes = []scheme{
+	{
+		name: ICAO,
+		letters: with(map[rune]string{
+			'ё': "e", 'й': "i", 'х': "kh", 'ц': "ts", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'э': "e", 'ю': "iu", 'я': "ia",
+			'ї': "i", 'є': "ie", 'ґ': "g",
+		}),
+	},
+	{
+		name: GOST,
+		letters: with(map[rune]string{
+			'ё': "yo", 'й': "j", 'х': "x", 'ц': "cz", 'щ': "shh", 'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+			'ї': "yi", 'є': "ye", 'ґ': "g",
+		}),
+		special: func(prev, r, next rune) (string, bool) {
+			// ц is c before е, и, ы and й
+			if r == 'ц' && strings.ContainsRune("еиыйі", next) {
+				return "c", true
+			}
+			return "", false
+		},
+	},
+	{
+		name: BGN,
+		letters: with(map[rune]string{
+			'ё': "yo", 'й': "y", 'х': "kh", 'ц': "ts", 'щ': "shch", 'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+			'ї': "yi", 'є': "ye", 'ґ': "g",
+		}),
+		special: func(prev, r, next rune) (string, bool) {
+			// е is ye at the start of a word and after vowels and signs
+			if r == 'е' && (prev == 0 || strings.ContainsRune("аеёиоуыэюяъь", prev)) {
+				return "ye", true
+			}
+			return "", false
+		},
+	},
+	{
+		name: Informal,
+		letters: with(map[rune]string{
+			'ё': "yo", 'й': "y", 'х': "h", 'ц': "ts", 'щ': "sch", 'ы': "y", 'э': "e", 'ю': "yu", 'я': "ya",
+			'ї': "yi", 'є': "ye", 'ґ': "g",
+		}),
+		special: func(prev, r, next rune) (string, bool) {
+			switch {
+			// Dmitry, Vasily: ий and ый end with y
+			case r == 'и' || r == 'ы':
+				if next == 'й' {
+					return "y", true
+				}
+			case r == 'й' && (prev == 'и' || prev == 'ы'):
+				return "", true
+			// Alexandr, Maxim
+			case r == 'к' && next == 'с':
+				return "x", true
+			case r == 'с' && prev == 'к':
+				return "", true
+			}
+			return "", false
+		},
+	},
+}
+
+// Variants returns lower-cased spellings of the text: the original one and
+// its transliterations from Cyrillic into Latin. Spellings equal to an
+// earlier one are dropped, so a Latin text has the original spelling only.
+func Variants(text string) []Variant {
+	text = strings.ToLower(text)
+	variants := []Variant{{Scheme: Original, Text: text}}
+	if !hasCyrillic(text) {
+		return variants
+	}
+
+	for _, s := range schemes {
+		spelling := s.transliterate(text)
+		if !contains(variants, spelling) {
+			variants = append(variants, Variant{Scheme: s.name, Text: spelling})
+		}
+	}
+	return variants
+}
+
+// Texts returns the spellings of Variants without schemes
+func Texts(text string) []string {
+	variants := Variants(text)
+	texts := make([]string, len(variants))
+	for i, variant := range variants {
+		texts[i] = variant.Text
+	}
+	return texts
+}
+
+func (s scheme) transliterate(text string) string {
+	runes := []rune(text)
+
+	var b strings.Builder
+	for i, r := range runes {
+		var prev, next rune
+		if i > 0 && unicode.IsLetter(runes[i-1]) {
+			prev = runes[i-1]
+		}
+		if i+1 < len(runes) && unicode.IsLetter(runes[i+1]) {
+			next = runes[i+1]
+		}
+
+		if s.special != nil {
+			if spelling, ok := s.special(prev, r, next); ok {
+				b.WriteString(spelling)
+				continue
+			}
+		}
+		if spelling, ok := s.letters[r]; ok {
+			b.WriteString(spelling)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func hasCyrillic(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(variants []Variant, text string) bool {
+	for _, variant := range variants {
+		if variant.Text == text {
+			return true
+		}
+	}
+	return false
+}
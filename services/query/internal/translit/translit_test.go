@@ -0,0 +1,35 @@
+package translit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Variants(t *testing.T) {
+	assert.Equal(t, []Variant{
+		{Scheme: Original, Text: "дмитрий"},
+		{Scheme: ICAO, Text: "dmitrii"},
+		{Scheme: GOST, Text: "dmitrij"},
+		{Scheme: BGN, Text: "dmitriy"},
+		{Scheme: Informal, Text: "dmitry"},
+	}, Variants("Дмитрий"))
+
+	assert.Equal(t, []Variant{
+		{Scheme: Original, Text: "елена цыганова"},
+		{Scheme: ICAO, Text: "elena tsyganova"},
+		{Scheme: GOST, Text: "elena cyganova"},
+		{Scheme: BGN, Text: "yelena tsyganova"},
+	}, Variants("Елена Цыганова"))
+
+	assert.Equal(t, []Variant{
+		{Scheme: Original, Text: "александр михайлович"},
+		{Scheme: ICAO, Text: "aleksandr mikhailovich"},
+		{Scheme: GOST, Text: "aleksandr mixajlovich"},
+		{Scheme: BGN, Text: "aleksandr mikhaylovich"},
+		{Scheme: Informal, Text: "alexandr mihaylovich"},
+	}, Variants("Александр Михайлович"))
+
+	assert.Equal(t, []Variant{{Scheme: Original, Text: "dmitriy"}}, Variants("Dmitriy"))
+	assert.Equal(t, []string{"щукин", "shchukin", "shhukin", "schukin"}, Texts("Щукин"))
+}
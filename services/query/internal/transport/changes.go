@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pintoter/persons/services/query/internal/entity"
+)
+
+const changeTokenPrefix = "changes:"
+
+// encodeChangeToken hides the event id, so clients don't build tokens
+// themselves and the format can change
+func encodeChangeToken(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(changeTokenPrefix + strconv.FormatInt(id, 10)))
+}
+
+func decodeChangeToken(token string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := strings.CutPrefix(string(data), changeTokenPrefix)
+	if !ok {
+		return 0, errors.New("unknown token format")
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid token position")
+	}
+	return n, nil
+}
+
+// @Summary Get person changes
+// @Description Get persons changed since the token in commit order: upserts with the latest state and tombstones
+// @Description for deleted persons. Pass next_token as since to continue. With wait the request is held until
+// @Description there are changes or wait seconds pass.
+// @Tags persons
+// @Produce json
+// @Param since query string false "next_token of the previous response, full sync without it"
+// @Param limit query int false "limit"
+// @Param wait query int false "seconds to wait for changes"
+// @Success 200 {object} getChangesResponse
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/persons/changes [get]
+func (h *Handler) getChanges(w http.ResponseWriter, r *http.Request) {
+	var input getChangesRequest
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	set, err := h.service.GetChanges(r.Context(), input.Since, input.Limit)
+	if err == nil && len(set.Changes) == 0 && input.Wait > 0 {
+		// the long-poll outlives the server write timeout
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		set, err = h.waitChanges(r.Context(), input)
+	}
+	if err != nil {
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		return
+	}
+
+	renderJSON(w, r, http.StatusOK, getChangesResponse{
+		Changes:   set.Changes,
+		NextToken: encodeChangeToken(set.NextToken),
+		HasMore:   set.HasMore,
+	})
+}
+
+// waitChanges polls for changes until there are some, the wait is over or
+// the handler is closed
+func (h *Handler) waitChanges(ctx context.Context, input getChangesRequest) (entity.ChangeSet, error) {
+	ctx, cancel := context.WithTimeout(ctx, input.Wait)
+	defer cancel()
+	stop := context.AfterFunc(h.closing, cancel)
+	defer stop()
+
+	poll := time.NewTicker(h.feed.GetPollInterval())
+	defer poll.Stop()
+
+	set := entity.ChangeSet{Changes: []entity.Change{}, NextToken: input.Since}
+	for {
+		select {
+		case <-ctx.Done():
+			return set, nil
+		case <-poll.C:
+		}
+
+		next, err := h.service.GetChanges(ctx, input.Since, input.Limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return set, nil
+			}
+			return entity.ChangeSet{}, err
+		}
+		set = next
+		if len(set.Changes) > 0 {
+			return set, nil
+		}
+	}
+}
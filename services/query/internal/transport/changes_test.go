@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_changeToken(t *testing.T) {
+	id, err := decodeChangeToken(encodeChangeToken(42))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	for _, token := range []string{"42", "!!", encodeChangeToken(-1)} {
+		_, err = decodeChangeToken(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func Test_GetChanges(t *testing.T) {
+	changedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mergedInto := 3
+	changes := []entity.Change{
+		{Type: entity.ChangeUpsert, PersonID: 2, Person: &entity.Person{ID: 2, Name: "Anna", Surname: "Petrova", Age: 25, Gender: "female"}, EventID: 5, ChangedAt: changedAt},
+		{Type: entity.ChangeTombstone, PersonID: 1, MergedInto: &mergedInto, EventID: 6, ChangedAt: changedAt},
+	}
+
+	tests := []struct {
+		name                 string
+		query                string
+		mockBehavior         func(r *mock_service.MockRepository)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "FullSync",
+			query: "?limit=2",
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(9), nil)
+				r.EXPECT().GetChanges(gomock.Any(), int64(0), int64(9), 3).Return(append(changes, entity.Change{EventID: 8}), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getChangesResponse{Changes: changes, NextToken: encodeChangeToken(6), HasMore: true}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:  "Continued",
+			query: "?since=" + encodeChangeToken(6),
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(9), nil)
+				r.EXPECT().GetChanges(gomock.Any(), int64(6), int64(9), 101).Return([]entity.Change{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getChangesResponse{Changes: []entity.Change{}, NextToken: encodeChangeToken(9)}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:  "LongPoll",
+			query: "?wait=5&since=" + encodeChangeToken(6),
+			mockBehavior: func(r *mock_service.MockRepository) {
+				r.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).Times(2)
+				r.EXPECT().GetChanges(gomock.Any(), int64(6), int64(6), 101).Return([]entity.Change{}, nil).Times(2)
+				r.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(9), nil)
+				r.EXPECT().GetChanges(gomock.Any(), int64(6), int64(9), 101).Return(changes[1:], nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getChangesResponse{Changes: changes[1:], NextToken: encodeChangeToken(9)}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "InvalidToken",
+			query:              "?since=6&wait=600",
+			mockBehavior:       func(r *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrInvalidInput.Error(), Violations: []violation{
+					{Field: "since", Code: codeInvalidValue, Message: "since must be a token returned as next_token"},
+					{Field: "wait", Code: codeOutOfRange, Message: "wait must be between 0 and 60"},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newFeedServer(t, tt.mockBehavior)
+
+			resp, err := http.Get(server.URL + "/api/v1/persons/changes" + tt.query)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedResponseBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	"github.com/pintoter/persons/services/query/internal/service"
+	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFeedClient returns the SDK client of a server built from the real handler
+func newFeedClient(t *testing.T, behavior func(s *mock_service.MockRepository)) *personsclient.Client {
+	server, _ := newFeedServer(t, behavior)
+
+	client, err := personsclient.New(server.URL, personsclient.WithRetries(1, time.Millisecond))
+	assert.NoError(t, err)
+	return client
+}
+
+func Test_ClientGetPerson(t *testing.T) {
+	client := newFeedClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPerson(gomock.Any(), 1).Return(entity.Person{
+			ID:          1,
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			Age:         30,
+			Gender:      "male",
+			Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.9}},
+		}, nil)
+		s.EXPECT().GetPerson(gomock.Any(), 2).Return(entity.Person{}, entity.ErrPersonNotExists)
+		s.EXPECT().GetPerson(gomock.Any(), 3).Return(entity.Person{}, &entity.MergedError{Into: 1})
+	})
+
+	ctx := context.Background()
+	person, err := client.GetPerson(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, personsclient.Person{
+		ID:          1,
+		Name:        "Ivan",
+		Surname:     "Ivanov",
+		Age:         30,
+		Gender:      personsclient.Male,
+		Nationalize: []personsclient.Nationality{{Country: "RU", Probability: 0.9}},
+	}, person)
+
+	_, err = client.GetPerson(ctx, 2)
+	assert.ErrorIs(t, err, personsclient.ErrNotFound)
+
+	_, err = client.GetPerson(ctx, 3)
+	assert.ErrorIs(t, err, personsclient.ErrMerged)
+	var merged *personsclient.MergedError
+	if assert.ErrorAs(t, err, &merged) {
+		assert.Equal(t, 1, merged.MergedInto)
+	}
+}
+
+func Test_ClientPersons(t *testing.T) {
+	name := personsclient.String("Ivan")
+
+	client := newFeedClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Name: name, Gender: []string{"male"}, Limit: 3}).Return([]entity.Person{
+			{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male"},
+			{ID: 4, Name: "Ivan", Surname: "Petrov", Age: 41, Gender: "male"},
+			{ID: 6, Name: "Ivan", Surname: "Sidorov", Age: 25, Gender: "male"},
+		}, nil)
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+			Name:   name,
+			Gender: []string{"male"},
+			Cursor: &service.Cursor{Key: entity.Person{ID: 4}},
+			Limit:  3,
+		}).Return([]entity.Person{
+			{ID: 6, Name: "Ivan", Surname: "Sidorov", Age: 25, Gender: "male"},
+		}, nil)
+		s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(3), nil).Times(2)
+	})
+
+	var ids []int
+	for person, err := range client.Persons(context.Background(), personsclient.NewFilter().Name("ivan").Gender(personsclient.Male).Limit(2)) {
+		assert.NoError(t, err)
+		ids = append(ids, person.ID)
+	}
+	assert.Equal(t, []int{1, 4, 6}, ids)
+}
+
+func Test_ClientPersonsInvalid(t *testing.T) {
+	client := newFeedClient(t, func(s *mock_service.MockRepository) {})
+
+	_, err := client.GetPersons(context.Background(), personsclient.NewFilter().Age(-1))
+	assert.ErrorIs(t, err, personsclient.ErrInvalidInput)
+
+	var apiErr *personsclient.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, []personsclient.Violation{
+			{Field: "age", Code: codeOutOfRange, Message: "age must be between 0 and 150"},
+		}, apiErr.Violations)
+	}
+}
+
+func Test_ClientGetChanges(t *testing.T) {
+	changedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	mergedInto := 3
+
+	client := newFeedClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(9), nil)
+		s.EXPECT().GetChanges(gomock.Any(), int64(6), int64(9), 101).Return([]entity.Change{
+			{Type: entity.ChangeTombstone, PersonID: 1, MergedInto: &mergedInto, EventID: 6, ChangedAt: changedAt},
+		}, nil)
+	})
+
+	changes, err := client.GetChanges(context.Background(), encodeChangeToken(6), personsclient.ChangesOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, personsclient.ChangeSet{
+		Changes: []personsclient.Change{
+			{Type: personsclient.ChangeTombstone, PersonID: 1, MergedInto: &mergedInto, EventID: 6, ChangedAt: changedAt},
+		},
+		NextToken: encodeChangeToken(9),
+	}, changes)
+}
+
+func Test_ClientEvents(t *testing.T) {
+	client := newFeedClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lastEventID := int64(3)
+	var events []personsclient.PersonEvent
+	for event, err := range client.Events(ctx, personsclient.NewFilter().Name("ivan"), &lastEventID) {
+		assert.NoError(t, err)
+		events = append(events, event)
+		if len(events) == 2 {
+			break
+		}
+	}
+
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, int64(4), events[0].ID)
+		assert.Equal(t, personsclient.PersonCreated, events[0].Type)
+		assert.Equal(t, "Ivanov", events[0].Person.Surname)
+		assert.Equal(t, int64(6), events[1].ID)
+		assert.Equal(t, personsclient.PersonDeleted, events[1].Type)
+		assert.Equal(t, 3, *events[1].MergedInto)
+	}
+}
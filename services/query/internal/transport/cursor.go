@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+const cursorPrefix = "persons:"
+
+// cursorToken is the content of a cursor: the keyset order it was issued
+// for and the key values in that order
+type cursorToken struct {
+	Order    string            `json:"o"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+// orderSignature describes the keyset order, e.g. "age:desc,surname,id"
+func orderSignature(order []service.SortField) string {
+	fields := make([]string, 0, len(order))
+	for _, field := range order {
+		if field.Desc {
+			fields = append(fields, field.Field+":desc")
+		} else {
+			fields = append(fields, field.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// keyFields returns pointers to the person fields of the keyset order
+func keyFields(key *entity.Person, order []service.SortField) []any {
+	fields := make([]any, 0, len(order))
+	for _, field := range order {
+		switch field.Field {
+		case service.SortName:
+			fields = append(fields, &key.Name)
+		case service.SortSurname:
+			fields = append(fields, &key.Surname)
+		case service.SortPatronymic:
+			fields = append(fields, &key.Patronymic)
+		case service.SortAge:
+			fields = append(fields, &key.Age)
+		case service.SortGender:
+			fields = append(fields, &key.Gender)
+		case service.SortScore:
+			fields = append(fields, &key.Score)
+		default:
+			fields = append(fields, &key.ID)
+		}
+	}
+	return fields
+}
+
+// encodeCursor hides the key values, so clients don't build cursors
+// themselves and the format can change
+func encodeCursor(cursor *service.Cursor, sort []service.SortField) string {
+	if cursor == nil {
+		return ""
+	}
+
+	order := service.KeysetOrder(sort)
+	token := cursorToken{Order: orderSignature(order), Backward: cursor.Backward}
+	for _, field := range keyFields(&cursor.Key, order) {
+		value, _ := json.Marshal(field)
+		token.Values = append(token.Values, value)
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(append([]byte(cursorPrefix), data...))
+}
+
+// decodeCursor reads the cursor, it must be issued for the same sort order
+func decodeCursor(value string, sort []service.SortField) (*service.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := bytes.CutPrefix(data, []byte(cursorPrefix))
+	if !ok {
+		return nil, errors.New("unknown cursor format")
+	}
+
+	var token cursorToken
+	if err = json.Unmarshal(data, &token); err != nil {
+		return nil, err
+	}
+
+	order := service.KeysetOrder(sort)
+	if token.Order != orderSignature(order) || len(token.Values) != len(order) {
+		return nil, errors.New("cursor was issued for another sort order")
+	}
+
+	cursor := &service.Cursor{Backward: token.Backward}
+	for i, field := range keyFields(&cursor.Key, order) {
+		if err = json.Unmarshal(token.Values[i], field); err != nil {
+			return nil, err
+		}
+	}
+	return cursor, nil
+}
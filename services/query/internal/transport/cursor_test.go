@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cursor(t *testing.T) {
+	sort := []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}}
+	cursor := &service.Cursor{Key: entity.Person{ID: 4, Surname: "Ivanov", Age: 30}, Backward: true}
+
+	decoded, err := decodeCursor(encodeCursor(cursor, sort), sort)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	assert.Empty(t, encodeCursor(nil, sort))
+
+	for _, value := range []string{
+		"!!",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":"id","v":[4]}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`persons:{"o":"id","v":["4"]}`)),
+		encodeCursor(cursor, nil),
+		encodeCursor(cursor, sort[:1]),
+	} {
+		_, err = decodeCursor(value, sort)
+		assert.Error(t, err, value)
+	}
+}
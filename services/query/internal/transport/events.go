@@ -0,0 +1,199 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+	"golang.org/x/net/websocket"
+)
+
+type FeedConfig interface {
+	GetPollInterval() time.Duration
+	GetHeartbeat() time.Duration
+	GetBatchSize() int
+}
+
+// eventStream delivers feed events to one client
+type eventStream interface {
+	send(event entity.PersonEvent) error
+	ping() error
+}
+
+// @Summary Stream person changes
+// @Description Stream person created/updated/enriched/deleted events as Server-Sent Events, or over WebSocket
+// @Description when the request asks for upgrade. Events are delivered after they are applied to the read model.
+// @Tags persons
+// @Produce text/event-stream
+// @Param name query string false "name"
+// @Param surname query string false "surname"
+// @Param patronymic query string false "patronymic, empty selects persons without patronymic"
+// @Param age query int false "age"
+// @Param age_min query int false "minimal age, inclusive"
+// @Param age_max query int false "maximal age, inclusive"
+// @Param gender query string false "comma-separated genders"
+// @Param nationalize query string false "comma-separated country codes, any of them"
+// @Param primary_nationality query string false "comma-separated country codes, the most probable nationality is any of them"
+// @Param nationalize_min_probability query number false "least probability of the countries nationalize and primary_nationality match, 0 to 1"
+// @Param name! query string false "comma-separated names to exclude"
+// @Param surname! query string false "comma-separated surnames to exclude"
+// @Param patronymic! query string false "comma-separated patronymics to exclude, empty excludes persons without patronymic"
+// @Param gender! query string false "comma-separated genders to exclude"
+// @Param nationalize! query string false "comma-separated country codes to exclude"
+// @Param last_event_id query int false "id of the last received event to resume after"
+// @Param Last-Event-ID header int false "id of the last received event to resume after"
+// @Success 200 {object} entity.PersonEvent
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/persons/events [get]
+func (h *Handler) streamEvents(w http.ResponseWriter, r *http.Request) {
+	var input getEventsRequest
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	filters := &service.GetFilters{}
+	input.personFilters.convert(filters)
+
+	feed, err := h.service.OpenFeed(r.Context(), filters, input.LastEventID)
+	if err != nil {
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handler: func(conn *websocket.Conn) {
+			h.streamWebSocket(conn, feed)
+		}}.ServeHTTP(w, r)
+		return
+	}
+
+	h.streamSSE(w, r, feed)
+}
+
+func (h *Handler) streamSSE(w http.ResponseWriter, r *http.Request, feed *service.Feed) {
+	rc := http.NewResponseController(w)
+	// the stream outlives the server write timeout
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		logger.ErrorKV(r.Context(), "flush event stream", "layer", "transport.streamSSE", "err", err)
+		return
+	}
+
+	h.stream(r.Context(), feed, &sseStream{w: w, rc: rc})
+}
+
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *sseStream) send(event entity.PersonEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(s.w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *sseStream) ping() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (h *Handler) streamWebSocket(conn *websocket.Conn, feed *service.Feed) {
+	ctx, cancel := context.WithCancel(conn.Request().Context())
+	defer cancel()
+
+	_ = conn.SetDeadline(time.Time{})
+
+	// messages from the client are not expected, reading detects the close
+	go func() {
+		defer cancel()
+		var message string
+		for {
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+		}
+	}()
+
+	h.stream(ctx, feed, &webSocketStream{conn: conn})
+}
+
+type webSocketStream struct {
+	conn *websocket.Conn
+}
+
+func (s *webSocketStream) send(event entity.PersonEvent) error {
+	return websocket.JSON.Send(s.conn, event)
+}
+
+func (s *webSocketStream) ping() error {
+	s.conn.PayloadType = websocket.PingFrame
+	defer func() { s.conn.PayloadType = websocket.TextFrame }()
+
+	_, err := s.conn.Write(nil)
+	return err
+}
+
+// stream polls the feed and sends events until the client goes away or the
+// handler is closed
+func (h *Handler) stream(ctx context.Context, feed *service.Feed, stream eventStream) {
+	layer := "transport.stream"
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(h.closing, cancel)
+	defer stop()
+
+	poll := time.NewTicker(h.feed.GetPollInterval())
+	defer poll.Stop()
+	heartbeat := time.NewTicker(h.feed.GetHeartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		events, err := feed.Next(ctx, h.feed.GetBatchSize())
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorKV(ctx, "read feed", "layer", layer, "err", err)
+		}
+
+		for _, event := range events {
+			if err = stream.send(event); err != nil {
+				logger.DebugKV(ctx, "send event", "layer", layer, "err", err)
+				return
+			}
+		}
+		if len(events) > 0 {
+			heartbeat.Reset(h.feed.GetHeartbeat())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		case <-heartbeat.C:
+			if err = stream.ping(); err != nil {
+				logger.DebugKV(ctx, "ping", "layer", layer, "err", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/normalize"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	"github.com/pintoter/persons/services/query/internal/service"
+	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+type feedConfig struct{}
+
+func (feedConfig) GetPollInterval() time.Duration { return 10 * time.Millisecond }
+func (feedConfig) GetHeartbeat() time.Duration    { return time.Minute }
+func (feedConfig) GetBatchSize() int              { return 10 }
+
+var feedEvents = []projection.Event{
+	{
+		ID:         4,
+		Type:       projection.PersonCreated,
+		PersonID:   1,
+		Payload:    []byte(`{"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[{"country_id":"RU","probability":0.9}]}}`),
+		OccurredAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+	},
+	{
+		ID:         5,
+		Type:       projection.PersonCreated,
+		PersonID:   2,
+		Payload:    []byte(`{"person":{"id":2,"name":"Anna","surname":"Petrova","age":25,"gender":"female","nationalize":[]}}`),
+		OccurredAt: time.Date(2026, 10, 19, 12, 0, 1, 0, time.UTC),
+	},
+	{
+		ID:         6,
+		Type:       projection.PersonDeleted,
+		PersonID:   1,
+		Payload:    []byte(`{"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[{"country_id":"RU","probability":0.9}]},"merged_into":3}`),
+		OccurredAt: time.Date(2026, 10, 19, 12, 0, 2, 0, time.UTC),
+	},
+}
+
+func newFeedServer(t *testing.T, behavior func(s *mock_service.MockRepository)) (*httptest.Server, *Handler) {
+	c := gomock.NewController(t)
+
+	repo := mock_service.NewMockRepository(c)
+	behavior(repo)
+
+	handler := NewHandler(service.New(repo, normalize.New(normalizationConfig{})), feedConfig{})
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		handler.Close()
+		server.Close()
+	})
+	return server, handler
+}
+
+func Test_StreamEventsSSE(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/persons/events?name=ivan", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "3")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(lines) < 8 && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.Equal(t, []string{
+		"id: 4",
+		"event: PersonCreated",
+		`data: {"id":4,"type":"PersonCreated","person_id":1,"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[{"country_id":"RU","probability":0.9}]},"occurred_at":"2026-10-19T12:00:00Z"}`,
+		"",
+		"id: 6",
+		"event: PersonDeleted",
+		`data: {"id":6,"type":"PersonDeleted","person_id":1,"person":{"id":1,"name":"Ivan","surname":"Ivanov","age":30,"gender":"male","nationalize":[{"country_id":"RU","probability":0.9}]},"merged_into":3,"occurred_at":"2026-10-19T12:00:02Z"}`,
+		"",
+	}, lines)
+}
+
+func Test_StreamEventsNotAppliedYet(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		// events after the checkpoint are not visible in the read model yet
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(5), nil).Times(1)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(5), 10).Return(feedEvents[2:], nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/persons/events?last_event_id=3", nil)
+	assert.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(ids) < 3 && scanner.Scan() {
+		if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	assert.Equal(t, []string{"4", "5", "6"}, ids)
+}
+
+func Test_StreamEventsExcluded(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	resp, err := http.Get(server.URL + "/api/v1/persons/events?last_event_id=3&age_max=28&nationalize!=RU,KZ&patronymic=")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(lines) < 2 && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	assert.Equal(t, []string{"id: 5", "event: PersonCreated"}, lines)
+}
+
+func Test_StreamEventsWebSocket(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(3), nil).Times(1)
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/persons/events?nationalize=ru"
+	conn, err := websocket.Dial(url, "", server.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	var events []entity.PersonEvent
+	for len(events) < 2 {
+		var event entity.PersonEvent
+		if !assert.NoError(t, websocket.JSON.Receive(conn, &event)) {
+			return
+		}
+		events = append(events, event)
+	}
+
+	assert.Equal(t, int64(4), events[0].ID)
+	assert.Equal(t, int64(6), events[1].ID)
+	assert.Equal(t, 3, *events[1].MergedInto)
+}
+
+func Test_StreamEventsClosedOnShutdown(t *testing.T) {
+	server, handler := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	resp, err := http.Get(server.URL + "/api/v1/persons/events")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		_, _ = bufio.NewReader(resp.Body).ReadString(0)
+		close(done)
+	}()
+
+	handler.Close()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("stream is not closed")
+	}
+}
+
+func Test_StreamEventsInvalidRequest(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {})
+
+	resp, err := http.Get(server.URL + "/api/v1/persons/events?last_event_id=-1&limit=5")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,302 @@
+package transport
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/dataloader"
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/pintoter/persons/pkg/logger"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+const (
+	maxQueryDepth = 8
+	maxBodySize   = 1 << 16
+
+	// batchWait is how long person lookups are collected into one batch
+	batchWait = time.Millisecond
+)
+
+type loaderKey struct{}
+
+func newGraphQLSchema(service *service.Service) *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &graphQLResolver{service: service}, graphql.MaxDepth(maxQueryDepth))
+}
+
+// @Summary GraphQL query
+// @Description Run a GraphQL query, see schema.graphql
+// @Tags persons
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errorResponse
+// @Router /graphql [post]
+func (h *Handler) graphQL(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&params); err != nil {
+		var v validator
+		v.add("body", codeMalformedBody, "request body must be a valid JSON object")
+		renderError(w, r, http.StatusBadRequest, v.err())
+		return
+	}
+	logger.DebugKV(r.Context(), "graphql request", "operation", params.OperationName, "query", params.Query)
+
+	ctx := context.WithValue(r.Context(), loaderKey{}, newPersonLoader(h.service))
+	renderJSON(w, r, http.StatusOK, h.graphql.Exec(ctx, params.Query, params.OperationName, params.Variables))
+}
+
+// newPersonLoader batches person lookups by ID made while resolving one
+// request
+func newPersonLoader(s *service.Service) *dataloader.Loader {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		ids := make([]int, len(keys))
+		for i, key := range keys {
+			ids[i], _ = strconv.Atoi(key.String())
+		}
+		slices.Sort(ids)
+
+		results := make([]*dataloader.Result, len(keys))
+		persons, err := s.GetPersonsByIDs(ctx, ids)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result{Error: err}
+			}
+			return results
+		}
+
+		found := make(map[string]*entity.Person, len(persons))
+		for i := range persons {
+			found[strconv.Itoa(persons[i].ID)] = &persons[i]
+		}
+		for i, key := range keys {
+			results[i] = &dataloader.Result{Data: found[key.String()]}
+		}
+		return results
+	}, dataloader.WithWait(batchWait), dataloader.WithBatchCapacity(maxLimit))
+}
+
+// graphQLError adds violations to the extensions of the error
+type graphQLError struct {
+	err error
+}
+
+func (e graphQLError) Error() string {
+	return e.err.Error()
+}
+
+func (e graphQLError) Extensions() map[string]any {
+	var vErr *validationError
+	if errors.As(e.err, &vErr) {
+		return map[string]any{"code": "BAD_USER_INPUT", "violations": vErr.violations}
+	}
+	return nil
+}
+
+type graphQLResolver struct {
+	service *service.Service
+}
+
+func (r *graphQLResolver) Person(ctx context.Context, args struct{ ID graphql.ID }) (*personResolver, error) {
+	id, err := strconv.Atoi(string(args.ID))
+	if err != nil || id <= 0 {
+		return nil, entity.ErrInvalidQueryId
+	}
+
+	loader := ctx.Value(loaderKey{}).(*dataloader.Loader)
+	data, err := loader.Load(ctx, dataloader.StringKey(strconv.Itoa(id)))()
+	if err != nil {
+		return nil, err
+	}
+
+	person, _ := data.(*entity.Person)
+	if person == nil {
+		return nil, nil
+	}
+	return &personResolver{person: *person}, nil
+}
+
+type personFilterInput struct {
+	Name                      *string
+	Surname                   *string
+	Patronymic                *string
+	Age                       *int32
+	Gender                    *string
+	Nationalize               *string
+	PrimaryNationality        *string
+	NationalizeMinProbability *float64
+}
+
+type personSortInput struct {
+	Field     string
+	Direction string
+}
+
+type pageInput struct {
+	Limit  *int32
+	Number *int32
+}
+
+type personsArgs struct {
+	Filter *personFilterInput
+	Sort   *[]*personSortInput
+	Page   *pageInput
+}
+
+func (r *graphQLResolver) Persons(ctx context.Context, args personsArgs) ([]*personResolver, error) {
+	var input getPersonsRequest
+	if err := input.setQuery(args.query()); err != nil {
+		return nil, graphQLError{err}
+	}
+
+	data := &service.GetFilters{}
+	convertInputToGetFilters(data, &input)
+
+	persons, err := r.service.GetPersons(ctx, data)
+	if errors.Is(err, entity.ErrPersonNotExists) {
+		return []*personResolver{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*personResolver, len(persons))
+	for i, person := range persons {
+		result[i] = &personResolver{person: person}
+	}
+	return result, nil
+}
+
+// query turns the arguments into query parameters of GET /api/v1/persons,
+// so they are validated the same way
+func (a personsArgs) query() url.Values {
+	query := url.Values{}
+	set := func(key string, value *string) {
+		if value != nil {
+			query.Set(key, *value)
+		}
+	}
+	setInt := func(key string, value *int32) {
+		if value != nil {
+			query.Set(key, strconv.Itoa(int(*value)))
+		}
+	}
+
+	if f := a.Filter; f != nil {
+		set("name", f.Name)
+		set("surname", f.Surname)
+		set("patronymic", f.Patronymic)
+		setInt("age", f.Age)
+		set("gender", f.Gender)
+		set("nationalize", f.Nationalize)
+		set("primary_nationality", f.PrimaryNationality)
+		if f.NationalizeMinProbability != nil {
+			query.Set("nationalize_min_probability", strconv.FormatFloat(*f.NationalizeMinProbability, 'f', -1, 64))
+		}
+	}
+	if a.Sort != nil && len(*a.Sort) > 0 {
+		fields := make([]string, len(*a.Sort))
+		for i, sort := range *a.Sort {
+			fields[i] = strings.ToLower(sort.Field)
+			if sort.Direction == "DESC" {
+				fields[i] = "-" + fields[i]
+			}
+		}
+		query.Set("sort", strings.Join(fields, ","))
+	}
+	if p := a.Page; p != nil {
+		setInt("limit", p.Limit)
+		setInt("page", p.Number)
+	}
+	return query
+}
+
+type personResolver struct {
+	person entity.Person
+}
+
+func (r *personResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.person.ID))
+}
+
+func (r *personResolver) Name() string {
+	return r.person.Name
+}
+
+func (r *personResolver) Surname() string {
+	return r.person.Surname
+}
+
+func (r *personResolver) Patronymic() *string {
+	if r.person.Patronymic == "" {
+		return nil
+	}
+	return &r.person.Patronymic
+}
+
+func (r *personResolver) Age() int32 {
+	return int32(r.person.Age)
+}
+
+func (r *personResolver) Gender() string {
+	return r.person.Gender
+}
+
+func (r *personResolver) Nationalize(args struct {
+	MinProbability float64
+	First          *int32
+}) []*nationalityResolver {
+	nationalize := slices.Clone(r.person.Nationalize)
+	slices.SortStableFunc(nationalize, func(a, b entity.Nationality) int {
+		switch {
+		case a.Probability > b.Probability:
+			return -1
+		case a.Probability < b.Probability:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	result := make([]*nationalityResolver, 0, len(nationalize))
+	for _, n := range nationalize {
+		if n.Probability < args.MinProbability {
+			break
+		}
+		if args.First != nil && len(result) >= int(*args.First) {
+			break
+		}
+		result = append(result, &nationalityResolver{nationality: n})
+	}
+	return result
+}
+
+type nationalityResolver struct {
+	nationality entity.Nationality
+}
+
+func (r *nationalityResolver) CountryID() string {
+	return r.nationality.Country
+}
+
+func (r *nationalityResolver) Probability() float64 {
+	return r.nationality.Probability
+}
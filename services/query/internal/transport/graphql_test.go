@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/personsclient"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func postGraphQL(t *testing.T, url, query string) (int, string) {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	resp, err := http.Post(url+"/graphql", "application/json", strings.NewReader(string(body)))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func Test_GraphQLPersonBatched(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersonsByIDs(gomock.Any(), []int{1, 2, 3}).Return([]entity.Person{
+			{
+				ID:      1,
+				Name:    "Ivan",
+				Surname: "Ivanov",
+				Age:     30,
+				Gender:  "male",
+				Nationalize: []entity.Nationality{
+					{Country: "KZ", Probability: 0.2},
+					{Country: "RU", Probability: 0.7},
+					{Country: "BY", Probability: 0.05},
+				},
+			},
+			{ID: 2, Name: "Anna", Surname: "Petrova", Age: 25, Gender: "female"},
+		}, nil)
+	})
+
+	code, body := postGraphQL(t, server.URL, `{
+		a: person(id: 1) { id name patronymic nationalize(minProbability: 0.1) { countryId probability } }
+		b: person(id: 2) { name top: nationalize(first: 1) { countryId } }
+		c: person(id: 3) { id }
+	}`)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"data": {
+		"a": {"id": "1", "name": "Ivan", "patronymic": null, "nationalize": [
+			{"countryId": "RU", "probability": 0.7},
+			{"countryId": "KZ", "probability": 0.2}
+		]},
+		"b": {"name": "Anna", "top": []},
+		"c": null
+	}}`, body)
+}
+
+func Test_GraphQLPersons(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+			Name:   personsclient.String("Ivan"),
+			Sort:   []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}},
+			Limit:  2,
+			Offset: 2,
+		}).Return([]entity.Person{
+			{ID: 4, Name: "Ivan", Surname: "Sidorov", Age: 40, Gender: "male"},
+			{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male"},
+		}, nil)
+		minProbability := 0.5
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+			Gender:                    []string{"female"},
+			PrimaryNationality:        []string{"RU"},
+			NationalizeMinProbability: &minProbability,
+			Limit:                     defaultLimit,
+		}).Return(nil, entity.ErrPersonNotExists)
+	})
+
+	code, body := postGraphQL(t, server.URL, `{
+		persons(filter: {name: "Ivan"}, sort: [{field: AGE, direction: DESC}, {field: SURNAME}], page: {limit: 2, number: 2}) {
+			id surname age
+		}
+	}`)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"data": {"persons": [
+		{"id": "4", "surname": "Sidorov", "age": 40},
+		{"id": "1", "surname": "Ivanov", "age": 30}
+	]}}`, body)
+
+	code, body = postGraphQL(t, server.URL, `{
+		persons(filter: {gender: "female", primaryNationality: "ru", nationalizeMinProbability: 0.5}) { id }
+	}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"data": {"persons": []}}`, body)
+}
+
+func Test_GraphQLPersonsInvalid(t *testing.T) {
+	server, _ := newFeedServer(t, func(s *mock_service.MockRepository) {})
+
+	code, body := postGraphQL(t, server.URL, `{ persons(filter: {gender: "unknown"}, page: {limit: 1000}) { id } }`)
+	assert.Equal(t, http.StatusOK, code)
+
+	var resp struct {
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code       string      `json:"code"`
+				Violations []violation `json:"violations"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(body), &resp))
+	if assert.Len(t, resp.Errors, 1) {
+		assert.Equal(t, entity.ErrInvalidInput.Error(), resp.Errors[0].Message)
+		assert.Equal(t, "BAD_USER_INPUT", resp.Errors[0].Extensions.Code)
+		assert.Len(t, resp.Errors[0].Extensions.Violations, 2)
+	}
+
+	code, body = postGraphQL(t, server.URL, `{ person(id: 1) { unknownField } }`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `Cannot query field \"unknownField\" on type \"Person\"`)
+
+	resp2, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader("{"))
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp2.StatusCode)
+}
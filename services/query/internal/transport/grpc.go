@@ -0,0 +1,313 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/pintoter/persons/pkg/logger"
+	queryv1 "github.com/pintoter/persons/services/query/api/persons/query/v1"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+const errorDomain = "persons.query"
+
+// GRPCHandler serves PersonQueryService. Requests are validated as query
+// parameters of the matching HTTP endpoints, and change streams share the
+// feed settings and shutdown of the HTTP handler.
+type GRPCHandler struct {
+	queryv1.UnimplementedPersonQueryServiceServer
+	handler *Handler
+}
+
+func NewGRPCHandler(handler *Handler) *GRPCHandler {
+	return &GRPCHandler{handler: handler}
+}
+
+func (h *GRPCHandler) Register(s *grpc.Server) {
+	queryv1.RegisterPersonQueryServiceServer(s, h)
+}
+
+// RecoveryInterceptor turns a panic of a handler into INTERNAL status
+// instead of crashing the server
+func RecoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer recoverStatus(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+// StreamRecoveryInterceptor is RecoveryInterceptor for streaming methods
+func StreamRecoveryInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverStatus(ss.Context(), info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+func recoverStatus(ctx context.Context, method string, err *error) {
+	if r := recover(); r != nil {
+		logger.ErrorKV(ctx, "grpc handler panicked", "method", method, "panic", r)
+		*err = status.Error(codes.Internal, entity.ErrInternalService.Error())
+	}
+}
+
+func (h *GRPCHandler) GetPerson(ctx context.Context, req *queryv1.GetPersonRequest) (*queryv1.GetPersonResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, grpcError(entity.ErrInvalidQueryId)
+	}
+
+	var asOf *time.Time
+	if req.AsOf != nil {
+		t := req.GetAsOf().AsTime()
+		asOf = &t
+	}
+
+	var nationalities int
+	if req.Nationalities != nil {
+		var v validator
+		if v.oneOf("nationalities", req.GetNationalities(), "top1", "top3", "all") {
+			nationalities = nationalitiesModes[req.GetNationalities()]
+		}
+		if err := v.err(); err != nil {
+			return nil, grpcError(err)
+		}
+	}
+
+	person, err := h.handler.service.GetPerson(ctx, int(req.GetId()), asOf, nationalities)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &queryv1.GetPersonResponse{Person: personToProto(person)}, nil
+}
+
+func (h *GRPCHandler) GetPersons(ctx context.Context, req *queryv1.GetPersonsRequest) (*queryv1.GetPersonsResponse, error) {
+	query := filterQuery(req.GetFilter())
+	if req.GetLimit() != 0 {
+		query.Set("limit", strconv.Itoa(int(req.GetLimit())))
+	}
+	if req.GetPage() != 0 {
+		query.Set("page", strconv.Itoa(int(req.GetPage())))
+	}
+	if req.AsOf != nil {
+		query.Set("as_of", req.GetAsOf().AsTime().Format(time.RFC3339Nano))
+	}
+	setOptional(query, "q", req.Q)
+	setOptional(query, "match", req.Match)
+	setOptional(query, "sort", req.Sort)
+	setOptional(query, "nationalities", req.Nationalities)
+	setOptional(query, "cursor", req.Cursor)
+
+	var input getPersonsRequest
+	if err := input.setQuery(query); err != nil {
+		return nil, grpcError(err)
+	}
+
+	data := &service.GetFilters{}
+	convertInputToGetFilters(data, &input)
+
+	page, err := h.handler.service.GetPersonsPage(ctx, data)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &queryv1.GetPersonsResponse{
+		Persons:    make([]*queryv1.Person, len(page.Persons)),
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: encodeCursor(page.Next, data.Sort),
+		PrevCursor: encodeCursor(page.Prev, data.Sort),
+	}
+	for i, person := range page.Persons {
+		resp.Persons[i] = personToProto(person)
+	}
+	return resp, nil
+}
+
+func (h *GRPCHandler) ListPersons(req *queryv1.ListPersonsRequest, stream grpc.ServerStreamingServer[queryv1.Person]) error {
+	filters, err := personFilterFromProto(req.GetFilter())
+	if err != nil {
+		return grpcError(err)
+	}
+
+	err = h.handler.service.ListPersons(stream.Context(), filters, int64(h.handler.feed.GetBatchSize()), func(person entity.Person) error {
+		return stream.Send(personToProto(person))
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return grpcError(err)
+	}
+	return nil
+}
+
+func (h *GRPCHandler) WatchChanges(req *queryv1.WatchChangesRequest, stream grpc.ServerStreamingServer[queryv1.PersonEvent]) error {
+	filters, err := personFilterFromProto(req.GetFilter())
+	if err != nil {
+		return grpcError(err)
+	}
+	if req.LastEventId != nil && req.GetLastEventId() < 0 {
+		var v validator
+		v.add("last_event_id", codeInvalidType, "last_event_id must be a non-negative integer")
+		return grpcError(v.err())
+	}
+
+	feed, err := h.handler.service.OpenFeed(stream.Context(), filters, req.LastEventId)
+	if err != nil {
+		return grpcError(err)
+	}
+
+	h.handler.stream(stream.Context(), feed, &grpcEventStream{stream: stream})
+	return nil
+}
+
+type grpcEventStream struct {
+	stream grpc.ServerStreamingServer[queryv1.PersonEvent]
+}
+
+func (s *grpcEventStream) send(event entity.PersonEvent) error {
+	return s.stream.Send(eventToProto(event))
+}
+
+// ping does nothing, HTTP/2 keeps the connection alive
+func (s *grpcEventStream) ping() error {
+	return nil
+}
+
+// filterQuery turns the filter into query parameters of GET /api/v1/persons,
+// a nil filter matches any person
+func filterQuery(filter *queryv1.PersonFilter) url.Values {
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+
+	setOptional(query, "name", filter.Name)
+	setOptional(query, "surname", filter.Surname)
+	setOptional(query, "patronymic", filter.Patronymic)
+	for key, value := range map[string]*int32{"age": filter.Age, "age_min": filter.AgeMin, "age_max": filter.AgeMax} {
+		if value != nil {
+			query.Set(key, strconv.Itoa(int(*value)))
+		}
+	}
+	setOptional(query, "gender", filter.Gender)
+	setOptional(query, "nationalize", filter.Nationalize)
+	setOptional(query, "primary_nationality", filter.PrimaryNationality)
+	if filter.NationalizeMinProbability != nil {
+		query.Set("nationalize_min_probability", strconv.FormatFloat(filter.GetNationalizeMinProbability(), 'g', -1, 64))
+	}
+
+	if exclude := filter.GetExclude(); exclude != nil {
+		setOptional(query, "name!", exclude.Name)
+		setOptional(query, "surname!", exclude.Surname)
+		setOptional(query, "patronymic!", exclude.Patronymic)
+		setOptional(query, "gender!", exclude.Gender)
+		setOptional(query, "nationalize!", exclude.Nationalize)
+	}
+	return query
+}
+
+func setOptional(query url.Values, key string, value *string) {
+	if value != nil {
+		query.Set(key, *value)
+	}
+}
+
+func personFilterFromProto(filter *queryv1.PersonFilter) (*service.GetFilters, error) {
+	var v validator
+	var input personFilters
+	input.set(&v, filterQuery(filter))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	filters := &service.GetFilters{}
+	input.convert(filters)
+	return filters, nil
+}
+
+// grpcError maps service errors to status codes the way the HTTP handlers
+// map them to HTTP statuses. Violations and the merge survivor are sent as
+// error details.
+func grpcError(err error) error {
+	var vErr *validationError
+	if errors.As(err, &vErr) {
+		details := &errdetails.BadRequest{}
+		for _, v := range vErr.violations {
+			details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+				Reason:      v.Code,
+			})
+		}
+		return withDetails(status.New(codes.InvalidArgument, vErr.Error()), details)
+	}
+
+	var mergedErr *entity.MergedError
+	if errors.As(err, &mergedErr) {
+		return withDetails(status.New(codes.NotFound, mergedErr.Error()), &errdetails.ErrorInfo{
+			Reason:   "PERSON_MERGED",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"merged_into": strconv.Itoa(mergedErr.Into)},
+		})
+	}
+
+	switch {
+	case errors.Is(err, entity.ErrPersonNotExists):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrInvalidInput), errors.Is(err, entity.ErrInvalidQueryId):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func personToProto(person entity.Person) *queryv1.Person {
+	nationalize := make([]*queryv1.Nationality, len(person.Nationalize))
+	for i, n := range person.Nationalize {
+		nationalize[i] = &queryv1.Nationality{CountryId: n.Country, Probability: n.Probability}
+	}
+
+	return &queryv1.Person{
+		Id:          int64(person.ID),
+		Name:        person.Name,
+		Surname:     person.Surname,
+		Patronymic:  person.Patronymic,
+		Age:         int32(person.Age),
+		Gender:      person.Gender,
+		Nationalize: nationalize,
+	}
+}
+
+func eventToProto(event entity.PersonEvent) *queryv1.PersonEvent {
+	result := &queryv1.PersonEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		PersonId:   int64(event.PersonID),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+	if event.Person != nil {
+		result.Person = personToProto(*event.Person)
+	}
+	if event.MergedInto != nil {
+		into := int64(*event.MergedInto)
+		result.MergedInto = &into
+	}
+	return result
+}
@@ -0,0 +1,247 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pintoter/persons/pkg/normalize"
+	queryv1 "github.com/pintoter/persons/services/query/api/persons/query/v1"
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/projection"
+	"github.com/pintoter/persons/services/query/internal/service"
+	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGRPCClient(t *testing.T, behavior func(s *mock_service.MockRepository)) queryv1.PersonQueryServiceClient {
+	c := gomock.NewController(t)
+
+	repo := mock_service.NewMockRepository(c)
+	behavior(repo)
+
+	handler := NewHandler(service.New(repo, normalize.New(normalizationConfig{})), feedConfig{})
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(RecoveryInterceptor),
+		grpc.ChainStreamInterceptor(StreamRecoveryInterceptor),
+	)
+	NewGRPCHandler(handler).Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		handler.Close()
+		server.Stop()
+	})
+
+	return queryv1.NewPersonQueryServiceClient(conn)
+}
+
+func Test_GRPCGetPerson(t *testing.T) {
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPerson(gomock.Any(), 1).Return(entity.Person{
+			ID:          1,
+			Name:        "Ivan",
+			Surname:     "Ivanov",
+			Age:         30,
+			Gender:      "male",
+			Nationalize: []entity.Nationality{{Country: "KZ", Probability: 0.05}, {Country: "RU", Probability: 0.9}},
+		}, nil)
+		s.EXPECT().GetPerson(gomock.Any(), 2).Return(entity.Person{}, &entity.MergedError{Into: 1})
+	})
+
+	top1 := "top1"
+	resp, err := client.GetPerson(context.Background(), &queryv1.GetPersonRequest{Id: 1, Nationalities: &top1})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ivan", resp.GetPerson().GetName())
+	if assert.Len(t, resp.GetPerson().GetNationalize(), 1) {
+		assert.Equal(t, "RU", resp.GetPerson().GetNationalize()[0].GetCountryId())
+	}
+
+	_, err = client.GetPerson(context.Background(), &queryv1.GetPersonRequest{Id: 2})
+	st := status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		assert.Equal(t, "1", st.Details()[0].(*errdetails.ErrorInfo).GetMetadata()["merged_into"])
+	}
+}
+
+func Test_GRPCGetPersonPanic(t *testing.T) {
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPerson(gomock.Any(), 1).DoAndReturn(func(context.Context, int) (entity.Person, error) {
+			panic("unexpected")
+		})
+		s.EXPECT().GetPerson(gomock.Any(), 2).Return(entity.Person{ID: 2, Name: "Ivan"}, nil)
+	})
+
+	_, err := client.GetPerson(context.Background(), &queryv1.GetPersonRequest{Id: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	// the server keeps serving after the panic
+	resp, err := client.GetPerson(context.Background(), &queryv1.GetPersonRequest{Id: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ivan", resp.GetPerson().GetName())
+}
+
+func Test_GRPCGetPersons(t *testing.T) {
+	ageMin, ageMax := int32(20), int32(40)
+	primary, probability := "ru,ua", 0.5
+	excludedNames, excludedPatronymic := "Petr,Pavel", ""
+
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersons(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filters *service.GetFilters) ([]entity.Person, error) {
+			assert.Equal(t, 20, *filters.AgeMin)
+			assert.Equal(t, 40, *filters.AgeMax)
+			assert.Equal(t, []string{"RU", "UA"}, filters.PrimaryNationality)
+			assert.Equal(t, 0.5, *filters.NationalizeMinProbability)
+			assert.Equal(t, []string{"Petr", "Pavel"}, filters.Exclude.Name)
+			assert.Equal(t, []string{""}, filters.Exclude.Patronymic)
+			assert.Equal(t, []service.SortField{{Field: service.SortAge, Desc: true}}, filters.Sort)
+			return []entity.Person{
+				{ID: 1, Name: "Ivan", Age: 40},
+				{ID: 2, Name: "Oleg", Age: 30},
+				{ID: 3, Name: "Igor", Age: 20},
+			}, nil
+		})
+		s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+	})
+
+	sort := "-age"
+	resp, err := client.GetPersons(context.Background(), &queryv1.GetPersonsRequest{
+		Filter: &queryv1.PersonFilter{
+			AgeMin:                    &ageMin,
+			AgeMax:                    &ageMax,
+			PrimaryNationality:        &primary,
+			NationalizeMinProbability: &probability,
+			Exclude:                   &queryv1.PersonExclusions{Name: &excludedNames, Patronymic: &excludedPatronymic},
+		},
+		Sort:  &sort,
+		Limit: 2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetPersons(), 2)
+	assert.Equal(t, int64(3), resp.GetTotal())
+	assert.True(t, resp.GetHasMore())
+	assert.NotEmpty(t, resp.GetNextCursor())
+	assert.Empty(t, resp.GetPrevCursor())
+}
+
+func Test_GRPCGetPersonsWithoutFilter(t *testing.T) {
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Limit: defaultLimit + 1}).Return(nil, entity.ErrPersonNotExists)
+		s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+		s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Limit: 10}).Return(nil, entity.ErrPersonNotExists)
+	})
+
+	resp, err := client.GetPersons(context.Background(), &queryv1.GetPersonsRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.GetPersons())
+
+	stream, err := client.ListPersons(context.Background(), &queryv1.ListPersonsRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func Test_GRPCGetPersonsInvalid(t *testing.T) {
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {})
+
+	gender := "unknown"
+	_, err := client.GetPersons(context.Background(), &queryv1.GetPersonsRequest{
+		Filter: &queryv1.PersonFilter{Gender: &gender},
+		Limit:  1000,
+	})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		violations := st.Details()[0].(*errdetails.BadRequest).GetFieldViolations()
+		fields := make([]string, len(violations))
+		for i, v := range violations {
+			fields[i] = v.GetField()
+		}
+		assert.ElementsMatch(t, []string{"gender", "limit"}, fields)
+	}
+}
+
+func Test_GRPCListPersons(t *testing.T) {
+	page := make([]entity.Person, 10)
+	for i := range page {
+		page[i] = entity.Person{ID: i + 1, Name: "Ivan", Surname: "Ivanov"}
+	}
+
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().GetPersons(gomock.Any(), gomock.Any()).Return(page, nil)
+		s.EXPECT().GetPersons(gomock.Any(), gomock.Any()).Return(page[:2], nil)
+	})
+
+	stream, err := client.ListPersons(context.Background(), &queryv1.ListPersonsRequest{
+		Filter: &queryv1.PersonFilter{Name: proto.String("ivan")},
+	})
+	assert.NoError(t, err)
+
+	var ids []int64
+	for {
+		person, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		ids = append(ids, person.GetId())
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2}, ids)
+}
+
+func Test_GRPCWatchChanges(t *testing.T) {
+	client := newGRPCClient(t, func(s *mock_service.MockRepository) {
+		s.EXPECT().Checkpoint(gomock.Any(), projection.Name).Return(int64(6), nil).AnyTimes()
+		s.EXPECT().EventsAfter(gomock.Any(), int64(3), 10).Return(feedEvents, nil)
+		s.EXPECT().EventsAfter(gomock.Any(), int64(6), 10).Return(nil, nil).AnyTimes()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	name := "ivan"
+	lastEventID := int64(3)
+	stream, err := client.WatchChanges(ctx, &queryv1.WatchChangesRequest{
+		Filter:      &queryv1.PersonFilter{Name: &name},
+		LastEventId: &lastEventID,
+	})
+	assert.NoError(t, err)
+
+	var events []*queryv1.PersonEvent
+	for len(events) < 2 {
+		event, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			return
+		}
+		events = append(events, event)
+	}
+
+	assert.Equal(t, int64(4), events[0].GetId())
+	assert.Equal(t, projection.PersonCreated, events[0].GetType())
+	assert.Equal(t, "Ivan", events[0].GetPerson().GetName())
+	assert.Equal(t, int64(6), events[1].GetId())
+	assert.Equal(t, int64(3), events[1].GetMergedInto())
+}
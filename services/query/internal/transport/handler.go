@@ -1,9 +1,12 @@
 package transport
 
 import (
+	"context"
+	"expvar"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/pintoter/persons/services/query/internal/service"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
@@ -11,12 +14,22 @@ import (
 type Handler struct {
 	router  *mux.Router
 	service *service.Service
+	feed    FeedConfig
+	graphql *graphql.Schema
+
+	closing context.Context
+	close   context.CancelFunc
 }
 
-func NewHandler(service *service.Service) *Handler {
+func NewHandler(service *service.Service, feed FeedConfig) *Handler {
+	closing, cancel := context.WithCancel(context.Background())
 	handler := &Handler{
 		router:  mux.NewRouter(),
 		service: service,
+		feed:    feed,
+		graphql: newGraphQLSchema(service),
+		closing: closing,
+		close:   cancel,
 	}
 
 	handler.router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
@@ -26,6 +39,10 @@ func NewHandler(service *service.Service) *Handler {
 		httpSwagger.DomID("swagger-ui"),
 	)).Methods(http.MethodGet)
 
+	handler.router.HandleFunc("/graphql", handler.graphQL).Methods(http.MethodPost)
+
+	handler.router.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
+
 	handler.InitRoutes()
 
 	return handler
@@ -36,9 +53,18 @@ func (h *Handler) InitRoutes() {
 	{
 		v1.HandleFunc("/persons/{id:[0-9]+}", h.getPerson).Methods(http.MethodGet)
 		v1.HandleFunc("/persons", h.getPersons).Methods(http.MethodGet)
+		v1.HandleFunc("/persons/events", h.streamEvents).Methods(http.MethodGet)
+		v1.HandleFunc("/persons/changes", h.getChanges).Methods(http.MethodGet)
+		v1.HandleFunc("/persons/{id:[0-9]+}/history", h.getHistory).Methods(http.MethodGet)
 	}
 }
 
+// Close ends open event streams, which would otherwise block graceful
+// shutdown of the server
+func (h *Handler) Close() {
+	h.close()
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
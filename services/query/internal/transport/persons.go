@@ -2,10 +2,10 @@ package transport
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
-	"github.com/gorilla/mux"
 	"github.com/pintoter/persons/pkg/logger"
 	"github.com/pintoter/persons/services/query/internal/entity"
 	"github.com/pintoter/persons/services/query/internal/service"
@@ -16,24 +16,32 @@ import (
 // @Tags persons
 // @Produce json
 // @Param id path int true "id"
+// @Param nationalities query string false "nationalities returned: the most probable one (top1), three (top3) or all (default)"
+// @Param as_of query string false "RFC 3339 timestamp to get the person as it was at"
 // @Success 200 {object} getPersonResponse
+// @Success 301 {object} errorResponse "person was merged, Location points to the survivor"
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/v1/persons/{id} [get]
 func (h *Handler) getPerson(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.Atoi(mux.Vars(r)["id"])
-	if id == 0 {
-		renderJSON(w, r, http.StatusBadRequest, errorResponse{entity.ErrInvalidInput.Error()})
+	var input getPersonRequest
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	person, err := h.service.GetPerson(r.Context(), id)
+	person, err := h.service.GetPerson(r.Context(), input.ID, input.AsOf, input.Nationalities)
 	if err != nil {
-		if errors.Is(err, entity.ErrPersonNotExists) {
-			renderJSON(w, r, http.StatusNotFound, errorResponse{entity.ErrPersonNotExists.Error()})
-		} else {
-			renderJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+		var mergedErr *entity.MergedError
+		switch {
+		case errors.As(err, &mergedErr):
+			w.Header().Set("Location", fmt.Sprintf("/api/v1/persons/%d", mergedErr.Into))
+			renderJSON(w, r, http.StatusMovedPermanently, errorResponse{Err: mergedErr.Error(), MergedInto: &mergedErr.Into})
+		case errors.Is(err, entity.ErrPersonNotExists):
+			renderJSON(w, r, http.StatusNotFound, errorResponse{Err: entity.ErrPersonNotExists.Error()})
+		default:
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
 		}
 		return
 	}
@@ -42,25 +50,42 @@ func (h *Handler) getPerson(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Get all persons
-// @Description Get all persons
+// @Description Get persons page by page. Follow next_cursor and prev_cursor for pages that stay consistent while
+// @Description persons are added, page selects a page by its offset.
 // @Tags persons
 // @Produce json
+// @Param q query string false "search by name parts starting with it or similar to it, results are ranked by score"
+// @Param match query string false "how q matches: fuzzy (default) or phonetic, by the sound of names and surnames"
+// @Param sort query string false "comma-separated fields to sort by, descending ones prefixed with a minus, e.g. -age,surname; ties are broken by id"
 // @Param name query string false "name"
 // @Param surname query string false "surname"
-// @Param patronymic query string false "patronymic"
+// @Param patronymic query string false "patronymic, empty selects persons without patronymic"
 // @Param age query int false "age"
-// @Param gender query string false "gender"
-// @Param nationalize query string false "nationalize"
+// @Param age_min query int false "minimal age, inclusive"
+// @Param age_max query int false "maximal age, inclusive"
+// @Param gender query string false "comma-separated genders"
+// @Param nationalize query string false "comma-separated country codes, any of them"
+// @Param primary_nationality query string false "comma-separated country codes, the most probable nationality is any of them"
+// @Param nationalize_min_probability query number false "least probability of the countries nationalize and primary_nationality match, 0 to 1"
+// @Param name! query string false "comma-separated names to exclude"
+// @Param surname! query string false "comma-separated surnames to exclude"
+// @Param patronymic! query string false "comma-separated patronymics to exclude, empty excludes persons without patronymic"
+// @Param gender! query string false "comma-separated genders to exclude"
+// @Param nationalize! query string false "comma-separated country codes to exclude"
+// @Param as_of query string false "RFC 3339 timestamp to get persons as they were at"
+// @Param cursor query string false "next_cursor or prev_cursor of the previous response, can't be combined with page"
 // @Param limit query int false "limit"
 // @Param page query int false "page"
 // @Success 200 {object} getPersonsResponse
+// @Header 200 {integer} X-Total-Count "number of persons matching the filters"
+// @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /api/v1/persons [get]
 func (h *Handler) getPersons(w http.ResponseWriter, r *http.Request) {
 	var input getPersonsRequest
 
 	if err := input.Set(r); err != nil {
-		renderJSON(w, r, http.StatusBadRequest, errorResponse{err.Error()})
+		renderError(w, r, http.StatusBadRequest, err)
 		return
 	}
 	logger.DebugKV(r.Context(), "get persons request", "input", input)
@@ -70,40 +95,99 @@ func (h *Handler) getPersons(w http.ResponseWriter, r *http.Request) {
 
 	logger.DebugKV(r.Context(), "get persons request", "input filters", data)
 
-	persons, err := h.service.GetPersons(r.Context(), data)
+	page, err := h.service.GetPersonsPage(r.Context(), data)
 	if err != nil {
-		renderJSON(w, r, http.StatusInternalServerError, errorResponse{err.Error()})
+		renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
 		return
 	}
 
-	renderJSON(w, r, http.StatusOK, getPersonsResponse{Persons: persons})
+	if page.Persons == nil {
+		page.Persons = []entity.Person{}
+	}
+	resp := getPersonsResponse{
+		Persons:    page.Persons,
+		Total:      page.Total,
+		Limit:      input.Limit,
+		HasMore:    page.HasMore,
+		NextCursor: encodeCursor(page.Next, data.Sort),
+		PrevCursor: encodeCursor(page.Prev, data.Sort),
+	}
+	if input.Cursor == nil {
+		resp.Page = input.Page
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	renderJSON(w, r, http.StatusOK, resp)
 }
 
-func convertInputToGetFilters(data *service.GetFilters, input *getPersonsRequest) {
-	if input.Name != "" {
-		data.Name = &input.Name
+// @Summary Get person history
+// @Description Get recorded changes of person by id, the newest first
+// @Tags persons
+// @Produce json
+// @Param id path int true "id"
+// @Param limit query int false "limit"
+// @Param page query int false "page"
+// @Success 200 {object} getHistoryResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /api/v1/persons/{id}/history [get]
+func (h *Handler) getHistory(w http.ResponseWriter, r *http.Request) {
+	var input getHistoryRequest
+	if err := input.Set(r); err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
 	}
 
-	if input.Surname != "" {
-		data.Surname = &input.Surname
+	limit := int64(input.Limit)
+	history, err := h.service.GetHistory(r.Context(), input.ID, limit, (int64(input.Page)-1)*limit)
+	if err != nil {
+		if errors.Is(err, entity.ErrPersonNotExists) {
+			renderJSON(w, r, http.StatusNotFound, errorResponse{Err: entity.ErrPersonNotExists.Error()})
+		} else {
+			renderJSON(w, r, http.StatusInternalServerError, errorResponse{Err: err.Error()})
+		}
+		return
 	}
 
-	if input.Patronymic != "" {
-		data.Patronymic = &input.Patronymic
+	renderJSON(w, r, http.StatusOK, getHistoryResponse{History: history, Limit: input.Limit, Page: input.Page})
+}
+
+func convertInputToGetFilters(data *service.GetFilters, input *getPersonsRequest) {
+	input.personFilters.convert(data)
+
+	if input.Query != "" {
+		data.Query = &input.Query
+		data.Match = input.Match
 	}
+	data.Nationalities = input.Nationalities
+	data.AsOf = input.AsOf
+	data.Sort = input.Sort
+	data.Cursor = input.Cursor
+	data.Limit = int64(input.Limit)
+	data.Offset = (int64(input.Page) - 1) * data.Limit
+}
 
-	if input.Age != 0 {
-		data.Age = &input.Age
+func (p *personFilters) convert(data *service.GetFilters) {
+	if p.Name != "" {
+		data.Name = &p.Name
 	}
 
-	if input.Gender != "" {
-		data.Gender = &input.Gender
+	if p.Surname != "" {
+		data.Surname = &p.Surname
 	}
 
-	if input.Nationalize != "" {
-		data.Nationalize = &input.Nationalize
+	data.Patronymic = p.Patronymic
+
+	if p.Age != 0 {
+		data.Age = &p.Age
 	}
 
-	data.Limit = int64(input.Limit)
-	data.Offset = (int64(input.Page) - 1) * data.Limit
+	data.AgeMin = p.AgeMin
+	data.AgeMax = p.AgeMax
+	data.Gender = p.Gender
+	data.Nationalize = p.Nationalize
+	data.PrimaryNationality = p.PrimaryNationality
+	data.NationalizeMinProbability = p.NationalizeMinProbability
+	data.Exclude = p.Exclude
 }
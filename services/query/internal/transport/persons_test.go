@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pintoter/persons/pkg/normalize"
 	"github.com/pintoter/persons/services/query/internal/entity"
 	"github.com/pintoter/persons/services/query/internal/service"
 	mock_service "github.com/pintoter/persons/services/query/internal/service/mocks"
@@ -17,12 +20,20 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+type normalizationConfig struct{}
+
+func (normalizationConfig) IsEnabled() bool        { return true }
+func (normalizationConfig) ShouldCapitalize() bool { return true }
+func (normalizationConfig) GetLanguage() string    { return "en" }
+func (normalizationConfig) GetParticles() []string { return nil }
+
 func Test_GetPersonHandler(t *testing.T) {
 	type mockBehavior func(s *mock_service.MockRepository, id int)
 
 	tests := []struct {
 		name                 string
 		inputId              int
+		query                string
 		mockBehavior         mockBehavior
 		expectedStatusCode   int
 		expectedResponseBody string
@@ -73,6 +84,78 @@ func Test_GetPersonHandler(t *testing.T) {
 				return string(resp)
 			}(),
 		},
+		{
+			name:    "OkAsOf",
+			inputId: 1,
+			query:   "?as_of=2026-07-01T00:00:00Z",
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetPersonAsOf(gomock.Any(), id, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)).
+					Return(entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{
+					Person: entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:    "OkWithTop1Nationalities",
+			inputId: 1,
+			query:   "?nationalities=top1",
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetPerson(gomock.Any(), id).Return(entity.Person{
+					ID:   1,
+					Name: "Ivan",
+					Nationalize: []entity.Nationality{
+						{Country: "KZ", Probability: 0.05},
+						{Country: "RU", Probability: 0.1},
+					},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonResponse{Person: entity.Person{
+					ID:          1,
+					Name:        "Ivan",
+					Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.1}},
+				}}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidNationalities",
+			inputId:            1,
+			query:              "?nationalities=top2",
+			mockBehavior:       func(s *mock_service.MockRepository, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "nationalities", Code: codeInvalidValue, Message: "nationalities must be one of: top1, top3, all"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidAsOf",
+			inputId:            1,
+			query:              "?as_of=yesterday",
+			mockBehavior:       func(s *mock_service.MockRepository, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "as_of", Code: codeInvalidType, Message: "as_of must be an RFC 3339 timestamp"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
 		{
 			name:    "FailedNotExist",
 			inputId: 2,
@@ -87,6 +170,22 @@ func Test_GetPersonHandler(t *testing.T) {
 				return string(resp)
 			}(),
 		},
+		{
+			name:    "Merged",
+			inputId: 3,
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetPerson(gomock.Any(), id).Return(entity.Person{}, &entity.MergedError{Into: 1})
+			},
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedResponseBody: func() string {
+				mergedInto := 1
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err:        entity.ErrPersonMerged.Error(),
+					MergedInto: &mergedInto,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
 		{
 			name:    "FailedWithId",
 			inputId: 0,
@@ -124,12 +223,12 @@ func Test_GetPersonHandler(t *testing.T) {
 			repo := mock_service.NewMockRepository(c)
 			tt.mockBehavior(repo, tt.inputId)
 
-			service := service.New(repo)
+			service := service.New(repo, normalize.New(normalizationConfig{}))
 
-			handler := NewHandler(service)
+			handler := NewHandler(service, feedConfig{})
 
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest("GET", "/api/v1/persons/"+fmt.Sprintf("%d", tt.inputId), nil)
+			r := httptest.NewRequest("GET", "/api/v1/persons/"+fmt.Sprintf("%d", tt.inputId)+tt.query, nil)
 
 			handler.ServeHTTP(w, r)
 
@@ -192,6 +291,7 @@ func Test_GetPersonsHandler(t *testing.T) {
 						},
 					},
 					nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(2), nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: func() string {
@@ -231,7 +331,7 @@ func Test_GetPersonsHandler(t *testing.T) {
 							},
 						},
 					},
-				}}, "", "    ")
+				}, Total: 2, Page: 1, Limit: 5}, "", "    ")
 				return string(resp)
 			}(),
 		},
@@ -278,6 +378,7 @@ func Test_GetPersonsHandler(t *testing.T) {
 						},
 					},
 					nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(2), nil)
 			},
 			expectedStatusCode: http.StatusOK,
 			expectedResponseBody: func() string {
@@ -317,7 +418,24 @@ func Test_GetPersonsHandler(t *testing.T) {
 							},
 						},
 					},
-				}}, "", "    ")
+				}, Total: 2, Page: 1, Limit: 5}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithNormalizedName",
+			path: "?name=%20IVAN%20",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				name := "Ivan"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Name: &name, Limit: defaultLimit + 1}).Return(
+					[]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{Persons: []entity.Person{
+					{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				}, Total: 1, Page: 1, Limit: 5}, "", "    ")
 				return string(resp)
 			}(),
 		},
@@ -343,6 +461,634 @@ func Test_GetPersonsHandler(t *testing.T) {
 			expectedResponseBody: func() string {
 				resp, _ := json.MarshalIndent(errorResponse{
 					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "gender", Code: codeInvalidValue, Message: "gender must be one of: male, female"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkAsOf",
+			path: "?surname=ivanov&as_of=2026-07-01T00:00:00Z",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				surname := "Ivanov"
+				asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Surname: &surname,
+					AsOf:    &asOf,
+					Limit:   defaultLimit + 1,
+				}).Return([]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{Persons: []entity.Person{
+					{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+				}, Total: 1, Page: 1, Limit: 5}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithSeveralViolations",
+			path:               "?age=-1&limit=500&nationalize=RUS&name=Ivan1&foo=bar",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "foo", Code: codeUnknownField, Message: "foo is not a supported parameter"},
+						{Field: "name", Code: codeInvalidCharacters, Message: "name may contain only letters, spaces, hyphens and apostrophes"},
+						{Field: "age", Code: codeOutOfRange, Message: "age must be between 0 and 150"},
+						{Field: "nationalize", Code: codeInvalidValue, Message: "nationalize must be an ISO 3166-1 alpha-2 country code"},
+						{Field: "limit", Code: codeOutOfRange, Message: "limit must be between 1 and 100"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithNextCursor",
+			path: "?surname=ivanov&limit=2&page=2",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				surname := "Ivanov"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Surname: &surname, Limit: 3, Offset: 2}).Return([]entity.Person{
+					{ID: 3, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+					{ID: 4, Name: "Petr", Surname: "Ivanov", Age: 20, Gender: "male"},
+					{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 3, Name: "Ivan", Surname: "Ivanov", Age: 18, Gender: "male"},
+						{ID: 4, Name: "Petr", Surname: "Ivanov", Age: 20, Gender: "male"},
+					},
+					NextCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 4}}, nil),
+					PrevCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 3}, Backward: true}, nil),
+					Total:      5,
+					Page:       2,
+					Limit:      2,
+					HasMore:    true,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithCursor",
+			path: "?limit=2&cursor=" + encodeCursor(&service.Cursor{Key: entity.Person{ID: 4}}, nil),
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Cursor: &service.Cursor{Key: entity.Person{ID: 4}},
+					Limit:  3,
+				}).Return([]entity.Person{{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons:    []entity.Person{{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"}},
+					PrevCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 7}, Backward: true}, nil),
+					Total:      5,
+					Limit:      2,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithPrevCursor",
+			path: "?limit=2&cursor=" + encodeCursor(&service.Cursor{Key: entity.Person{ID: 3}, Backward: true}, nil),
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Cursor: &service.Cursor{Key: entity.Person{ID: 3}, Backward: true},
+					Limit:  3,
+				}).Return([]entity.Person{
+					{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 30, Gender: "male"},
+					{ID: 2, Name: "Olga", Surname: "Petrova", Age: 25, Gender: "female"},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 1, Name: "Ivan", Surname: "Petrov", Age: 30, Gender: "male"},
+						{ID: 2, Name: "Olga", Surname: "Petrova", Age: 25, Gender: "female"},
+					},
+					NextCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 2}}, nil),
+					Total:      5,
+					Limit:      2,
+					HasMore:    true,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithCursorAfterLast",
+			path: "?cursor=" + encodeCursor(&service.Cursor{Key: entity.Person{ID: 9}}, nil),
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), gomock.Any()).Return(nil, entity.ErrPersonNotExists)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{Persons: []entity.Person{}, Total: 5, Limit: 5}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithPageAfterLast",
+			path: "?limit=2&page=4",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{Limit: 3, Offset: 6}).Return(nil, entity.ErrPersonNotExists)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{Persons: []entity.Person{}, Total: 5, Page: 4, Limit: 2}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "FailedCount",
+			path: "?limit=2",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), gomock.Any()).Return([]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("connection reset"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrInternalService.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidCursor",
+			path:               "?cursor=abc&page=1",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "cursor", Code: codeInvalidValue, Message: "cursor can't be combined with page"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithUnknownCursor",
+			path:               "?cursor=abc",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "cursor", Code: codeInvalidValue, Message: "cursor must be a cursor returned for the same sort order"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithRangesSetsAndExclusions",
+			path: "?age_min=20&age_max=30&gender=male,female&nationalize=ru,%20kz&surname!=ivanov,PETROV&nationalize!=UA&patronymic=",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				patronymic, ageMin, ageMax := "", 20, 30
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Patronymic:  &patronymic,
+					AgeMin:      &ageMin,
+					AgeMax:      &ageMax,
+					Gender:      []string{"male", "female"},
+					Nationalize: []string{"RU", "KZ"},
+					Exclude: service.Exclusions{
+						Surname:     []string{"Ivanov", "Petrov"},
+						Nationalize: []string{"UA"},
+					},
+					Limit: defaultLimit + 1,
+				}).Return([]entity.Person{{ID: 2, Name: "Anna", Surname: "Sidorova", Age: 25, Gender: "female"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{{ID: 2, Name: "Anna", Surname: "Sidorova", Age: 25, Gender: "female"}},
+					Total:   1,
+					Page:    1,
+					Limit:   defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidSets",
+			path:               "?age_min=40&age_max=30&gender=male,unknown&nationalize!=RU,RUS&name!=",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "age_max", Code: codeOutOfRange, Message: "age_max must not be less than age_min"},
+						{Field: "gender", Code: codeInvalidValue, Message: "gender must be one of: male, female"},
+						{Field: "name!", Code: codeRequired, Message: "name! must not be empty"},
+						{Field: "nationalize!", Code: codeInvalidValue, Message: "nationalize! must be an ISO 3166-1 alpha-2 country code"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithSearch",
+			path: "?q=%20IVA%20%20iv&limit=2",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				query := "iva iv"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Query: &query,
+					Sort:  []service.SortField{{Field: service.SortScore, Desc: true}},
+					Limit: 3,
+				}).Return([]entity.Person{
+					{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male", Score: 0.9},
+					{ID: 5, Name: "Iva", Surname: "Ivashina", Age: 25, Gender: "female", Score: 0.6},
+					{ID: 2, Name: "Ivo", Surname: "Petrov", Age: 41, Gender: "male", Score: 0.3},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				relevance := []service.SortField{{Field: service.SortScore, Desc: true}}
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male", Score: 0.9},
+						{ID: 5, Name: "Iva", Surname: "Ivashina", Age: 25, Gender: "female", Score: 0.6},
+					},
+					Total:      3,
+					Page:       1,
+					Limit:      2,
+					HasMore:    true,
+					NextCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 5, Score: 0.6}}, relevance),
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithTransliteratedSearch",
+			path: "?q=Dmitry",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				query := "dmitry"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Query: &query,
+					Sort:  []service.SortField{{Field: service.SortScore, Desc: true}},
+					Limit: defaultLimit + 1,
+				}).Return([]entity.Person{
+					{ID: 3, Name: "Дмитрий", Surname: "Орлов", Age: 35, Gender: "male", Score: 1,
+						Match: &entity.Match{Scheme: "informal", Text: "dmitry orlov"}},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 3, Name: "Дмитрий", Surname: "Орлов", Age: 35, Gender: "male", Score: 1,
+							Match: &entity.Match{Scheme: "informal", Text: "dmitry orlov"}},
+					},
+					Total: 1,
+					Page:  1,
+					Limit: defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithPhoneticSearch",
+			path: "?q=Jon%20Shmidt&match=phonetic",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				query := "jon shmidt"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Query: &query,
+					Match: service.MatchPhonetic,
+					Sort:  []service.SortField{{Field: service.SortScore, Desc: true}},
+					Limit: defaultLimit + 1,
+				}).Return([]entity.Person{
+					{ID: 4, Name: "John", Surname: "Schmidt", Age: 52, Gender: "male", Score: 0.5,
+						Match: &entity.Match{Scheme: "phonetic", Text: "john schmidt"}},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 4, Name: "John", Surname: "Schmidt", Age: 52, Gender: "male", Score: 0.5,
+							Match: &entity.Match{Scheme: "phonetic", Text: "john schmidt"}},
+					},
+					Total: 1,
+					Page:  1,
+					Limit: defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidMatch",
+			path:               "?match=phonetic&gender=male",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "match", Code: codeInvalidValue, Message: "match requires q"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithUnknownMatch",
+			path:               "?q=jon&match=exact",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "match", Code: codeInvalidValue, Message: "match must be one of: fuzzy, phonetic"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithSort",
+			path: "?sort=-age,surname,id&limit=2&page=2",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Sort:   []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}, {Field: service.SortID}},
+					Limit:  3,
+					Offset: 2,
+				}).Return([]entity.Person{
+					{ID: 4, Name: "Ivan", Surname: "Sidorov", Age: 40, Gender: "male"},
+					{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male"},
+					{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"},
+				}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				sort := []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}, {Field: service.SortID}}
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{
+						{ID: 4, Name: "Ivan", Surname: "Sidorov", Age: 40, Gender: "male"},
+						{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male"},
+					},
+					Total:   5,
+					Page:    2,
+					Limit:   2,
+					HasMore: true,
+					NextCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male"}},
+						sort),
+					PrevCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 4, Name: "Ivan", Surname: "Sidorov", Age: 40, Gender: "male"},
+						Backward: true}, sort),
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithSortAndCursor",
+			path: "?sort=-age,surname&limit=2&cursor=" + encodeCursor(&service.Cursor{Key: entity.Person{ID: 1, Surname: "Ivanov", Age: 30}},
+				[]service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}}),
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Sort:   []service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}},
+					Cursor: &service.Cursor{Key: entity.Person{ID: 1, Surname: "Ivanov", Age: 30}},
+					Limit:  3,
+				}).Return([]entity.Person{{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(5), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{{ID: 7, Name: "Anna", Surname: "Ivanova", Age: 21, Gender: "female"}},
+					PrevCursor: encodeCursor(&service.Cursor{Key: entity.Person{ID: 7, Surname: "Ivanova", Age: 21}, Backward: true},
+						[]service.SortField{{Field: service.SortAge, Desc: true}, {Field: service.SortSurname}}),
+					Total: 5,
+					Limit: 2,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithSearchSortedBySurname",
+			path: "?q=iva&sort=surname",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				query := "iva"
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Query: &query,
+					Sort:  []service.SortField{{Field: service.SortSurname}},
+					Limit: defaultLimit + 1,
+				}).Return([]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male", Score: 0.9}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male", Score: 0.9}},
+					Total:   1,
+					Page:    1,
+					Limit:   defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithUnknownSortField",
+			path:               "?sort=-height",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "sort", Code: codeInvalidValue, Message: "sort must be one of: id, name, surname, patronymic, age, gender, score"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithRepeatedSortField",
+			path:               "?sort=age,-age",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "sort", Code: codeInvalidValue, Message: "sort must not repeat age"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithScoreSortWithoutSearch",
+			path:               "?sort=-score",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "sort", Code: codeInvalidValue, Message: "sort by score requires q"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithNationalityThresholds",
+			path: "?nationalize=ru&primary_nationality=ru,kz&nationalize_min_probability=0.5&nationalities=top1",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				minProbability := 0.5
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Nationalize:               []string{"RU"},
+					PrimaryNationality:        []string{"RU", "KZ"},
+					NationalizeMinProbability: &minProbability,
+					Nationalities:             1,
+					Limit:                     defaultLimit + 1,
+				}).Return([]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male",
+					Nationalize: []entity.Nationality{{Country: "UA", Probability: 0.2}, {Country: "RU", Probability: 0.7}}}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male",
+						Nationalize: []entity.Nationality{{Country: "RU", Probability: 0.7}}}},
+					Total: 1,
+					Page:  1,
+					Limit: defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name: "OkWithTiedNationalities",
+			path: "?nationalities=top1",
+			mockBehavior: func(s *mock_service.MockRepository) {
+				s.EXPECT().GetPersons(gomock.Any(), &service.GetFilters{
+					Nationalities: 1,
+					Limit:         defaultLimit + 1,
+				}).Return([]entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male",
+					Nationalize: []entity.Nationality{{Country: "UA", Probability: 0.4}, {Country: "KZ", Probability: 0.4}}}}, nil)
+				s.EXPECT().CountPersons(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getPersonsResponse{
+					Persons: []entity.Person{{ID: 1, Name: "Ivan", Surname: "Ivanov", Age: 30, Gender: "male",
+						Nationalize: []entity.Nationality{{Country: "KZ", Probability: 0.4}}}},
+					Total: 1,
+					Page:  1,
+					Limit: defaultLimit,
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidNationalityThresholds",
+			path:               "?nationalize_min_probability=0.5&primary_nationality=RUS&nationalities=top2",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "primary_nationality", Code: codeInvalidValue, Message: "primary_nationality must be an ISO 3166-1 alpha-2 country code"},
+						{Field: "nationalities", Code: codeInvalidValue, Message: "nationalities must be one of: top1, top3, all"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithMinProbability",
+			path:               "?nationalize_min_probability=0.5",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "nationalize_min_probability", Code: codeInvalidValue, Message: "nationalize_min_probability requires nationalize or primary_nationality"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithMinProbabilityOutOfRange",
+			path:               "?nationalize=RU&nationalize_min_probability=1.5",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "nationalize_min_probability", Code: codeOutOfRange, Message: "nationalize_min_probability must be between 0 and 1"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithMinProbabilityNaN",
+			path:               "?nationalize=RU&nationalize_min_probability=NaN",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "nationalize_min_probability", Code: codeInvalidType, Message: "nationalize_min_probability must be a number"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidSearch",
+			path:               "?q=%20&cursor=" + encodeCursor(&service.Cursor{Key: entity.Person{ID: 5, Score: 0.6}}, nil),
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "q", Code: codeRequired, Message: "q must not be empty"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "FailedWithInvalidPage",
+			path:               "?page=first",
+			mockBehavior:       func(s *mock_service.MockRepository) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "page", Code: codeInvalidType, Message: "page must be an integer"},
+					},
 				}, "", "    ")
 				return string(resp)
 			}(),
@@ -357,9 +1103,9 @@ func Test_GetPersonsHandler(t *testing.T) {
 			repo := mock_service.NewMockRepository(c)
 			tt.mockBehavior(repo)
 
-			service := service.New(repo)
+			service := service.New(repo, normalize.New(normalizationConfig{}))
 
-			handler := NewHandler(service)
+			handler := NewHandler(service, feedConfig{})
 
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("GET", "/api/v1/persons"+tt.path, nil)
@@ -368,6 +1114,123 @@ func Test_GetPersonsHandler(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatusCode, w.Code)
 			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+
+			if w.Code == http.StatusOK {
+				var resp getPersonsResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, strconv.FormatInt(resp.Total, 10), w.Header().Get("X-Total-Count"))
+			}
+		})
+	}
+}
+
+func Test_GetHistoryHandler(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockRepository, id int)
+
+	createdAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	history := []entity.HistoryEntry{
+		{ID: 2, Action: "update", Actor: "admin", RequestID: "42", Changes: json.RawMessage(`{"surname":{"before":"Ivanov","after":"Petrov"}}`), CreatedAt: createdAt},
+		{ID: 1, Action: "create", Actor: "anonymous", RequestID: "41", Changes: json.RawMessage(`{"name":{"before":null,"after":"Ivan"}}`), CreatedAt: createdAt},
+	}
+
+	tests := []struct {
+		name                 string
+		inputId              int
+		query                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "Ok",
+			inputId: 1,
+			query:   "?limit=2&page=1",
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetHistory(gomock.Any(), id, int64(2), int64(0)).Return(history, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getHistoryResponse{History: history, Limit: 2, Page: 1}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:    "OkEmptyPage",
+			inputId: 1,
+			query:   "?page=3",
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetHistory(gomock.Any(), id, int64(defaultLimit), int64(2*defaultLimit)).Return([]entity.HistoryEntry{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getHistoryResponse{History: []entity.HistoryEntry{}, Limit: defaultLimit, Page: 3}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:    "OkWithoutRecordedChanges",
+			inputId: 1,
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetHistory(gomock.Any(), id, int64(defaultLimit), int64(0)).Return([]entity.HistoryEntry{}, nil)
+				s.EXPECT().GetPerson(gomock.Any(), id).Return(entity.Person{ID: id, Name: "Ivan"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(getHistoryResponse{History: []entity.HistoryEntry{}, Limit: defaultLimit, Page: 1}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:    "NotFound",
+			inputId: 7,
+			mockBehavior: func(s *mock_service.MockRepository, id int) {
+				s.EXPECT().GetHistory(gomock.Any(), id, int64(defaultLimit), int64(0)).Return([]entity.HistoryEntry{}, nil)
+				s.EXPECT().GetPerson(gomock.Any(), id).Return(entity.Person{}, entity.ErrPersonNotExists)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{Err: entity.ErrPersonNotExists.Error()}, "", "    ")
+				return string(resp)
+			}(),
+		},
+		{
+			name:               "InvalidLimit",
+			inputId:            1,
+			query:              "?limit=0&sort=id",
+			mockBehavior:       func(s *mock_service.MockRepository, id int) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponseBody: func() string {
+				resp, _ := json.MarshalIndent(errorResponse{
+					Err: entity.ErrInvalidInput.Error(),
+					Violations: []violation{
+						{Field: "sort", Code: codeUnknownField, Message: "sort is not a supported parameter"},
+						{Field: "limit", Code: codeOutOfRange, Message: "limit must be between 1 and 100"},
+					},
+				}, "", "    ")
+				return string(resp)
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mock_service.NewMockRepository(c)
+			tt.mockBehavior(repo, tt.inputId)
+
+			service := service.New(repo, normalize.New(normalizationConfig{}))
+
+			handler := NewHandler(service, feedConfig{})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/persons/%d/history%s", tt.inputId, tt.query), nil)
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
 		})
 	}
 }
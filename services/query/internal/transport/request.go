@@ -2,82 +2,321 @@ package transport
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/pintoter/persons/pkg/logger"
 	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
 )
 
+const (
+	defaultLimit = 5
+	defaultPage  = 1
+
+	defaultChangesLimit = 100
+)
+
+// personFilters are filters shared by the persons list and the change feed.
+// Gender, nationalize and primary_nationality take comma-separated sets,
+// parameters ending with ! exclude persons with the values.
+type personFilters struct {
+	Name               string
+	Surname            string
+	Patronymic         *string
+	Age                int
+	AgeMin             *int
+	AgeMax             *int
+	Gender             []string
+	Nationalize        []string
+	PrimaryNationality []string
+	// NationalizeMinProbability is the least probability of the countries
+	// nationalize and primary_nationality match
+	NationalizeMinProbability *float64
+	Exclude                   service.Exclusions
+}
+
+var personFilterParams = []string{
+	"name", "surname", "patronymic", "age", "age_min", "age_max", "gender", "nationalize",
+	"primary_nationality", "nationalize_min_probability",
+	"name!", "surname!", "patronymic!", "gender!", "nationalize!",
+}
+
+func (p *personFilters) set(v *validator, query url.Values) {
+	if query.Has("name") && v.name("name", query.Get("name")) {
+		p.Name = query.Get("name")
+	}
+
+	if query.Has("surname") && v.name("surname", query.Get("surname")) {
+		p.Surname = query.Get("surname")
+	}
+
+	// an empty patronymic selects persons without patronymic
+	if value := query.Get("patronymic"); query.Has("patronymic") && (value == "" || v.name("patronymic", value)) {
+		p.Patronymic = &value
+	}
+
+	if query.Has("age") {
+		p.Age, _ = v.intRange("age", query.Get("age"), minAge, maxAge)
+	}
+
+	if query.Has("age_min") {
+		if age, ok := v.intRange("age_min", query.Get("age_min"), minAge, maxAge); ok {
+			p.AgeMin = &age
+		}
+	}
+	if query.Has("age_max") {
+		if age, ok := v.intRange("age_max", query.Get("age_max"), minAge, maxAge); ok {
+			p.AgeMax = &age
+		}
+	}
+	if p.AgeMin != nil && p.AgeMax != nil && *p.AgeMin > *p.AgeMax {
+		v.add("age_max", codeOutOfRange, "age_max must not be less than age_min")
+	}
+
+	p.Gender = v.list(query, "gender", v.gender)
+	p.Nationalize = upper(v.list(query, "nationalize", v.countryCode))
+	p.PrimaryNationality = upper(v.list(query, "primary_nationality", v.countryCode))
+
+	if query.Has("nationalize_min_probability") {
+		if !query.Has("nationalize") && !query.Has("primary_nationality") {
+			v.add("nationalize_min_probability", codeInvalidValue, "nationalize_min_probability requires nationalize or primary_nationality")
+		} else if probability, ok := v.probability("nationalize_min_probability", query.Get("nationalize_min_probability")); ok {
+			p.NationalizeMinProbability = &probability
+		}
+	}
+
+	p.Exclude = service.Exclusions{
+		Name:        v.list(query, "name!", v.name),
+		Surname:     v.list(query, "surname!", v.name),
+		Patronymic:  v.list(query, "patronymic!", v.optionalName),
+		Gender:      v.list(query, "gender!", v.gender),
+		Nationalize: upper(v.list(query, "nationalize!", v.countryCode)),
+	}
+}
+
+// nationalitiesModes map values of nationalities to the number of the most
+// probable nationalities returned, 0 for all of them
+var nationalitiesModes = map[string]int{"top1": 1, "top3": 3, "all": 0}
+
+// upper brings country codes to upper case
+func upper(codes []string) []string {
+	for i, code := range codes {
+		codes[i] = strings.ToUpper(code)
+	}
+	return codes
+}
+
 type getPersonsRequest struct {
-	Name        string
-	Surname     string
-	Patronymic  string
-	Age         int
-	Gender      string
-	Nationalize string
-	Limit       int
-	Page        int
+	personFilters
+	// Query is the search query, persons are ordered by relevance to it
+	Query string
+	// Match is how Query matches names
+	Match string
+	// Nationalities is the number of the most probable nationalities
+	// returned, 0 for all of them
+	Nationalities int
+	AsOf          *time.Time
+	Sort          []service.SortField
+	Cursor        *service.Cursor
+	Limit         int
+	Page          int
 }
 
 func (p *getPersonsRequest) Set(r *http.Request) error {
-	logger.DebugKV(r.Context(), "get persons request", "query", r.URL.Query())
-	if r.URL.Query().Has("name") {
-		p.Name = r.URL.Query().Get("name")
+	query := r.URL.Query()
+	logger.DebugKV(r.Context(), "get persons request", "query", query)
+
+	if err := p.setQuery(query); err != nil {
+		logger.DebugKV(r.Context(), "get persons request", "err", err)
+		return err
 	}
 
-	if r.URL.Query().Has("surname") {
-		p.Surname = r.URL.Query().Get("surname")
+	return nil
+}
+
+// setQuery reads the request from query parameters, gRPC requests are
+// validated by passing their fields the same way
+func (p *getPersonsRequest) setQuery(query url.Values) error {
+	var v validator
+	v.knownParams(query, append(personFilterParams, "q", "match", "sort", "nationalities", "as_of", "cursor", "limit", "page")...)
+
+	p.personFilters.set(&v, query)
+
+	if query.Has("q") && v.name("q", strings.TrimSpace(query.Get("q"))) {
+		p.Query = query.Get("q")
+		p.Sort = []service.SortField{{Field: service.SortScore, Desc: true}}
 	}
 
-	if r.URL.Query().Has("patronymic") {
-		p.Patronymic = r.URL.Query().Get("patronymic")
+	// an explicit order replaces the relevance order of searches
+	if query.Has("sort") {
+		if sort, ok := v.sort("sort", query.Get("sort"), p.Query != ""); ok {
+			p.Sort = sort
+		}
 	}
 
-	if r.URL.Query().Has("age") {
-		age, err := strconv.Atoi(r.URL.Query().Get("age"))
-		logger.DebugKV(r.Context(), "get persons request", "has age", age)
-		if err != nil {
-			logger.DebugKV(r.Context(), "get persons request", "err", err)
-			return entity.ErrInvalidInput
+	if query.Has("match") {
+		if !query.Has("q") {
+			v.add("match", codeInvalidValue, "match requires q")
+		} else if v.oneOf("match", query.Get("match"), service.MatchFuzzy, service.MatchPhonetic) {
+			p.Match = query.Get("match")
 		}
-		p.Age = age
 	}
-	logger.DebugKV(r.Context(), "get persons request", "age", p.Age)
 
-	if r.URL.Query().Has("gender") {
-		gender := r.URL.Query().Get("gender")
-		if gender != entity.Male && gender != entity.Female {
-			logger.DebugKV(r.Context(), "get persons request", "err", "invalid gender")
-			return entity.ErrInvalidInput
+	if query.Has("nationalities") && v.oneOf("nationalities", query.Get("nationalities"), "top1", "top3", "all") {
+		p.Nationalities = nationalitiesModes[query.Get("nationalities")]
+	}
+
+	if query.Has("as_of") {
+		if asOf, ok := v.timestamp("as_of", query.Get("as_of")); ok {
+			p.AsOf = &asOf
 		}
-		p.Gender = gender
 	}
 
-	if r.URL.Query().Has("nationalize") {
-		p.Nationalize = r.URL.Query().Get("nationalize")
+	p.Limit = defaultLimit
+	if query.Has("limit") {
+		p.Limit, _ = v.intRange("limit", query.Get("limit"), minLimit, maxLimit)
+	}
+
+	p.Page = defaultPage
+	if query.Has("page") {
+		p.Page, _ = v.intRange("page", query.Get("page"), defaultPage, maxPage)
 	}
 
-	if r.URL.Query().Has("limit") {
-		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-		if err != nil || limit < 0 {
-			logger.DebugKV(r.Context(), "get persons request", "err", err)
-			return entity.ErrInvalidInput
+	if query.Has("cursor") {
+		if query.Has("page") {
+			v.add("cursor", codeInvalidValue, "cursor can't be combined with page")
+		} else {
+			p.Cursor, _ = v.cursor("cursor", query.Get("cursor"), p.Sort)
 		}
-		p.Limit = limit
-	} else {
-		p.Limit = 5
 	}
 
-	if r.URL.Query().Has("page") {
-		page, err := strconv.Atoi(r.URL.Query().Get("page"))
-		if err != nil || page <= 0 {
-			logger.DebugKV(r.Context(), "get persons request", "err", err)
-			return entity.ErrInvalidInput
+	return v.err()
+}
+
+// getEventsRequest subscribes to the change feed. Position to resume from is
+// taken from Last-Event-ID header, which EventSource sends on reconnect, or
+// from last_event_id parameter.
+type getEventsRequest struct {
+	personFilters
+	LastEventID *int64
+}
+
+func (p *getEventsRequest) Set(r *http.Request) error {
+	query := r.URL.Query()
+	logger.DebugKV(r.Context(), "get events request", "query", query)
+
+	var v validator
+	v.knownParams(query, append(personFilterParams, "last_event_id")...)
+
+	p.personFilters.set(&v, query)
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if query.Has("last_event_id") {
+		lastEventID = query.Get("last_event_id")
+	}
+	if lastEventID != "" {
+		if id, ok := v.eventID("last_event_id", lastEventID); ok {
+			p.LastEventID = &id
 		}
-		p.Page = page
-	} else {
-		p.Page = 1
 	}
 
-	return nil
+	return v.err()
+}
+
+// getChangesRequest asks for persons changed after the since token. Without
+// the token changes are read from the beginning, which is a full sync.
+type getChangesRequest struct {
+	Since int64
+	Limit int
+	Wait  time.Duration
+}
+
+func (p *getChangesRequest) Set(r *http.Request) error {
+	query := r.URL.Query()
+	logger.DebugKV(r.Context(), "get changes request", "query", query)
+
+	var v validator
+	v.knownParams(query, "since", "limit", "wait")
+
+	if query.Has("since") {
+		p.Since, _ = v.changeToken("since", query.Get("since"))
+	}
+
+	p.Limit = defaultChangesLimit
+	if query.Has("limit") {
+		p.Limit, _ = v.intRange("limit", query.Get("limit"), minLimit, maxChangesLimit)
+	}
+
+	if query.Has("wait") {
+		wait, _ := v.intRange("wait", query.Get("wait"), 0, maxWait)
+		p.Wait = time.Duration(wait) * time.Second
+	}
+
+	return v.err()
+}
+
+type getPersonRequest struct {
+	ID int
+	// Nationalities is the number of the most probable nationalities
+	// returned, 0 for all of them
+	Nationalities int
+	AsOf          *time.Time
+}
+
+func (p *getPersonRequest) Set(r *http.Request) error {
+	p.ID, _ = strconv.Atoi(mux.Vars(r)["id"])
+	if p.ID == 0 {
+		return entity.ErrInvalidInput
+	}
+
+	query := r.URL.Query()
+
+	var v validator
+	v.knownParams(query, "nationalities", "as_of")
+
+	if query.Has("nationalities") && v.oneOf("nationalities", query.Get("nationalities"), "top1", "top3", "all") {
+		p.Nationalities = nationalitiesModes[query.Get("nationalities")]
+	}
+
+	if query.Has("as_of") {
+		if asOf, ok := v.timestamp("as_of", query.Get("as_of")); ok {
+			p.AsOf = &asOf
+		}
+	}
+
+	return v.err()
+}
+
+type getHistoryRequest struct {
+	ID    int
+	Limit int
+	Page  int
+}
+
+func (p *getHistoryRequest) Set(r *http.Request) error {
+	p.ID, _ = strconv.Atoi(mux.Vars(r)["id"])
+	if p.ID == 0 {
+		return entity.ErrInvalidInput
+	}
+
+	query := r.URL.Query()
+
+	var v validator
+	v.knownParams(query, "limit", "page")
+
+	p.Limit = defaultLimit
+	if query.Has("limit") {
+		p.Limit, _ = v.intRange("limit", query.Get("limit"), minLimit, maxLimit)
+	}
+
+	p.Page = defaultPage
+	if query.Has("page") {
+		p.Page, _ = v.intRange("page", query.Get("page"), defaultPage, maxPage)
+	}
+
+	return v.err()
 }
@@ -14,10 +14,34 @@ type getPersonResponse struct {
 
 type getPersonsResponse struct {
 	Persons []entity.Person `json:"persons"`
+	// Total is the number of persons matching the filters on all pages
+	Total int64 `json:"total"`
+	// Page is omitted for pages selected by a cursor
+	Page    int  `json:"page,omitempty"`
+	Limit   int  `json:"limit"`
+	HasMore bool `json:"has_more"`
+	// NextCursor and PrevCursor select the pages next to this one, they are
+	// omitted when there are no persons there
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+type getHistoryResponse struct {
+	History []entity.HistoryEntry `json:"history"`
+	Limit   int                   `json:"limit"`
+	Page    int                   `json:"page"`
+}
+
+type getChangesResponse struct {
+	Changes   []entity.Change `json:"changes"`
+	NextToken string          `json:"next_token"`
+	HasMore   bool            `json:"has_more"`
 }
 
 type errorResponse struct {
-	Err string `json:"error"`
+	Err        string      `json:"error"`
+	Violations []violation `json:"violations,omitempty"`
+	MergedInto *int        `json:"merged_into,omitempty"`
 }
 
 func renderJSON(w http.ResponseWriter, r *http.Request, code int, data any) {
@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pintoter/persons/services/query/internal/entity"
+	"github.com/pintoter/persons/services/query/internal/service"
+)
+
+const (
+	maxNameLength = 64
+
+	minAge = 0
+	maxAge = 150
+
+	minLimit = 1
+	maxLimit = 100
+
+	maxPage = math.MaxInt32
+
+	maxChangesLimit = 1000
+	// maxWait is the longest long-poll in seconds
+	maxWait = 60
+)
+
+const (
+	codeRequired          = "required"
+	codeTooLong           = "too_long"
+	codeInvalidCharacters = "invalid_characters"
+	codeInvalidType       = "invalid_type"
+	codeInvalidValue      = "invalid_value"
+	codeOutOfRange        = "out_of_range"
+	codeUnknownField      = "unknown_field"
+	codeDuplicateField    = "duplicate_field"
+	codeMalformedBody     = "malformed_body"
+)
+
+type violation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type validationError struct {
+	violations []violation
+}
+
+func (e *validationError) Error() string {
+	return entity.ErrInvalidInput.Error()
+}
+
+func (e *validationError) Is(target error) bool {
+	return target == entity.ErrInvalidInput
+}
+
+type validator struct {
+	violations []violation
+}
+
+func (v *validator) add(field, code, message string) {
+	v.violations = append(v.violations, violation{Field: field, Code: code, Message: message})
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &validationError{violations: v.violations}
+}
+
+// knownParams reports unknown and repeated query parameters
+func (v *validator) knownParams(query url.Values, allowed ...string) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if !slices.Contains(allowed, key) {
+			v.add(key, codeUnknownField, fmt.Sprintf("%s is not a supported parameter", key))
+			continue
+		}
+		if len(query[key]) > 1 {
+			v.add(key, codeDuplicateField, fmt.Sprintf("%s must be passed once", key))
+		}
+	}
+}
+
+func (v *validator) name(field, value string) bool {
+	if value == "" {
+		v.add(field, codeRequired, fmt.Sprintf("%s must not be empty", field))
+		return false
+	}
+	if utf8.RuneCountInString(value) > maxNameLength {
+		v.add(field, codeTooLong, fmt.Sprintf("%s must be at most %d characters", field, maxNameLength))
+		return false
+	}
+	for _, r := range value {
+		if !isNameRune(r) {
+			v.add(field, codeInvalidCharacters, fmt.Sprintf("%s may contain only letters, spaces, hyphens and apostrophes", field))
+			return false
+		}
+	}
+	return true
+}
+
+// optionalName is a name or an empty value
+func (v *validator) optionalName(field, value string) bool {
+	return value == "" || v.name(field, value)
+}
+
+func (v *validator) gender(field, value string) bool {
+	return v.oneOf(field, value, entity.Male, entity.Female)
+}
+
+// list splits the comma-separated values of the parameter and checks each
+// of them, nil is returned when the parameter is missing or a value is
+// invalid
+func (v *validator) list(query url.Values, field string, check func(field, value string) bool) []string {
+	if !query.Has(field) {
+		return nil
+	}
+
+	values := strings.Split(query.Get(field), ",")
+	for i, value := range values {
+		values[i] = strings.TrimSpace(value)
+		if !check(field, values[i]) {
+			return nil
+		}
+	}
+	return values
+}
+
+func (v *validator) intRange(field, value string, min, max int) (int, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		v.add(field, codeInvalidType, fmt.Sprintf("%s must be an integer", field))
+		return 0, false
+	}
+	if n < min || n > max {
+		v.add(field, codeOutOfRange, fmt.Sprintf("%s must be between %d and %d", field, min, max))
+		return 0, false
+	}
+	return n, true
+}
+
+func (v *validator) probability(field, value string) (float64, bool) {
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(p) {
+		v.add(field, codeInvalidType, fmt.Sprintf("%s must be a number", field))
+		return 0, false
+	}
+	if p < 0 || p > 1 {
+		v.add(field, codeOutOfRange, fmt.Sprintf("%s must be between 0 and 1", field))
+		return 0, false
+	}
+	return p, true
+}
+
+func (v *validator) eventID(field, value string) (int64, bool) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		v.add(field, codeInvalidType, fmt.Sprintf("%s must be a non-negative integer", field))
+		return 0, false
+	}
+	return id, true
+}
+
+func (v *validator) changeToken(field, value string) (int64, bool) {
+	id, err := decodeChangeToken(value)
+	if err != nil {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be a token returned as next_token", field))
+		return 0, false
+	}
+	return id, true
+}
+
+func (v *validator) cursor(field, value string, sort []service.SortField) (*service.Cursor, bool) {
+	cursor, err := decodeCursor(value, sort)
+	if err != nil {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be a cursor returned for the same sort order", field))
+		return nil, false
+	}
+	return cursor, true
+}
+
+// sortFields are the fields persons can be sorted by
+var sortFields = []string{
+	service.SortID, service.SortName, service.SortSurname, service.SortPatronymic, service.SortAge, service.SortGender,
+	service.SortScore,
+}
+
+// sort reads comma-separated fields, descending ones prefixed with a minus.
+// Sorting by score needs a search query.
+func (v *validator) sort(field, value string, search bool) ([]service.SortField, bool) {
+	var sort []service.SortField
+	for _, part := range strings.Split(value, ",") {
+		name := strings.TrimSpace(part)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		if !v.oneOf(field, name, sortFields...) {
+			return nil, false
+		}
+		if slices.ContainsFunc(sort, func(f service.SortField) bool { return f.Field == name }) {
+			v.add(field, codeInvalidValue, fmt.Sprintf("%s must not repeat %s", field, name))
+			return nil, false
+		}
+		if name == service.SortScore && !search {
+			v.add(field, codeInvalidValue, fmt.Sprintf("%s by score requires q", field))
+			return nil, false
+		}
+		sort = append(sort, service.SortField{Field: name, Desc: desc})
+	}
+	return sort, true
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) bool {
+	if !slices.Contains(allowed, value) {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be one of: %s", field, strings.Join(allowed, ", ")))
+		return false
+	}
+	return true
+}
+
+func (v *validator) countryCode(field, value string) bool {
+	if len(value) != 2 || !isASCIILetter(value[0]) || !isASCIILetter(value[1]) {
+		v.add(field, codeInvalidValue, fmt.Sprintf("%s must be an ISO 3166-1 alpha-2 country code", field))
+		return false
+	}
+	return true
+}
+
+func (v *validator) timestamp(field, value string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.add(field, codeInvalidType, fmt.Sprintf("%s must be an RFC 3339 timestamp", field))
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Mn, r) || r == ' ' || r == '-' || r == '\'' || r == '’'
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	var vErr *validationError
+	if errors.As(err, &vErr) {
+		renderJSON(w, r, http.StatusBadRequest, errorResponse{Err: vErr.Error(), Violations: vErr.violations})
+		return
+	}
+	renderJSON(w, r, code, errorResponse{Err: err.Error()})
+}
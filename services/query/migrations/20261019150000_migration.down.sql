@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS projection_checkpoint;
+DROP TABLE IF EXISTS person_view;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS person_view (
+  person_id INT PRIMARY KEY,
+  name VARCHAR(80) NOT NULL,
+  surname VARCHAR(80) NOT NULL,
+  patronymic VARCHAR(80) NOT NULL DEFAULT '',
+  age INT NOT NULL,
+  gender VARCHAR(16) NOT NULL DEFAULT '',
+  nationalize JSONB NOT NULL DEFAULT '[]',
+  full_name TEXT GENERATED ALWAYS AS (lower(name || ' ' || surname || ' ' || patronymic)) STORED,
+  deleted BOOLEAN NOT NULL DEFAULT false,
+  merged_into INT,
+  last_event_id BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_view_name ON person_view (name) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_person_view_surname ON person_view (surname) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_person_view_full_name ON person_view (full_name) WHERE NOT deleted;
+CREATE INDEX IF NOT EXISTS idx_person_view_nationalize ON person_view USING GIN (nationalize jsonb_path_ops);
+
+CREATE TABLE IF NOT EXISTS projection_checkpoint (
+  name VARCHAR(64) PRIMARY KEY,
+  last_event_id BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO projection_checkpoint (name) VALUES ('person_view') ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_person_view_last_event_id;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_person_view_last_event_id ON person_view (last_event_id);
\ No newline at end of file
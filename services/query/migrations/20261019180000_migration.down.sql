@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_person_view_full_name_trgm;
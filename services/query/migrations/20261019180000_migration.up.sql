@@ -0,0 +1,4 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- q= search matches word prefixes and word similarity on the full name
+CREATE INDEX IF NOT EXISTS idx_person_view_full_name_trgm ON person_view USING GIN (full_name gin_trgm_ops) WHERE NOT deleted;
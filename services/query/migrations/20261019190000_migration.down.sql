@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_person_view_search_text_trgm;
+CREATE INDEX IF NOT EXISTS idx_person_view_full_name_trgm ON person_view USING GIN (full_name gin_trgm_ops) WHERE NOT deleted;
+
+ALTER TABLE person_view
+    DROP COLUMN IF EXISTS search_text,
+    DROP COLUMN IF EXISTS search_keys;
@@ -0,0 +1,13 @@
+-- Spellings of full names q= search matches: the original one and its
+-- transliterations, filled by the projector. Existing rows get the original
+-- spelling, `make rebuild-view` replays events to add transliterations
+ALTER TABLE person_view
+    ADD COLUMN IF NOT EXISTS search_keys JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+UPDATE person_view
+SET search_keys = jsonb_build_array(jsonb_build_object('scheme', 'original', 'text', trim(full_name))),
+    search_text = trim(full_name);
+
+DROP INDEX IF EXISTS idx_person_view_full_name_trgm;
+CREATE INDEX IF NOT EXISTS idx_person_view_search_text_trgm ON person_view USING GIN (search_text gin_trgm_ops) WHERE NOT deleted;
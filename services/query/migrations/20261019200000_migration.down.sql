@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_person_view_phonetic;
+
+ALTER TABLE person_view DROP COLUMN IF EXISTS phonetic;
@@ -0,0 +1,5 @@
+-- phonetic keys of name and surname from event payloads, match=phonetic
+-- searches persons sharing a key with every word of the query
+ALTER TABLE person_view ADD COLUMN IF NOT EXISTS phonetic JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_person_view_phonetic ON person_view USING GIN (phonetic jsonb_path_ops) WHERE NOT deleted;